@@ -0,0 +1,381 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"maps"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+	"github.com/karoljaro/go-uptime-monitor/infrastructure/chat"
+	"github.com/karoljaro/go-uptime-monitor/infrastructure/clock"
+	"github.com/karoljaro/go-uptime-monitor/infrastructure/dns"
+	"github.com/karoljaro/go-uptime-monitor/infrastructure/email"
+	httpclient "github.com/karoljaro/go-uptime-monitor/infrastructure/http"
+	"github.com/karoljaro/go-uptime-monitor/infrastructure/id"
+	"github.com/karoljaro/go-uptime-monitor/infrastructure/incident"
+	"github.com/karoljaro/go-uptime-monitor/infrastructure/storage"
+	"github.com/karoljaro/go-uptime-monitor/infrastructure/storage/postgres"
+	"github.com/karoljaro/go-uptime-monitor/infrastructure/storage/sqlite"
+	"github.com/karoljaro/go-uptime-monitor/infrastructure/tcp"
+	"github.com/karoljaro/go-uptime-monitor/infrastructure/webhook"
+	api "github.com/karoljaro/go-uptime-monitor/interface/http"
+	"github.com/karoljaro/go-uptime-monitor/usecase"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "HTTP listen address")
+	workers := flag.Int("workers", 4, "number of concurrent checks")
+	timeout := flag.Duration("timeout", 10*time.Second, "timeout of a single check")
+	dbPath := flag.String("db", "", "SQLite database file (in-memory storage when empty)")
+	postgresDSN := flag.String("postgres", "", "PostgreSQL connection string, takes precedence over -db")
+	nameserver := flag.String("nameserver", "", "DNS server for dns:// targets that don't name one (system resolver when empty)")
+	rawRetention := flag.Duration("retention", 7*24*time.Hour, "how long raw results are kept before they are rolled up")
+	hourlyRetention := flag.Duration("hourly-retention", 90*24*time.Hour, "how long hourly rollups are kept (0 keeps them forever)")
+	certExpiryDays := flag.Int("cert-expiry-days", 14, "alert when a TLS certificate expires within this many days")
+	alertReminder := flag.Duration("alert-reminder", 0, "notify again about alerts nobody acknowledged or snoozed this often (0 disables reminders)")
+	notifyConfig := notifierConfig{webhookHeaders: headerFlags{}}
+	flag.StringVar(&notifyConfig.webhookURL, "webhook-url", "", "POST alert notifications as JSON to this URL")
+	flag.StringVar(&notifyConfig.webhookTemplate, "webhook-template", "", "file with a Go template rendering the webhook payload")
+	flag.Var(notifyConfig.webhookHeaders, "webhook-header", "header sent with webhook notifications, as \"Name: value\" (repeatable)")
+	flag.StringVar(&notifyConfig.chatWebhookURL, "chat-webhook-url", "", "post alerts to this Slack or Mattermost incoming webhook")
+	flag.StringVar(&notifyConfig.slackToken, "slack-token", "", "Slack bot token; posts alerts to -slack-channel and updates them when resolved")
+	flag.StringVar(&notifyConfig.slackChannel, "slack-channel", "", "Slack channel for -slack-token")
+	flag.StringVar(&notifyConfig.apiURL, "api-url", "", "public URL of this API, linked from chat messages")
+	flag.StringVar(&notifyConfig.smtpAddr, "smtp-addr", "", "email alerts through this SMTP server (host:port, STARTTLS required)")
+	flag.StringVar(&notifyConfig.smtpUser, "smtp-user", "", "SMTP username")
+	flag.StringVar(&notifyConfig.smtpPassword, "smtp-password", os.Getenv("SMTP_PASSWORD"), "SMTP password (default $SMTP_PASSWORD)")
+	flag.StringVar(&notifyConfig.emailFrom, "email-from", "", "sender of alert emails")
+	flag.StringVar(&notifyConfig.emailTo, "email-to", "", "comma-separated recipients of alerts of targets without alert_emails")
+	flag.StringVar(&notifyConfig.incidentKey, "incident-routing-key", "", "trigger and resolve incidents through an events API with this routing key")
+	flag.StringVar(&notifyConfig.incidentURL, "incident-events-url", incident.DefaultEventsURL, "events API for -incident-routing-key")
+	flag.StringVar(&notifyConfig.emailTemplates, "email-templates", "", "directory with subject.tmpl, text.tmpl and html.tmpl replacing the default email templates")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	repos, closeStorage, err := openStorage(*postgresDSN, *dbPath)
+	if err != nil {
+		log.Fatalf("failed to open storage: %v", err)
+	}
+	defer closeStorage()
+
+	idGenerator := id.NewUUIDGenerator()
+	systemClock := clock.NewSystemClock()
+
+	var dnsOpts []dns.Option
+	if *nameserver != "" {
+		dnsOpts = append(dnsOpts, dns.WithNameserver(*nameserver))
+	}
+
+	notifiers, err := openNotifiers(notifyConfig)
+	if err != nil {
+		log.Fatalf("failed to set up notifications: %v", err)
+	}
+
+	outbox := usecase.NewOutboxUseCase(
+		repos.outbox,
+		notifiers,
+		systemClock,
+		usecase.WithDeliveryErrorHandler(func(message *domain.OutboxMessage, err error) {
+			if message == nil {
+				log.Printf("notification delivery failed: %v", err)
+				return
+			}
+			log.Printf("%s notification of alert %s failed (attempt %d): %v", message.Channel, message.Notification.Alert.ID, message.Attempts+1, err)
+		}),
+	)
+
+	monitor := usecase.NewMonitorUseCase(
+		repos.targets,
+		repos.results,
+		repos.alerts,
+		httpclient.NewDefaultHTTPClient(*timeout),
+		idGenerator,
+		usecase.WithChecker(domain.TargetTypeTCP, tcp.NewChecker(*timeout)),
+		usecase.WithChecker(domain.TargetTypeDNS, dns.NewChecker(*timeout, dnsOpts...)),
+		usecase.WithCertificateExpiryWarning(*certExpiryDays),
+		usecase.WithAlertReminder(*alertReminder),
+		usecase.WithMonitorClock(systemClock),
+		usecase.WithStateChangeRepository(repos.states),
+		usecase.WithOutbox(repos.outbox, slices.Sorted(maps.Keys(notifiers))...),
+		usecase.WithMaintenanceWindows(repos.maintenance),
+	)
+
+	scheduler := usecase.NewScheduler(
+		repos.targets,
+		monitor,
+		systemClock,
+		usecase.WithWorkers(*workers),
+		usecase.WithErrorHandler(func(targetID string, err error) {
+			log.Printf("check of target %s failed: %v", targetID, err)
+		}),
+	)
+
+	retention := usecase.NewRetentionUseCase(
+		repos.targets,
+		repos.results,
+		repos.rollups,
+		systemClock,
+		usecase.WithRawRetention(*rawRetention),
+		usecase.WithHourlyRetention(*hourlyRetention),
+		usecase.WithCompactionErrorHandler(func(err error) {
+			log.Printf("result compaction failed: %v", err)
+		}),
+	)
+
+	escalation := usecase.NewEscalationUseCase(
+		repos.targets,
+		repos.escalations,
+		repos.alerts,
+		repos.outbox,
+		idGenerator,
+		systemClock,
+		usecase.WithEscalationErrorHandler(func(err error) {
+			log.Printf("alert escalation failed: %v", err)
+		}),
+		usecase.WithEscalationMaintenanceWindows(repos.maintenance),
+	)
+
+	handler := api.NewHandler(
+		monitor,
+		usecase.NewStatsUseCase(
+			repos.targets,
+			repos.results,
+			repos.alerts,
+			usecase.WithRollupRepository(repos.rollups),
+			usecase.WithMaintenanceWindowRepository(repos.maintenance),
+		),
+		repos.targets,
+		repos.results,
+		repos.alerts,
+		idGenerator,
+		api.WithTargetReloader(scheduler),
+		api.WithStateChangeRepository(repos.states),
+		api.WithOutbox(outbox),
+		api.WithEscalationPolicies(repos.escalations, slices.Sorted(maps.Keys(notifiers))...),
+		api.WithMaintenanceWindows(repos.maintenance),
+	)
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           handler.Routes(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	schedulerDone := make(chan struct{})
+	go func() {
+		defer close(schedulerDone)
+		scheduler.Run(ctx)
+	}()
+
+	go retention.Run(ctx)
+	go outbox.Run(ctx)
+	go escalation.Run(ctx)
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("listening on %s", *addr)
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("server failed: %v", err)
+	}
+
+	<-schedulerDone
+}
+
+type repositories struct {
+	targets domain.TargetRepository
+	results domain.ResultRepository
+	alerts  domain.AlertRepository
+	rollups domain.RollupRepository
+	states  domain.StateChangeRepository
+	outbox  domain.OutboxRepository
+
+	escalations domain.EscalationPolicyRepository
+	maintenance domain.MaintenanceWindowRepository
+}
+
+func openStorage(postgresDSN, sqlitePath string) (*repositories, func(), error) {
+	switch {
+	case postgresDSN != "":
+		db, err := postgres.Open(postgresDSN)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &repositories{
+			targets: postgres.NewTargetRepository(db),
+			results: postgres.NewResultRepository(db),
+			alerts:  postgres.NewAlertRepository(db),
+			rollups: postgres.NewRollupRepository(db),
+			states:  postgres.NewStateChangeRepository(db),
+			outbox:  postgres.NewOutboxRepository(db),
+
+			escalations: postgres.NewEscalationPolicyRepository(db),
+			maintenance: postgres.NewMaintenanceWindowRepository(db),
+		}, func() { db.Close() }, nil
+
+	case sqlitePath != "":
+		db, err := sqlite.Open(sqlitePath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &repositories{
+			targets: sqlite.NewTargetRepository(db),
+			results: sqlite.NewResultRepository(db),
+			alerts:  sqlite.NewAlertRepository(db),
+			rollups: sqlite.NewRollupRepository(db),
+			states:  sqlite.NewStateChangeRepository(db),
+			outbox:  sqlite.NewOutboxRepository(db),
+
+			escalations: sqlite.NewEscalationPolicyRepository(db),
+			maintenance: sqlite.NewMaintenanceWindowRepository(db),
+		}, func() { db.Close() }, nil
+	}
+
+	alerts := storage.NewMemoryAlertRepository()
+
+	return &repositories{
+		targets: storage.NewMemoryTargetRepository(),
+		results: storage.NewMemoryResultRepository(),
+		alerts:  alerts,
+		rollups: storage.NewMemoryRollupRepository(),
+		states:  storage.NewMemoryStateChangeRepository(),
+		outbox:  storage.NewMemoryOutboxRepository(alerts),
+
+		escalations: storage.NewMemoryEscalationPolicyRepository(),
+		maintenance: storage.NewMemoryMaintenanceWindowRepository(),
+	}, func() {}, nil
+}
+
+// headerFlags collects repeated "Name: value" flags.
+type headerFlags map[string]string
+
+func (h headerFlags) String() string {
+	return fmt.Sprint(map[string]string(h))
+}
+
+func (h headerFlags) Set(v string) error {
+	name, value, ok := strings.Cut(v, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("header %q is not \"Name: value\"", v)
+	}
+
+	h[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	return nil
+}
+
+// notifierConfig holds the notification flags; a notifier is set up for every
+// channel whose flags are given.
+type notifierConfig struct {
+	webhookURL      string
+	webhookTemplate string
+	webhookHeaders  headerFlags
+	chatWebhookURL  string
+	slackToken      string
+	slackChannel    string
+	apiURL          string
+	smtpAddr        string
+	smtpUser        string
+	smtpPassword    string
+	emailFrom       string
+	emailTo         string
+	emailTemplates  string
+	incidentKey     string
+	incidentURL     string
+}
+
+// openNotifiers returns the configured notifiers by outbox channel name.
+func openNotifiers(cfg notifierConfig) (map[string]domain.Notifier, error) {
+	notifiers := make(map[string]domain.Notifier)
+
+	if cfg.webhookURL != "" {
+		var payloadTemplate string
+		if cfg.webhookTemplate != "" {
+			data, err := os.ReadFile(cfg.webhookTemplate)
+			if err != nil {
+				return nil, err
+			}
+			payloadTemplate = string(data)
+		}
+
+		notifier, err := webhook.NewNotifier(cfg.webhookURL, payloadTemplate, webhook.WithHeaders(cfg.webhookHeaders))
+		if err != nil {
+			return nil, err
+		}
+
+		notifiers["webhook"] = notifier
+	}
+
+	switch {
+	case cfg.chatWebhookURL != "":
+		notifiers["chat"] = chat.NewWebhookNotifier(cfg.chatWebhookURL, chat.WithAPIURL(cfg.apiURL))
+	case cfg.slackToken != "":
+		if cfg.slackChannel == "" {
+			return nil, errors.New("-slack-token needs -slack-channel")
+		}
+		notifiers["chat"] = chat.NewSlackNotifier(cfg.slackToken, cfg.slackChannel, chat.WithAPIURL(cfg.apiURL))
+	}
+
+	if cfg.smtpAddr != "" {
+		notifier, err := openEmailNotifier(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		notifiers["email"] = notifier
+	}
+
+	if cfg.incidentKey != "" {
+		notifiers["incident"] = incident.NewNotifier(cfg.incidentKey, incident.WithEventsURL(cfg.incidentURL))
+	}
+
+	return notifiers, nil
+}
+
+func openEmailNotifier(cfg notifierConfig) (*email.Notifier, error) {
+	var templates [3]string
+	if cfg.emailTemplates != "" {
+		for i, name := range []string{"subject.tmpl", "text.tmpl", "html.tmpl"} {
+			data, err := os.ReadFile(filepath.Join(cfg.emailTemplates, name))
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			templates[i] = string(data)
+		}
+	}
+
+	opts := []email.Option{email.WithTemplates(templates[0], templates[1], templates[2])}
+	if cfg.emailTo != "" {
+		opts = append(opts, email.WithRecipients(strings.FieldsFunc(cfg.emailTo, func(r rune) bool { return r == ',' || r == ' ' })...))
+	}
+	if cfg.smtpUser != "" {
+		opts = append(opts, email.WithAuth(cfg.smtpUser, cfg.smtpPassword))
+	}
+
+	return email.NewNotifier(cfg.smtpAddr, cfg.emailFrom, opts...)
+}
@@ -1,6 +1,21 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// AlertTypeCertificateExpiry is raised while a target's TLS certificate is
+// about to expire (or has expired) and AlertTypeSlowResponse while the target
+// is DEGRADED by its latency threshold; other alert types are result statuses.
+const (
+	AlertTypeCertificateExpiry = "CERT_EXPIRY"
+	AlertTypeSlowResponse      = "SLOW_RESPONSE"
+)
+
+// ErrAlertResolved is returned when acknowledging, snoozing or assigning an
+// alert that was already resolved.
+var ErrAlertResolved = errors.New("alert is resolved")
 
 type Alert struct {
 	ID         string
@@ -10,16 +25,44 @@ type Alert struct {
 	CreatedAt  time.Time
 	ResolvedAt *time.Time
 	IsResolved bool
+
+	// AcknowledgedBy took the alert on at AcknowledgedAt. Repeat
+	// notifications stop once it is acknowledged and while it is snoozed.
+	AcknowledgedBy string
+	AcknowledgedAt *time.Time
+	SnoozedUntil   *time.Time
+	Assignee       string
+
+	// NotifiedAt is when the alert was last notified about.
+	NotifiedAt time.Time
+
+	// EscalationStep is how many steps of its target's escalation policy the
+	// alert went through.
+	EscalationStep int
 }
 
 func NewAlert(id, targetID, alertType, message string) *Alert {
+	now := time.Now()
+
 	return &Alert{
 		ID:         id,
 		TargetID:   targetID,
 		Type:       alertType,
 		Message:    message,
-		CreatedAt:  time.Now(),
+		CreatedAt:  now,
 		IsResolved: false,
+		NotifiedAt: now,
+	}
+}
+
+// IsDowntime reports whether the alert was raised for failing checks rather
+// than, say, an expiring certificate.
+func (a *Alert) IsDowntime() bool {
+	switch a.Type {
+	case StatusClientError, StatusServerError, StatusError, StatusAssertionFailed:
+		return true
+	default:
+		return false
 	}
 }
 
@@ -27,4 +70,50 @@ func (a *Alert) Resolve() {
 	now := time.Now()
 	a.ResolvedAt = &now
 	a.IsResolved = true
-}
\ No newline at end of file
+}
+
+// Acknowledge records that by took the alert on at the given time.
+// Acknowledging it again hands it over.
+func (a *Alert) Acknowledge(by string, at time.Time) error {
+	if a.IsResolved {
+		return ErrAlertResolved
+	}
+
+	a.AcknowledgedBy = by
+	a.AcknowledgedAt = &at
+	return nil
+}
+
+// Snooze silences the alert until the given time; a time in the past ends
+// an earlier snooze.
+func (a *Alert) Snooze(until time.Time) error {
+	if a.IsResolved {
+		return ErrAlertResolved
+	}
+
+	a.SnoozedUntil = &until
+	return nil
+}
+
+func (a *Alert) Assign(assignee string) error {
+	if a.IsResolved {
+		return ErrAlertResolved
+	}
+
+	a.Assignee = assignee
+	return nil
+}
+
+func (a *Alert) IsAcknowledged() bool {
+	return a.AcknowledgedAt != nil
+}
+
+func (a *Alert) IsSnoozed(now time.Time) bool {
+	return a.SnoozedUntil != nil && now.Before(*a.SnoozedUntil)
+}
+
+// IsSilenced reports whether repeat notifications about the alert are
+// suppressed at now.
+func (a *Alert) IsSilenced(now time.Time) bool {
+	return a.IsAcknowledged() || a.IsSnoozed(now)
+}
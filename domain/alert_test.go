@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -71,3 +72,67 @@ func TestResolve(t *testing.T) {
 		t.Errorf("expected IsResolved true, got %t", alert.IsResolved)
 	}
 }
+
+func TestAcknowledge(t *testing.T) {
+	alert := NewAlert("alert-1", "target-1", "Down", "Service is down")
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	if alert.IsAcknowledged() || alert.IsSilenced(at) {
+		t.Fatal("expected a new alert not to be acknowledged")
+	}
+
+	if err := alert.Acknowledge("alice", at); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if alert.AcknowledgedBy != "alice" || alert.AcknowledgedAt == nil || !alert.AcknowledgedAt.Equal(at) {
+		t.Errorf("expected the alert to be acknowledged by alice at %v, got %s at %v", at, alert.AcknowledgedBy, alert.AcknowledgedAt)
+	}
+
+	if !alert.IsSilenced(at.Add(24 * time.Hour)) {
+		t.Error("expected an acknowledged alert to be silenced")
+	}
+
+	alert.Resolve()
+	if err := alert.Acknowledge("bob", at); !errors.Is(err, ErrAlertResolved) {
+		t.Errorf("expected ErrAlertResolved, got %v", err)
+	}
+}
+
+func TestSnooze(t *testing.T) {
+	alert := NewAlert("alert-1", "target-1", "Down", "Service is down")
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := alert.Snooze(now.Add(time.Hour)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !alert.IsSnoozed(now) || !alert.IsSilenced(now.Add(59*time.Minute)) {
+		t.Error("expected the alert to be silenced within the hour")
+	}
+
+	if alert.IsSilenced(now.Add(time.Hour)) {
+		t.Error("expected the snooze to end after an hour")
+	}
+
+	if alert.IsAcknowledged() {
+		t.Error("expected snoozing not to acknowledge the alert")
+	}
+}
+
+func TestAssign(t *testing.T) {
+	alert := NewAlert("alert-1", "target-1", "Down", "Service is down")
+
+	if err := alert.Assign("bob"); err != nil || alert.Assignee != "bob" {
+		t.Errorf("expected the alert to be assigned to bob, got %q (%v)", alert.Assignee, err)
+	}
+
+	if alert.IsSilenced(time.Now()) {
+		t.Error("expected assigning not to silence the alert")
+	}
+
+	alert.Resolve()
+	if err := alert.Assign("alice"); !errors.Is(err, ErrAlertResolved) {
+		t.Errorf("expected ErrAlertResolved, got %v", err)
+	}
+}
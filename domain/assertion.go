@@ -0,0 +1,231 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	AssertionContains    = "contains"
+	AssertionNotContains = "not_contains"
+	AssertionRegex       = "regex"
+	AssertionJSONPath    = "json_path"
+	AssertionMaxSize     = "max_size"
+)
+
+// Assertion is a check on an HTTP response body. Value is the keyword,
+// pattern or expected value depending on Type; Path is the JSONPath of a
+// json_path assertion and Size the byte limit of a max_size one.
+type Assertion struct {
+	Type  string
+	Path  string
+	Value string
+	Size  int64
+}
+
+func (a Assertion) String() string {
+	switch a.Type {
+	case AssertionContains:
+		return fmt.Sprintf("body contains %q", a.Value)
+	case AssertionNotContains:
+		return fmt.Sprintf("body lacks %q", a.Value)
+	case AssertionRegex:
+		return fmt.Sprintf("body matches /%s/", a.Value)
+	case AssertionJSONPath:
+		return fmt.Sprintf("%s equals %q", a.Path, a.Value)
+	case AssertionMaxSize:
+		return fmt.Sprintf("body is at most %d bytes", a.Size)
+	default:
+		return a.Type
+	}
+}
+
+func (a Assertion) Validate() error {
+	switch a.Type {
+	case AssertionContains, AssertionNotContains:
+		if a.Value == "" {
+			return fmt.Errorf("%s assertion needs a value", a.Type)
+		}
+	case AssertionRegex:
+		if _, err := regexp.Compile(a.Value); err != nil {
+			return fmt.Errorf("regex assertion: %w", err)
+		}
+	case AssertionJSONPath:
+		if _, err := parseJSONPath(a.Path); err != nil {
+			return err
+		}
+	case AssertionMaxSize:
+		if a.Size <= 0 {
+			return fmt.Errorf("max_size assertion needs a positive size")
+		}
+	default:
+		return fmt.Errorf("unknown assertion type %q", a.Type)
+	}
+
+	return nil
+}
+
+// Evaluate returns an *AssertionError when body fails the assertion.
+func (a Assertion) Evaluate(body []byte) error {
+	fail := func(format string, args ...any) error {
+		return &AssertionError{Assertion: a, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	switch a.Type {
+	case AssertionContains:
+		if !bytes.Contains(body, []byte(a.Value)) {
+			return fail("keyword not found")
+		}
+	case AssertionNotContains:
+		if bytes.Contains(body, []byte(a.Value)) {
+			return fail("keyword found")
+		}
+	case AssertionRegex:
+		re, err := regexp.Compile(a.Value)
+		if err != nil {
+			return fail("%v", err)
+		}
+		if !re.Match(body) {
+			return fail("no match")
+		}
+	case AssertionJSONPath:
+		got, err := evalJSONPath(body, a.Path)
+		if err != nil {
+			return fail("%v", err)
+		}
+		if got != a.Value {
+			return fail("got %s", got)
+		}
+	case AssertionMaxSize:
+		if int64(len(body)) > a.Size {
+			return fail("body has %d bytes", len(body))
+		}
+	default:
+		return fail("unknown assertion type")
+	}
+
+	return nil
+}
+
+// AssertionError names the assertion a response body failed and why.
+type AssertionError struct {
+	Assertion Assertion
+	Reason    string
+}
+
+func (e *AssertionError) Error() string {
+	return fmt.Sprintf("assertion %s failed: %s", e.Assertion, e.Reason)
+}
+
+// jsonPathStep is an object key or, when key is empty, an array index.
+type jsonPathStep struct {
+	key   string
+	index int
+}
+
+// parseJSONPath supports the dot and bracket subset of JSONPath: $.a.b,
+// $.items[0].name and $['odd key'].
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	rest, ok := strings.CutPrefix(path, "$")
+	if !ok {
+		return nil, fmt.Errorf("JSONPath %q must start with $", path)
+	}
+
+	var steps []jsonPathStep
+
+	for rest != "" {
+		switch {
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("JSONPath %q has an empty key", path)
+			}
+
+			steps = append(steps, jsonPathStep{key: key})
+			rest = rest[end+1:]
+
+		case strings.HasPrefix(rest, "['"):
+			end := strings.Index(rest, "']")
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath %q has an unclosed bracket", path)
+			}
+
+			steps = append(steps, jsonPathStep{key: rest[2:end]})
+			rest = rest[end+2:]
+
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath %q has an unclosed bracket", path)
+			}
+
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("JSONPath %q has an invalid index %q", path, rest[1:end])
+			}
+
+			steps = append(steps, jsonPathStep{index: index})
+			rest = rest[end+1:]
+
+		default:
+			return nil, fmt.Errorf("JSONPath %q is invalid at %q", path, rest)
+		}
+	}
+
+	return steps, nil
+}
+
+// evalJSONPath returns the value at path: strings as they are, anything else
+// as JSON, so that an expected value of "false" or "3" matches.
+func evalJSONPath(body []byte, path string) (string, error) {
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return "", err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return "", fmt.Errorf("body is not JSON: %w", err)
+	}
+
+	for _, step := range steps {
+		switch node := value.(type) {
+		case map[string]any:
+			v, ok := node[step.key]
+			if step.key == "" || !ok {
+				return "", fmt.Errorf("%s not found", path)
+			}
+			value = v
+		case []any:
+			if step.key != "" || step.index >= len(node) {
+				return "", fmt.Errorf("%s not found", path)
+			}
+			value = node[step.index]
+		default:
+			return "", fmt.Errorf("%s not found", path)
+		}
+	}
+
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAssertion_Evaluate(t *testing.T) {
+	body := []byte(`{"healthy": true, "version": 3, "checks": [{"name": "db", "status": "up"}], "odd key": null}`)
+
+	tests := []struct {
+		assertion Assertion
+		wantPass  bool
+	}{
+		{Assertion{Type: AssertionContains, Value: `"healthy": true`}, true},
+		{Assertion{Type: AssertionContains, Value: "maintenance"}, false},
+		{Assertion{Type: AssertionNotContains, Value: "maintenance"}, true},
+		{Assertion{Type: AssertionNotContains, Value: "healthy"}, false},
+		{Assertion{Type: AssertionRegex, Value: `"version": \d+`}, true},
+		{Assertion{Type: AssertionRegex, Value: `^<html`}, false},
+		{Assertion{Type: AssertionJSONPath, Path: "$.healthy", Value: "true"}, true},
+		{Assertion{Type: AssertionJSONPath, Path: "$.version", Value: "3"}, true},
+		{Assertion{Type: AssertionJSONPath, Path: "$.checks[0].status", Value: "up"}, true},
+		{Assertion{Type: AssertionJSONPath, Path: "$['odd key']", Value: "null"}, true},
+		{Assertion{Type: AssertionJSONPath, Path: "$.healthy", Value: "false"}, false},
+		{Assertion{Type: AssertionJSONPath, Path: "$.checks[1].status", Value: "up"}, false},
+		{Assertion{Type: AssertionMaxSize, Size: 1024}, true},
+		{Assertion{Type: AssertionMaxSize, Size: 10}, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.assertion.Evaluate(body)
+
+		if tt.wantPass && err != nil {
+			t.Errorf("%s: expected to pass, got %v", tt.assertion, err)
+		}
+
+		var failed *AssertionError
+		if !tt.wantPass && !errors.As(err, &failed) {
+			t.Errorf("%s: expected an AssertionError, got %v", tt.assertion, err)
+		}
+	}
+}
+
+func TestAssertion_EvaluateNonJSONBody(t *testing.T) {
+	err := Assertion{Type: AssertionJSONPath, Path: "$.healthy", Value: "true"}.Evaluate([]byte("<html>"))
+
+	if err == nil || !strings.Contains(err.Error(), "$.healthy") {
+		t.Errorf("expected the failure to name the path, got %v", err)
+	}
+}
+
+func TestAssertion_Validate(t *testing.T) {
+	invalid := []Assertion{
+		{Type: "bogus"},
+		{Type: AssertionContains},
+		{Type: AssertionRegex, Value: "("},
+		{Type: AssertionJSONPath, Path: "healthy"},
+		{Type: AssertionJSONPath, Path: "$.items[x]"},
+		{Type: AssertionMaxSize},
+	}
+
+	for _, a := range invalid {
+		if err := a.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", a)
+		}
+	}
+}
+
+func TestHTTPCheck_AssertBody(t *testing.T) {
+	check := DefaultHTTPCheck()
+	check.Assertions = []Assertion{
+		{Type: AssertionContains, Value: "ok"},
+		{Type: AssertionNotContains, Value: "error"},
+	}
+
+	if failed := check.AssertBody([]byte("ok")); failed != nil {
+		t.Errorf("expected no failure, got %v", failed)
+	}
+
+	failed := check.AssertBody([]byte("ok, error"))
+	if failed == nil || failed.Assertion.Type != AssertionNotContains {
+		t.Errorf("expected the not_contains assertion to fail, got %v", failed)
+	}
+}
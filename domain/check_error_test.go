@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"deadline", &url.Error{Op: "Get", URL: "https://example.com", Err: context.DeadlineExceeded}, ErrorKindTimeout},
+		{"dial timeout", &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}, ErrorKindTimeout},
+		{"no such host", &net.OpError{Op: "dial", Err: &net.DNSError{Name: "nope.invalid", IsNotFound: true}}, ErrorKindDNS},
+		{"refused", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, ErrorKindRefused},
+		{"reset", &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, ErrorKindReset},
+		{"closed", fmt.Errorf("Get: %w", io.EOF), ErrorKindReset},
+		{"untrusted", fmt.Errorf("verification failed: %w", x509.UnknownAuthorityError{}), ErrorKindTLS},
+		{"other", errors.New("unexpected banner"), ErrorKindOther},
+	}
+
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
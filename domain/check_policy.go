@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// DefaultRetryBackoff is the wait before the first re-check when a policy
+// retries without setting a backoff.
+const DefaultRetryBackoff = time.Second
+
+// MaxRetries bounds CheckPolicy.Retries.
+const MaxRetries = 10
+
+// CheckPolicy controls how failures of a target are confirmed. The zero value
+// records the first answer and opens and resolves alerts on a single check.
+type CheckPolicy struct {
+	// Retries is how many times a failing check is repeated before the
+	// failure is recorded. RetryBackoff is the wait before the first retry and
+	// doubles for each one after it.
+	Retries      int
+	RetryBackoff time.Duration
+
+	// FailureThreshold is the number of consecutive failed checks that opens
+	// an alert and RecoveryThreshold the number of consecutive successful
+	// ones that resolves it.
+	FailureThreshold  int
+	RecoveryThreshold int
+}
+
+func (p CheckPolicy) Validate() error {
+	if p.Retries < 0 || p.RetryBackoff < 0 || p.FailureThreshold < 0 || p.RecoveryThreshold < 0 {
+		return errors.New("check policy values must not be negative")
+	}
+
+	if p.Retries > MaxRetries {
+		return fmt.Errorf("check policy allows at most %d retries", MaxRetries)
+	}
+
+	return nil
+}
+
+// ValidateInterval checks that the retries of a failing check are over
+// before the next check of a target checked every interval is due.
+func (p CheckPolicy) ValidateInterval(interval time.Duration) error {
+	if wait := p.RetryWait(); wait >= interval {
+		return fmt.Errorf("check policy retries wait %s, not less than the %s interval", wait, interval)
+	}
+
+	return nil
+}
+
+// Backoff returns the wait before retry number attempt, counting from 0. It
+// stops doubling at the longest duration.
+func (p CheckPolicy) Backoff(attempt int) time.Duration {
+	backoff := p.RetryBackoff
+	if backoff == 0 {
+		backoff = DefaultRetryBackoff
+	}
+
+	if attempt >= 63 || backoff > math.MaxInt64>>attempt {
+		return math.MaxInt64
+	}
+
+	return backoff << attempt
+}
+
+// RetryWait is how long the retries of a failing check wait in all.
+func (p CheckPolicy) RetryWait() time.Duration {
+	var wait time.Duration
+	for attempt := 0; attempt < p.Retries; attempt++ {
+		backoff := p.Backoff(attempt)
+		if backoff > math.MaxInt64-wait {
+			return math.MaxInt64
+		}
+		wait += backoff
+	}
+
+	return wait
+}
+
+func (p CheckPolicy) FailuresToAlert() int {
+	return max(p.FailureThreshold, 1)
+}
+
+func (p CheckPolicy) SuccessesToRecover() int {
+	return max(p.RecoveryThreshold, 1)
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCheckPolicy_Defaults(t *testing.T) {
+	var policy CheckPolicy
+
+	if policy.FailuresToAlert() != 1 || policy.SuccessesToRecover() != 1 {
+		t.Errorf("expected the zero policy to act on a single check, got %d/%d", policy.FailuresToAlert(), policy.SuccessesToRecover())
+	}
+
+	if policy.Backoff(0) != DefaultRetryBackoff {
+		t.Errorf("expected the default backoff, got %s", policy.Backoff(0))
+	}
+}
+
+func TestCheckPolicy_Backoff(t *testing.T) {
+	policy := CheckPolicy{RetryBackoff: 200 * time.Millisecond}
+
+	if policy.Backoff(0) != 200*time.Millisecond || policy.Backoff(2) != 800*time.Millisecond {
+		t.Errorf("expected the backoff to double, got %s and %s", policy.Backoff(0), policy.Backoff(2))
+	}
+
+	if (CheckPolicy{Retries: -1}).Validate() == nil {
+		t.Error("expected negative retries to be invalid")
+	}
+
+	if (CheckPolicy{Retries: MaxRetries + 1}).Validate() == nil {
+		t.Errorf("expected more than %d retries to be invalid", MaxRetries)
+	}
+
+	if huge := (CheckPolicy{RetryBackoff: time.Hour}).Backoff(40); huge <= 0 {
+		t.Errorf("expected the backoff to stop doubling instead of overflowing, got %s", huge)
+	}
+}
+
+func TestCheckPolicy_ValidateInterval(t *testing.T) {
+	policy := CheckPolicy{Retries: 3, RetryBackoff: time.Second}
+
+	if policy.RetryWait() != 7*time.Second {
+		t.Errorf("expected 7s of retries, got %s", policy.RetryWait())
+	}
+
+	if err := policy.ValidateInterval(10 * time.Second); err != nil {
+		t.Errorf("expected retries within the interval to be valid, got %v", err)
+	}
+
+	if policy.ValidateInterval(5*time.Second) == nil {
+		t.Error("expected retries longer than the interval to be invalid")
+	}
+
+	if (CheckPolicy{Retries: MaxRetries, RetryBackoff: math.MaxInt64 / 4}).ValidateInterval(time.Hour) == nil {
+		t.Error("expected overflowing retries to be invalid")
+	}
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// CheckResponse is what a single probe observed. StatusCode is only set by
+// protocols that have one (HTTP) and TLS only for connections over TLS; Error
+// reports a probe that reached the target but whose answer was wrong.
+// FailedAssertion is the first body assertion an HTTP response failed and
+// Timings the phase breakdown of an HTTP check.
+type CheckResponse struct {
+	StatusCode      int
+	ResponseTime    time.Duration
+	Error           error
+	TLS             *TLSInfo
+	FailedAssertion *AssertionError
+	Timings         *Timings
+}
+
+// Checker probes one kind of target. A returned error means the target could
+// not be probed at all, e.g. the connection failed.
+type Checker interface {
+	Check(ctx context.Context, target *Target) (*CheckResponse, error)
+}
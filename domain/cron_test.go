@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronSchedule_Next(t *testing.T) {
+	from := time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC) // a Wednesday
+
+	cases := map[string]time.Time{
+		"* * * * *":      time.Date(2025, 1, 1, 12, 1, 0, 0, time.UTC),
+		"*/15 * * * *":   time.Date(2025, 1, 1, 12, 15, 0, 0, time.UTC),
+		"30 22 * * 2":    time.Date(2025, 1, 7, 22, 30, 0, 0, time.UTC),
+		"0 3 * * 7":      time.Date(2025, 1, 5, 3, 0, 0, 0, time.UTC),
+		"0 0 15 2 *":     time.Date(2025, 2, 15, 0, 0, 0, 0, time.UTC),
+		"0 9 1-5/2 * *":  time.Date(2025, 1, 3, 9, 0, 0, 0, time.UTC),
+		"0 0 31 * 5":     time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC),
+		"0 6,18 * * 1-5": time.Date(2025, 1, 1, 18, 0, 0, 0, time.UTC),
+		"@monthly":       time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	for expr, want := range cases {
+		schedule, err := ParseCron(expr)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", expr, err)
+		}
+
+		if got := schedule.Next(from); !got.Equal(want) {
+			t.Errorf("%s: expected %s, got %s", expr, want, got)
+		}
+	}
+}
+
+func TestCronSchedule_NextIncludesStart(t *testing.T) {
+	schedule, _ := ParseCron("0 12 * * *")
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if got := schedule.Next(at); !got.Equal(at) {
+		t.Errorf("expected %s, got %s", at, got)
+	}
+}
+
+func TestCronSchedule_NeverFires(t *testing.T) {
+	schedule, _ := ParseCron("0 0 30 2 *")
+
+	if got := schedule.Next(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)); !got.IsZero() {
+		t.Errorf("expected no run on February 30th, got %s", got)
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@sometimes"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("%q: expected an error", expr)
+		}
+	}
+}
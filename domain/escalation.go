@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// EscalationStep notifies Channels (names of notifiers, e.g. "email") Delay
+// after the step before it, or after the alert opened for the first step.
+type EscalationStep struct {
+	Delay    time.Duration
+	Channels []string
+}
+
+// EscalationPolicy routes the alerts of the targets it is attached to
+// through its steps in order, for as long as nobody acknowledges them.
+type EscalationPolicy struct {
+	ID        string
+	Name      string
+	Steps     []EscalationStep
+	CreatedAt time.Time
+}
+
+func NewEscalationPolicy(id, name string, steps []EscalationStep) *EscalationPolicy {
+	return &EscalationPolicy{
+		ID:        id,
+		Name:      name,
+		Steps:     steps,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (p *EscalationPolicy) Validate() error {
+	if p.Name == "" {
+		return errors.New("escalation policy name is required")
+	}
+
+	if len(p.Steps) == 0 {
+		return errors.New("escalation policy needs at least one step")
+	}
+
+	for _, step := range p.Steps {
+		if step.Delay < 0 {
+			return errors.New("escalation step delay must not be negative")
+		}
+
+		if len(step.Channels) == 0 {
+			return errors.New("escalation step needs at least one channel")
+		}
+	}
+
+	return nil
+}
+
+// DueAt returns when step, counting from 0, is due for an alert opened at
+// openedAt.
+func (p *EscalationPolicy) DueAt(step int, openedAt time.Time) time.Time {
+	at := openedAt
+	for _, s := range p.Steps[:step+1] {
+		at = at.Add(s.Delay)
+	}
+
+	return at
+}
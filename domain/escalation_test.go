@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEscalationPolicy_DueAt(t *testing.T) {
+	policy := NewEscalationPolicy("policy-1", "On call", []EscalationStep{
+		{Delay: 0, Channels: []string{"chat"}},
+		{Delay: 10 * time.Minute, Channels: []string{"email"}},
+		{Delay: 20 * time.Minute, Channels: []string{"incident"}},
+	})
+	openedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for step, want := range []time.Time{openedAt, openedAt.Add(10 * time.Minute), openedAt.Add(30 * time.Minute)} {
+		if got := policy.DueAt(step, openedAt); !got.Equal(want) {
+			t.Errorf("expected step %d to be due at %v, got %v", step, want, got)
+		}
+	}
+}
+
+func TestEscalationPolicy_Validate(t *testing.T) {
+	valid := []EscalationStep{{Delay: time.Minute, Channels: []string{"email"}}}
+
+	if err := NewEscalationPolicy("policy-1", "On call", valid).Validate(); err != nil {
+		t.Errorf("expected a valid policy, got %v", err)
+	}
+
+	for name, policy := range map[string]*EscalationPolicy{
+		"no name":          NewEscalationPolicy("policy-1", "", valid),
+		"no steps":         NewEscalationPolicy("policy-1", "On call", nil),
+		"negative delay":   NewEscalationPolicy("policy-1", "On call", []EscalationStep{{Delay: -time.Minute, Channels: []string{"email"}}}),
+		"no step channels": NewEscalationPolicy("policy-1", "On call", []EscalationStep{{Delay: time.Minute}}),
+	} {
+		if policy.Validate() == nil {
+			t.Errorf("%s: expected the policy to be invalid", name)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StatusRange is an inclusive range of HTTP status codes.
+type StatusRange struct {
+	Min int
+	Max int
+}
+
+// ParseStatusRange parses a single code ("204") or a range ("200-299").
+func ParseStatusRange(s string) (StatusRange, error) {
+	lo, hi, isRange := strings.Cut(strings.TrimSpace(s), "-")
+	if !isRange {
+		hi = lo
+	}
+
+	min, errMin := strconv.Atoi(strings.TrimSpace(lo))
+	max, errMax := strconv.Atoi(strings.TrimSpace(hi))
+	if errMin != nil || errMax != nil {
+		return StatusRange{}, fmt.Errorf("invalid status range %q", s)
+	}
+
+	r := StatusRange{Min: min, Max: max}
+	if err := r.validate(); err != nil {
+		return StatusRange{}, err
+	}
+
+	return r, nil
+}
+
+func (r StatusRange) Contains(code int) bool {
+	return code >= r.Min && code <= r.Max
+}
+
+func (r StatusRange) String() string {
+	if r.Min == r.Max {
+		return strconv.Itoa(r.Min)
+	}
+
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
+func (r StatusRange) validate() error {
+	if r.Min < 100 || r.Max > 599 || r.Min > r.Max {
+		return fmt.Errorf("invalid status range %s", r)
+	}
+
+	return nil
+}
+
+// DefaultAcceptedStatus is what an HTTP check accepts when its spec doesn't
+// list any ranges.
+var DefaultAcceptedStatus = []StatusRange{{Min: 200, Max: 299}}
+
+// HTTPCheck describes the request sent to an HTTP target and which responses
+// count as up. A target without one gets DefaultHTTPCheck.
+type HTTPCheck struct {
+	Method          string
+	Headers         map[string]string
+	Body            string
+	AcceptedStatus  []StatusRange
+	FollowRedirects bool
+	Assertions      []Assertion
+}
+
+func DefaultHTTPCheck() *HTTPCheck {
+	return &HTTPCheck{
+		Method:          "GET",
+		FollowRedirects: true,
+	}
+}
+
+// Accepts reports whether code is within the accepted ranges.
+func (c *HTTPCheck) Accepts(code int) bool {
+	accepted := c.AcceptedStatus
+	if len(accepted) == 0 {
+		accepted = DefaultAcceptedStatus
+	}
+
+	for _, r := range accepted {
+		if r.Contains(code) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *HTTPCheck) Validate() error {
+	if c.Method == "" || strings.ContainsAny(c.Method, " \t\r\n") {
+		return fmt.Errorf("invalid HTTP method %q", c.Method)
+	}
+
+	for name := range c.Headers {
+		if name == "" || strings.ContainsAny(name, " \t\r\n:") {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+	}
+
+	var errs []error
+	for _, r := range c.AcceptedStatus {
+		errs = append(errs, r.validate())
+	}
+
+	for _, a := range c.Assertions {
+		errs = append(errs, a.Validate())
+	}
+
+	return errors.Join(errs...)
+}
+
+// AssertBody evaluates the assertions in order and returns the first failure.
+func (c *HTTPCheck) AssertBody(body []byte) *AssertionError {
+	for _, a := range c.Assertions {
+		var failed *AssertionError
+		if errors.As(a.Evaluate(body), &failed) {
+			return failed
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package domain
+
+import "testing"
+
+func TestParseStatusRange(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    StatusRange
+		wantErr bool
+	}{
+		{in: "204", want: StatusRange{Min: 204, Max: 204}},
+		{in: "200-299", want: StatusRange{Min: 200, Max: 299}},
+		{in: " 401 - 403 ", want: StatusRange{Min: 401, Max: 403}},
+		{in: "299-200", wantErr: true},
+		{in: "99", wantErr: true},
+		{in: "2xx", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseStatusRange(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseStatusRange(%q): expected error %v, got %v", tt.in, tt.wantErr, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("ParseStatusRange(%q): expected %+v, got %+v", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestHTTPCheck_Accepts(t *testing.T) {
+	check := DefaultHTTPCheck()
+
+	if !check.Accepts(204) || check.Accepts(301) || check.Accepts(401) {
+		t.Error("expected the default check to accept exactly 2xx")
+	}
+
+	check.AcceptedStatus = []StatusRange{{Min: 200, Max: 204}, {Min: 401, Max: 401}}
+
+	if !check.Accepts(401) || check.Accepts(205) {
+		t.Error("expected the configured ranges to replace the default")
+	}
+}
+
+func TestHTTPCheck_Validate(t *testing.T) {
+	if err := DefaultHTTPCheck().Validate(); err != nil {
+		t.Errorf("expected the default check to be valid, got %v", err)
+	}
+
+	invalid := []*HTTPCheck{
+		{Method: ""},
+		{Method: "GET POST"},
+		{Method: "GET", Headers: map[string]string{"Bad Name": "x"}},
+		{Method: "GET", AcceptedStatus: []StatusRange{{Min: 300, Max: 200}}},
+	}
+
+	for _, check := range invalid {
+		if err := check.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", check)
+		}
+	}
+}
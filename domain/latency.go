@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// LatencyThreshold marks a target that answers, but slowly, as DEGRADED: when
+// a check takes longer than Max, or when the p95 response time of the last
+// P95Window checks is above P95. A zero Max or P95 disables that test.
+type LatencyThreshold struct {
+	Max       time.Duration
+	P95       time.Duration
+	P95Window int
+}
+
+func (l LatencyThreshold) Validate() error {
+	if l.Max < 0 || l.P95 < 0 || l.P95Window < 0 {
+		return errors.New("latency threshold values must not be negative")
+	}
+
+	if (l.P95 > 0) != (l.P95Window > 0) {
+		return errors.New("a p95 latency threshold needs both a limit and a window")
+	}
+
+	return nil
+}
+
+// Violation describes how the latest response time, or the p95 of recent
+// ones (newest first, the latest included), breaks the threshold, or returns
+// "" when it doesn't. The p95 is only judged once a full window was checked.
+func (l LatencyThreshold) Violation(recent []time.Duration) string {
+	if len(recent) == 0 {
+		return ""
+	}
+
+	if l.Max > 0 && recent[0] > l.Max {
+		return fmt.Sprintf("response time %s is above %s", recent[0], l.Max)
+	}
+
+	if l.P95 == 0 || len(recent) < l.P95Window {
+		return ""
+	}
+
+	var dist ResponseTimeDistribution
+	for _, responseTime := range recent[:l.P95Window] {
+		dist.Add(responseTime)
+	}
+
+	if p95 := dist.Percentile(95); p95 > l.P95 {
+		return fmt.Sprintf("p95 response time of the last %d checks %s is above %s", l.P95Window, p95, l.P95)
+	}
+
+	return ""
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyThreshold_Violation(t *testing.T) {
+	ms := time.Millisecond
+	threshold := LatencyThreshold{Max: 500 * ms, P95: 200 * ms, P95Window: 4}
+
+	tests := []struct {
+		name   string
+		recent []time.Duration
+		want   bool
+	}{
+		{"no checks", nil, false},
+		{"fast", []time.Duration{100 * ms, 100 * ms, 100 * ms, 100 * ms}, false},
+		{"above max", []time.Duration{600 * ms}, true},
+		{"p95 above limit", []time.Duration{100 * ms, 300 * ms, 100 * ms, 100 * ms}, true},
+		{"window not full", []time.Duration{100 * ms, 300 * ms, 100 * ms}, false},
+		{"slow check left the window", []time.Duration{100 * ms, 100 * ms, 100 * ms, 100 * ms, 300 * ms}, false},
+	}
+
+	for _, tt := range tests {
+		if got := threshold.Violation(tt.recent); (got != "") != tt.want {
+			t.Errorf("%s: expected violation %t, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestLatencyThreshold_Validate(t *testing.T) {
+	if err := (LatencyThreshold{Max: time.Second}).Validate(); err != nil {
+		t.Errorf("expected an absolute threshold to be valid, got %v", err)
+	}
+
+	if (LatencyThreshold{P95: time.Second}).Validate() == nil {
+		t.Error("expected a p95 limit without a window to be invalid")
+	}
+
+	if (LatencyThreshold{Max: -time.Second}).Validate() == nil {
+		t.Error("expected a negative threshold to be invalid")
+	}
+}
@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// MaintenanceWindow is planned downtime of the targets it covers: while it is
+// active their checks are still recorded, but open no alerts. A one-off
+// window is active for Duration from Start; a recurring one for Duration
+// each time its Schedule fires at or after Start.
+type MaintenanceWindow struct {
+	ID        string
+	Name      string
+	Start     time.Time
+	Duration  time.Duration
+	CreatedAt time.Time
+
+	// Schedule is a cron expression (see ParseCron) evaluated in Location,
+	// an IANA time zone name; empty means UTC. An empty Schedule makes the
+	// window one-off.
+	Schedule string
+	Location string
+
+	// The window covers the targets listed in TargetIDs and those with any
+	// of Tags.
+	TargetIDs []string
+	Tags      []string
+}
+
+func NewMaintenanceWindow(id, name string, start time.Time, duration time.Duration) *MaintenanceWindow {
+	return &MaintenanceWindow{
+		ID:        id,
+		Name:      name,
+		Start:     start,
+		Duration:  duration,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (w *MaintenanceWindow) Validate() error {
+	if w.Name == "" {
+		return errors.New("maintenance window name is required")
+	}
+
+	if w.Duration <= 0 {
+		return errors.New("maintenance window duration must be positive")
+	}
+
+	if w.Start.IsZero() {
+		return errors.New("maintenance window needs a start")
+	}
+
+	if len(w.TargetIDs) == 0 && len(w.Tags) == 0 {
+		return errors.New("maintenance window needs target IDs or tags")
+	}
+
+	if w.Schedule == "" {
+		return nil
+	}
+
+	if _, err := ParseCron(w.Schedule); err != nil {
+		return err
+	}
+
+	if _, err := time.LoadLocation(w.Location); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Covers reports whether the window applies to target.
+func (w *MaintenanceWindow) Covers(target *Target) bool {
+	if slices.Contains(w.TargetIDs, target.ID) {
+		return true
+	}
+
+	for _, tag := range w.Tags {
+		if slices.Contains(target.Tags, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsActive reports whether the window is in progress at now. Windows that
+// don't validate never are.
+func (w *MaintenanceWindow) IsActive(now time.Time) bool {
+	if w.Schedule == "" {
+		return !now.Before(w.Start) && now.Before(w.Start.Add(w.Duration))
+	}
+
+	schedule, err := ParseCron(w.Schedule)
+	if err != nil {
+		return false
+	}
+
+	loc, err := time.LoadLocation(w.Location)
+	if err != nil {
+		return false
+	}
+
+	// Only a run that started after now-Duration can still be going on.
+	from := now.Add(-w.Duration).Add(time.Nanosecond)
+	if w.Start.After(from) {
+		from = w.Start
+	}
+
+	run := schedule.Next(from.In(loc))
+
+	return !run.IsZero() && !run.After(now)
+}
+
+// Runs returns the starts of the runs of the window that overlap [from, to),
+// each lasting Duration. Windows that don't validate have none.
+func (w *MaintenanceWindow) Runs(from, to time.Time) []time.Time {
+	if w.Schedule == "" {
+		if w.Start.Before(to) && w.Start.Add(w.Duration).After(from) {
+			return []time.Time{w.Start}
+		}
+		return nil
+	}
+
+	schedule, err := ParseCron(w.Schedule)
+	if err != nil {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(w.Location)
+	if err != nil {
+		return nil
+	}
+
+	// As in IsActive, the first run that can overlap started after
+	// from-Duration.
+	next := from.Add(-w.Duration).Add(time.Nanosecond)
+	if w.Start.After(next) {
+		next = w.Start
+	}
+
+	var runs []time.Time
+	for run := schedule.Next(next.In(loc)); !run.IsZero() && run.Before(to); run = schedule.Next(run.Add(time.Minute)) {
+		runs = append(runs, run)
+	}
+
+	return runs
+}
+
+// InMaintenance reports whether any of windows covers target and is active at
+// now.
+func InMaintenance(windows []*MaintenanceWindow, target *Target, now time.Time) bool {
+	for _, w := range windows {
+		if w.Covers(target) && w.IsActive(now) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindow_OneOff(t *testing.T) {
+	start := time.Date(2025, 1, 1, 22, 0, 0, 0, time.UTC)
+	window := NewMaintenanceWindow("window-1", "Deploy", start, time.Hour)
+
+	cases := map[time.Time]bool{
+		start.Add(-time.Second):     false,
+		start:                       true,
+		start.Add(59 * time.Minute): true,
+		start.Add(time.Hour):        false,
+	}
+
+	for now, want := range cases {
+		if got := window.IsActive(now); got != want {
+			t.Errorf("at %s: expected active %v, got %v", now, want, got)
+		}
+	}
+}
+
+func TestMaintenanceWindow_Recurring(t *testing.T) {
+	window := NewMaintenanceWindow("window-1", "Weekly deploy", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), 2*time.Hour)
+	window.Schedule = "0 22 * * 2"
+	window.Location = "Europe/Warsaw"
+
+	// 22:00 in Warsaw is 21:00 UTC in winter; 2025-01-07 is a Tuesday.
+	cases := map[time.Time]bool{
+		time.Date(2025, 1, 7, 20, 59, 0, 0, time.UTC): false,
+		time.Date(2025, 1, 7, 21, 0, 0, 0, time.UTC):  true,
+		time.Date(2025, 1, 7, 22, 59, 0, 0, time.UTC): true,
+		time.Date(2025, 1, 7, 23, 0, 0, 0, time.UTC):  false,
+		time.Date(2025, 1, 8, 21, 30, 0, 0, time.UTC): false,
+		time.Date(2025, 1, 14, 22, 0, 0, 0, time.UTC): true,
+	}
+
+	for now, want := range cases {
+		if got := window.IsActive(now); got != want {
+			t.Errorf("at %s: expected active %v, got %v", now, want, got)
+		}
+	}
+
+	window.Start = time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	if window.IsActive(time.Date(2025, 1, 7, 21, 30, 0, 0, time.UTC)) {
+		t.Error("expected no run before the window starts")
+	}
+}
+
+func TestMaintenanceWindow_Runs(t *testing.T) {
+	start := time.Date(2025, 1, 1, 22, 0, 0, 0, time.UTC)
+	oneOff := NewMaintenanceWindow("window-1", "Deploy", start, time.Hour)
+
+	if runs := oneOff.Runs(start.Add(30*time.Minute), start.Add(2*time.Hour)); len(runs) != 1 || !runs[0].Equal(start) {
+		t.Errorf("expected the run at %s, got %v", start, runs)
+	}
+
+	if runs := oneOff.Runs(start.Add(time.Hour), start.Add(2*time.Hour)); len(runs) != 0 {
+		t.Errorf("expected no run after the window ended, got %v", runs)
+	}
+
+	daily := NewMaintenanceWindow("window-2", "Nightly backup", start, 3*time.Hour)
+	daily.Schedule = "0 22 * * *"
+
+	// The run of January 2nd is still going on at midnight.
+	runs := daily.Runs(time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC))
+	want := []time.Time{
+		time.Date(2025, 1, 2, 22, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 3, 22, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 4, 22, 0, 0, 0, time.UTC),
+	}
+
+	if len(runs) != len(want) {
+		t.Fatalf("expected runs %v, got %v", want, runs)
+	}
+
+	for i := range want {
+		if !runs[i].Equal(want[i]) {
+			t.Errorf("expected run %d at %s, got %s", i, want[i], runs[i])
+		}
+	}
+}
+
+func TestMaintenanceWindow_Covers(t *testing.T) {
+	window := NewMaintenanceWindow("window-1", "Deploy", time.Now(), time.Hour)
+	window.TargetIDs = []string{"target-1"}
+	window.Tags = []string{"api"}
+
+	listed := NewTarget("target-1", "https://example.com", "listed", time.Minute)
+	tagged := NewTarget("target-2", "https://api.example.com", "tagged", time.Minute)
+	tagged.Tags = []string{"prod", "api"}
+	other := NewTarget("target-3", "https://www.example.com", "other", time.Minute)
+	other.Tags = []string{"web"}
+
+	if !window.Covers(listed) || !window.Covers(tagged) || window.Covers(other) {
+		t.Errorf("expected the window to cover the listed and the tagged target only")
+	}
+}
+
+func TestMaintenanceWindow_Validate(t *testing.T) {
+	valid := func() *MaintenanceWindow {
+		window := NewMaintenanceWindow("window-1", "Deploy", time.Now(), time.Hour)
+		window.Tags = []string{"api"}
+		return window
+	}
+
+	if err := valid().Validate(); err != nil {
+		t.Errorf("expected a valid window, got %v", err)
+	}
+
+	invalid := map[string]func(w *MaintenanceWindow){
+		"without name":     func(w *MaintenanceWindow) { w.Name = "" },
+		"without duration": func(w *MaintenanceWindow) { w.Duration = 0 },
+		"without scope":    func(w *MaintenanceWindow) { w.Tags = nil },
+		"without start":    func(w *MaintenanceWindow) { w.Start = time.Time{} },
+		"bad schedule":     func(w *MaintenanceWindow) { w.Schedule = "every tuesday" },
+		"bad location":     func(w *MaintenanceWindow) { w.Schedule, w.Location = "@daily", "Mars/Olympus" },
+	}
+
+	for name, change := range invalid {
+		window := valid()
+		change(window)
+
+		if err := window.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// NotificationRepeated reminds about an alert that is still open and that
+// nobody acknowledged or snoozed. NotificationEscalated tells the channels of
+// an escalation step about an alert nobody acknowledged in time.
+const (
+	NotificationOpened    = "opened"
+	NotificationResolved  = "resolved"
+	NotificationRepeated  = "repeated"
+	NotificationEscalated = "escalated"
+)
+
+// Notification tells about an alert that was opened, resolved, is still open
+// or was escalated. Alert and Target are copies taken when it happened.
+type Notification struct {
+	Event  string
+	Alert  Alert
+	Target Target
+
+	// ResponseTime is that of the check that opened or resolved the alert.
+	ResponseTime time.Duration
+}
+
+// Notifier delivers notifications to people or other systems.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
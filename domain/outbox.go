@@ -0,0 +1,66 @@
+package domain
+
+import "time"
+
+// OutboxMessage is a notification waiting to be delivered to one channel (a
+// named notifier). A message that keeps failing is dead-lettered: DeadAt is
+// set and it is no longer due until it is replayed.
+type OutboxMessage struct {
+	ID            string
+	Channel       string
+	Notification  Notification
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	CreatedAt     time.Time
+	DeadAt        *time.Time
+}
+
+func NewOutboxMessage(id, channel string, n Notification, now time.Time) *OutboxMessage {
+	return &OutboxMessage{
+		ID:            id,
+		Channel:       channel,
+		Notification:  n,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+}
+
+func (m *OutboxMessage) IsDead() bool {
+	return m.DeadAt != nil
+}
+
+// Precedes reports whether m was queued before other for the same channel
+// and alert. Messages about an alert are delivered to a channel in the order
+// they were queued.
+func (m *OutboxMessage) Precedes(other *OutboxMessage) bool {
+	if m.Channel != other.Channel || m.Notification.Alert.ID != other.Notification.Alert.ID {
+		return false
+	}
+
+	if !m.CreatedAt.Equal(other.CreatedAt) {
+		return m.CreatedAt.Before(other.CreatedAt)
+	}
+	return m.ID < other.ID
+}
+
+// Fail records a failed delivery attempt: the message is retried at
+// retryAt, or dead-lettered when retryAt is zero.
+func (m *OutboxMessage) Fail(err error, now, retryAt time.Time) {
+	m.Attempts++
+	m.LastError = err.Error()
+
+	if retryAt.IsZero() {
+		m.DeadAt = &now
+		return
+	}
+
+	m.NextAttemptAt = retryAt
+}
+
+// Replay makes a dead letter due again with a fresh set of attempts.
+func (m *OutboxMessage) Replay(now time.Time) {
+	m.Attempts = 0
+	m.DeadAt = nil
+	m.NextAttemptAt = now
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOutboxMessage_FailAndReplay(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	message := NewOutboxMessage("message-1", "webhook", Notification{Event: NotificationOpened}, now)
+
+	message.Fail(errors.New("timeout"), now, now.Add(time.Minute))
+	if message.Attempts != 1 || message.LastError != "timeout" || !message.NextAttemptAt.Equal(now.Add(time.Minute)) || message.IsDead() {
+		t.Errorf("expected a retry in a minute, got %+v", message)
+	}
+
+	message.Fail(errors.New("410 Gone"), now.Add(time.Minute), time.Time{})
+	if !message.IsDead() || !message.DeadAt.Equal(now.Add(time.Minute)) || message.Attempts != 2 {
+		t.Errorf("expected the message to be dead-lettered, got %+v", message)
+	}
+
+	message.Replay(now.Add(time.Hour))
+	if message.IsDead() || message.Attempts != 0 || !message.NextAttemptAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("expected the message to be due again, got %+v", message)
+	}
+}
+
+func TestOutboxMessage_Precedes(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	about := func(alertID string) Notification {
+		return Notification{Alert: Alert{ID: alertID}}
+	}
+
+	opened := NewOutboxMessage("message-1", "webhook", about("alert-1"), now)
+	resolved := NewOutboxMessage("message-2", "webhook", about("alert-1"), now.Add(time.Minute))
+
+	for _, tt := range []struct {
+		name  string
+		other *OutboxMessage
+		want  bool
+	}{
+		{"later message", resolved, true},
+		{"same time, later ID", NewOutboxMessage("message-3", "webhook", about("alert-1"), now), true},
+		{"same time, earlier ID", NewOutboxMessage("message-0", "webhook", about("alert-1"), now), false},
+		{"earlier message", NewOutboxMessage("message-3", "webhook", about("alert-1"), now.Add(-time.Minute)), false},
+		{"other channel", NewOutboxMessage("message-3", "email", about("alert-1"), now.Add(time.Minute)), false},
+		{"other alert", NewOutboxMessage("message-3", "webhook", about("alert-2"), now.Add(time.Minute)), false},
+	} {
+		if got := opened.Precedes(tt.other); got != tt.want {
+			t.Errorf("%s: expected %t, got %t", tt.name, tt.want, got)
+		}
+	}
+}
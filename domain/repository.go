@@ -1,22 +1,103 @@
 package domain
 
+import "time"
+
 type TargetRepository interface {
 	Save(target *Target) error
 	FindByID(id string) (*Target, error)
 	GetAll() ([]*Target, error)
 	Delete(id string) error
 	Update(target *Target) error
+	// ChangeState saves the state of change unless the target left
+	// change.From since it was read, and reports whether it did. Only
+	// pausing and resuming change whether the target is active.
+	ChangeState(change *StateChange) (bool, error)
+	// SetEscalationPolicy changes only the target's escalation policy.
+	SetEscalationPolicy(id, policyID string) error
 }
 
 type ResultRepository interface {
 	Save(result *Result) error
 	FindByTargetID(targetID string) ([]*Result, error)
 	GetLastByTargetID(targetID string) (*Result, error)
+	QueryByTargetID(targetID string, query ResultQuery) (*ResultPage, error)
+	DeleteBefore(targetID string, before time.Time) (int, error)
+}
+
+type RollupRepository interface {
+	// Save replaces any rollup already stored for the same target,
+	// resolution and bucket.
+	Save(rollup *Rollup) error
+	// FindByTargetID returns the rollups whose bucket starts within
+	// [from, to), oldest first.
+	FindByTargetID(targetID string, resolution RollupResolution, from, to time.Time) ([]*Rollup, error)
+	DeleteBefore(targetID string, resolution RollupResolution, before time.Time) (int, error)
+}
+
+type StateChangeRepository interface {
+	Save(change *StateChange) error
+	// FindByTargetID returns the target's state changes, oldest first.
+	FindByTargetID(targetID string) ([]*StateChange, error)
 }
 
 type AlertRepository interface {
 	Save(alert *Alert) error
+	FindByID(id string) (*Alert, error)
 	FindByTargetID(targetID string) ([]*Alert, error)
 	GetUnresolvedByTargetID(targetID string) ([]*Alert, error)
+	// GetUnacknowledged returns the unresolved alerts nobody acknowledged
+	// yet, of all targets, oldest first.
+	GetUnacknowledged() ([]*Alert, error)
 	Update(alert *Alert) error
+	// Acknowledge, Snooze and Assign change only their own fields, so they
+	// don't undo a resolve that happens meanwhile. They return
+	// ErrAlertResolved for resolved alerts.
+	Acknowledge(id, by string, at time.Time) error
+	Snooze(id string, until time.Time) error
+	Assign(id, assignee string) error
+	// Remind records a reminder about alert id sent at the given time,
+	// unless the alert was resolved, acknowledged or snoozed meanwhile.
+	// Resolve resolves it unless it already is. Both change only their own
+	// fields and report whether they did.
+	Remind(id string, at time.Time) (bool, error)
+	Resolve(id string, at time.Time) (bool, error)
+}
+
+type EscalationPolicyRepository interface {
+	Save(policy *EscalationPolicy) error
+	FindByID(id string) (*EscalationPolicy, error)
+	// GetAll returns the policies, oldest first.
+	GetAll() ([]*EscalationPolicy, error)
+	Delete(id string) error
+}
+
+type MaintenanceWindowRepository interface {
+	Save(window *MaintenanceWindow) error
+	FindByID(id string) (*MaintenanceWindow, error)
+	// GetAll returns the windows, oldest first.
+	GetAll() ([]*MaintenanceWindow, error)
+	Delete(id string) error
+}
+
+// OutboxRepository stores alerts together with the messages notifying about
+// them, so that a notification is queued exactly when its alert is saved.
+type OutboxRepository interface {
+	SaveAlert(alert *Alert, messages []*OutboxMessage) error
+	// RemindAlert and ResolveAlert are AlertRepository.Remind and Resolve
+	// that queue messages with the change, and only if it was made.
+	RemindAlert(alertID string, at time.Time, messages []*OutboxMessage) (bool, error)
+	ResolveAlert(alertID string, at time.Time, messages []*OutboxMessage) (bool, error)
+	// AdvanceEscalation moves alert alertID from escalation step from to to
+	// and queues messages with it, unless the alert was resolved,
+	// acknowledged or escalated meanwhile. It reports whether it did.
+	AdvanceEscalation(alertID string, from, to int, messages []*OutboxMessage) (bool, error)
+	FindByID(id string) (*OutboxMessage, error)
+	// Due returns up to limit live messages whose next attempt is at or
+	// before now, earliest first. A message waits while a live message that
+	// precedes it is queued.
+	Due(now time.Time, limit int) ([]*OutboxMessage, error)
+	// DeadLetters returns the dead-lettered messages, oldest first.
+	DeadLetters() ([]*OutboxMessage, error)
+	Update(message *OutboxMessage) error
+	Delete(id string) error
 }
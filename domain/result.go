@@ -2,6 +2,17 @@ package domain
 
 import "time"
 
+const (
+	StatusOK          = "OK"
+	StatusClientError = "CLIENT_ERROR"
+	StatusServerError = "SERVER_ERROR"
+	StatusError       = "ERROR"
+
+	// StatusAssertionFailed is an accepted HTTP response whose body failed
+	// one of the target's assertions.
+	StatusAssertionFailed = "ASSERTION_FAILED"
+)
+
 type Result struct {
 	ID           string
 	TargetID     string
@@ -10,6 +21,17 @@ type Result struct {
 	ResponseTime time.Duration
 	CheckedAt    time.Time
 	Error        error
+
+	// Timings is the phase breakdown of HTTP checks; nil for other checks.
+	Timings *Timings
+
+	// ErrorKind classifies the error of a check that failed to get a
+	// response; see ClassifyError.
+	ErrorKind string
+
+	// InMaintenance marks checks made during a maintenance window of the
+	// target. They open no alerts and are left out of uptime statistics.
+	InMaintenance bool
 }
 
 func NewResult(id, targetID, status string, statusCode int, responseTime time.Duration) *Result {
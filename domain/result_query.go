@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultResultQueryLimit = 100
+	MaxResultQueryLimit     = 1000
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ResultQuery selects results newest-first. Zero From/To leave that side of
+// the range open; To is exclusive.
+type ResultQuery struct {
+	From   time.Time
+	To     time.Time
+	Status string
+	Limit  int
+	Cursor string
+}
+
+type ResultPage struct {
+	Results    []*Result
+	NextCursor string
+}
+
+func (q ResultQuery) EffectiveLimit() int {
+	if q.Limit <= 0 {
+		return DefaultResultQueryLimit
+	}
+
+	if q.Limit > MaxResultQueryLimit {
+		return MaxResultQueryLimit
+	}
+
+	return q.Limit
+}
+
+// ResultCursor points just past the last result of a page. Results are
+// ordered by (CheckedAt, ID) descending, so the next page holds everything
+// strictly older than it.
+type ResultCursor struct {
+	CheckedAt time.Time
+	ID        string
+}
+
+func NewResultCursor(result *Result) ResultCursor {
+	return ResultCursor{CheckedAt: result.CheckedAt, ID: result.ID}
+}
+
+// Before reports whether result comes after the cursor in newest-first order.
+func (c ResultCursor) Before(result *Result) bool {
+	if result.CheckedAt.Equal(c.CheckedAt) {
+		return result.ID < c.ID
+	}
+
+	return result.CheckedAt.Before(c.CheckedAt)
+}
+
+func (c ResultCursor) Encode() string {
+	raw := strconv.FormatInt(c.CheckedAt.UnixNano(), 10) + ":" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeResultCursor(s string) (ResultCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return ResultCursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	nanos, id, found := strings.Cut(string(raw), ":")
+	if !found {
+		return ResultCursor{}, ErrInvalidCursor
+	}
+
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return ResultCursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	return ResultCursor{CheckedAt: time.Unix(0, n), ID: id}, nil
+}
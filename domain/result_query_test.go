@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResultQueryEffectiveLimit(t *testing.T) {
+	cases := []struct {
+		limit    int
+		expected int
+	}{
+		{0, DefaultResultQueryLimit},
+		{-5, DefaultResultQueryLimit},
+		{10, 10},
+		{MaxResultQueryLimit + 1, MaxResultQueryLimit},
+	}
+
+	for _, tc := range cases {
+		if got := (ResultQuery{Limit: tc.limit}).EffectiveLimit(); got != tc.expected {
+			t.Errorf("limit %d: expected %d, got %d", tc.limit, tc.expected, got)
+		}
+	}
+}
+
+func TestResultCursorRoundTrip(t *testing.T) {
+	result := NewResult("result:1", "target-1", StatusOK, 200, time.Millisecond)
+
+	cursor, err := DecodeResultCursor(NewResultCursor(result).Encode())
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !cursor.CheckedAt.Equal(result.CheckedAt) {
+		t.Errorf("expected CheckedAt %v, got %v", result.CheckedAt, cursor.CheckedAt)
+	}
+
+	if cursor.ID != result.ID {
+		t.Errorf("expected ID %s, got %s", result.ID, cursor.ID)
+	}
+}
+
+func TestDecodeResultCursorInvalid(t *testing.T) {
+	for _, s := range []string{"%%%", "bm9jb2xvbg", "YWJjOmlk"} {
+		if _, err := DecodeResultCursor(s); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%q: expected ErrInvalidCursor, got %v", s, err)
+		}
+	}
+}
+
+func TestResultCursorBefore(t *testing.T) {
+	at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cursor := ResultCursor{CheckedAt: at, ID: "b"}
+
+	older := &Result{ID: "z", CheckedAt: at.Add(-time.Second)}
+	tieBefore := &Result{ID: "a", CheckedAt: at}
+	same := &Result{ID: "b", CheckedAt: at}
+	newer := &Result{ID: "a", CheckedAt: at.Add(time.Second)}
+
+	if !cursor.Before(older) || !cursor.Before(tieBefore) {
+		t.Error("expected older results to come after the cursor")
+	}
+
+	if cursor.Before(same) || cursor.Before(newer) {
+		t.Error("expected the cursor result and newer ones to be excluded")
+	}
+}
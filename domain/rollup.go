@@ -0,0 +1,295 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+type RollupResolution string
+
+const (
+	RollupHourly RollupResolution = "HOURLY"
+	RollupDaily  RollupResolution = "DAILY"
+)
+
+func (r RollupResolution) Duration() time.Duration {
+	if r == RollupDaily {
+		return 24 * time.Hour
+	}
+
+	return time.Hour
+}
+
+// Truncate returns the start of the UTC bucket t falls into.
+func (r RollupResolution) Truncate(t time.Time) time.Time {
+	return t.UTC().Truncate(r.Duration())
+}
+
+// Rollup summarizes the results of one target checked within
+// [BucketStart, BucketStart+Resolution).
+type Rollup struct {
+	TargetID        string
+	Resolution      RollupResolution
+	BucketStart     time.Time
+	TotalChecks     int
+	StatusCounts    map[string]int
+	MinResponseTime time.Duration
+	AvgResponseTime time.Duration
+	MaxResponseTime time.Duration
+	P50ResponseTime time.Duration
+	P95ResponseTime time.Duration
+	P99ResponseTime time.Duration
+	UptimePercent   float64
+
+	// PhaseTimings summarizes the timing breakdown of the checks that had
+	// one, keyed by phase.
+	PhaseTimings map[string]TimingSummary
+}
+
+// NewRollup summarizes results, leaving out those checked in maintenance.
+func NewRollup(targetID string, resolution RollupResolution, bucketStart time.Time, results []*Result) *Rollup {
+	rollup := &Rollup{
+		TargetID:     targetID,
+		Resolution:   resolution,
+		BucketStart:  bucketStart,
+		StatusCounts: make(map[string]int),
+	}
+
+	var (
+		dist   ResponseTimeDistribution
+		phases PhaseDistributions
+	)
+
+	for _, result := range results {
+		if result.InMaintenance {
+			continue
+		}
+
+		rollup.TotalChecks++
+		rollup.StatusCounts[result.Status]++
+		dist.Add(result.ResponseTime)
+		phases.Add(result.Timings)
+	}
+
+	rollup.summarize(&dist, phases)
+
+	return rollup
+}
+
+// MergeRollups folds finer rollups (e.g. the hours of a day) into one bucket.
+// Counts and the mean stay exact; percentiles are approximated from the
+// merged distribution.
+func MergeRollups(targetID string, resolution RollupResolution, bucketStart time.Time, rollups []*Rollup) *Rollup {
+	merged := &Rollup{
+		TargetID:     targetID,
+		Resolution:   resolution,
+		BucketStart:  bucketStart,
+		StatusCounts: make(map[string]int),
+	}
+
+	var (
+		dist   ResponseTimeDistribution
+		phases PhaseDistributions
+	)
+
+	for _, rollup := range rollups {
+		merged.TotalChecks += rollup.TotalChecks
+		for status, n := range rollup.StatusCounts {
+			merged.StatusCounts[status] += n
+		}
+		dist.AddRollup(rollup)
+		phases.AddRollup(rollup)
+	}
+
+	merged.summarize(&dist, phases)
+
+	return merged
+}
+
+func (r *Rollup) BucketEnd() time.Time {
+	return r.BucketStart.Add(r.Resolution.Duration())
+}
+
+func (r *Rollup) summarize(dist *ResponseTimeDistribution, phases PhaseDistributions) {
+	if r.TotalChecks > 0 {
+		r.UptimePercent = float64(r.StatusCounts[StatusOK]) / float64(r.TotalChecks) * 100
+	}
+
+	summary := dist.Summary()
+
+	r.MinResponseTime = summary.Min
+	r.AvgResponseTime = summary.Avg
+	r.MaxResponseTime = summary.Max
+	r.P50ResponseTime = summary.P50
+	r.P95ResponseTime = summary.P95
+	r.P99ResponseTime = summary.P99
+
+	r.PhaseTimings = make(map[string]TimingSummary, len(phases))
+	for phase, phaseDist := range phases {
+		r.PhaseTimings[phase] = phaseDist.Summary()
+	}
+}
+
+// responseTimeSummary returns the response time figures of the rollup.
+func (r *Rollup) responseTimeSummary() TimingSummary {
+	return TimingSummary{
+		Count: r.TotalChecks,
+		Min:   r.MinResponseTime,
+		Avg:   r.AvgResponseTime,
+		Max:   r.MaxResponseTime,
+		P50:   r.P50ResponseTime,
+		P95:   r.P95ResponseTime,
+		P99:   r.P99ResponseTime,
+	}
+}
+
+type weightedDuration struct {
+	value  time.Duration
+	weight float64
+}
+
+// ResponseTimeDistribution collects response times from raw results and from
+// rollups. A rollup only keeps a few quantiles, so it is represented by those
+// quantiles weighted by the share of checks below them; the percentiles of a
+// single rollup therefore come back unchanged.
+type ResponseTimeDistribution struct {
+	samples []weightedDuration
+	sum     float64
+	count   float64
+	min     time.Duration
+	max     time.Duration
+}
+
+func (d *ResponseTimeDistribution) Add(responseTime time.Duration) {
+	d.add(responseTime, 1)
+	d.observe(responseTime, responseTime, float64(responseTime), 1)
+}
+
+func (d *ResponseTimeDistribution) AddRollup(rollup *Rollup) {
+	d.AddSummary(rollup.responseTimeSummary())
+}
+
+func (d *ResponseTimeDistribution) AddSummary(summary TimingSummary) {
+	if summary.Count == 0 {
+		return
+	}
+
+	n := float64(summary.Count)
+
+	d.add(summary.P50, 0.50*n)
+	d.add(summary.P95, 0.45*n)
+	d.add(summary.P99, 0.04*n)
+	d.add(summary.Max, 0.01*n)
+	d.observe(summary.Min, summary.Max, float64(summary.Avg)*n, n)
+}
+
+func (d *ResponseTimeDistribution) Summary() TimingSummary {
+	return TimingSummary{
+		Count: d.Count(),
+		Min:   d.Min(),
+		Avg:   d.Mean(),
+		Max:   d.Max(),
+		P50:   d.Percentile(50),
+		P95:   d.Percentile(95),
+		P99:   d.Percentile(99),
+	}
+}
+
+func (d *ResponseTimeDistribution) Count() int {
+	return int(d.count)
+}
+
+func (d *ResponseTimeDistribution) Mean() time.Duration {
+	if d.count == 0 {
+		return 0
+	}
+
+	return time.Duration(d.sum / d.count)
+}
+
+func (d *ResponseTimeDistribution) Min() time.Duration {
+	return d.min
+}
+
+func (d *ResponseTimeDistribution) Max() time.Duration {
+	return d.max
+}
+
+// Percentile uses the nearest-rank method, so it always returns an observed
+// value.
+func (d *ResponseTimeDistribution) Percentile(p int) time.Duration {
+	if len(d.samples) == 0 {
+		return 0
+	}
+
+	sort.SliceStable(d.samples, func(i, j int) bool { return d.samples[i].value < d.samples[j].value })
+
+	var total float64
+	for _, s := range d.samples {
+		total += s.weight
+	}
+
+	rank := float64(p) * total / 100
+	tolerance := total * 1e-9
+
+	var cumulative float64
+	for _, s := range d.samples {
+		cumulative += s.weight
+		if cumulative+tolerance >= rank && cumulative > 0 {
+			return s.value
+		}
+	}
+
+	return d.samples[len(d.samples)-1].value
+}
+
+// PhaseDistributions collects one distribution per timing phase. The zero
+// value is ready to use.
+type PhaseDistributions map[string]*ResponseTimeDistribution
+
+func (p *PhaseDistributions) Add(timings *Timings) {
+	if timings == nil {
+		return
+	}
+
+	for _, phase := range TimingPhases {
+		p.phase(phase).Add(timings.Phase(phase))
+	}
+}
+
+func (p *PhaseDistributions) AddRollup(rollup *Rollup) {
+	for phase, summary := range rollup.PhaseTimings {
+		p.phase(phase).AddSummary(summary)
+	}
+}
+
+func (p *PhaseDistributions) phase(name string) *ResponseTimeDistribution {
+	if *p == nil {
+		*p = make(PhaseDistributions)
+	}
+
+	if (*p)[name] == nil {
+		(*p)[name] = &ResponseTimeDistribution{}
+	}
+
+	return (*p)[name]
+}
+
+func (d *ResponseTimeDistribution) add(value time.Duration, weight float64) {
+	if weight > 0 {
+		d.samples = append(d.samples, weightedDuration{value: value, weight: weight})
+	}
+}
+
+func (d *ResponseTimeDistribution) observe(lo, hi time.Duration, sum, count float64) {
+	if d.count == 0 || lo < d.min {
+		d.min = lo
+	}
+
+	if d.count == 0 || hi > d.max {
+		d.max = hi
+	}
+
+	d.sum += sum
+	d.count += count
+}
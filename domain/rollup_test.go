@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRollupResolutionTruncate(t *testing.T) {
+	ts := time.Date(2025, 3, 4, 15, 42, 7, 0, time.FixedZone("CET", 3600))
+
+	if got := RollupHourly.Truncate(ts); !got.Equal(time.Date(2025, 3, 4, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected hour bucket 14:00 UTC, got %s", got)
+	}
+
+	if got := RollupDaily.Truncate(ts); !got.Equal(time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected day bucket 2025-03-04 UTC, got %s", got)
+	}
+}
+
+func TestNewRollup(t *testing.T) {
+	bucket := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	var results []*Result
+	for i := 1; i <= 100; i++ {
+		status := StatusOK
+		if i%10 == 0 {
+			status = StatusServerError
+		}
+		results = append(results, NewResult(fmt.Sprint(i), "target-1", status, 200, time.Duration(i)*time.Millisecond))
+	}
+
+	rollup := NewRollup("target-1", RollupHourly, bucket, results)
+
+	if rollup.TotalChecks != 100 || rollup.StatusCounts[StatusServerError] != 10 {
+		t.Errorf("unexpected counts %d %v", rollup.TotalChecks, rollup.StatusCounts)
+	}
+
+	if rollup.UptimePercent != 90 {
+		t.Errorf("expected uptime 90%%, got %f", rollup.UptimePercent)
+	}
+
+	if rollup.MinResponseTime != time.Millisecond || rollup.MaxResponseTime != 100*time.Millisecond {
+		t.Errorf("expected min 1ms and max 100ms, got %s and %s", rollup.MinResponseTime, rollup.MaxResponseTime)
+	}
+
+	if rollup.AvgResponseTime != 50500*time.Microsecond {
+		t.Errorf("expected mean 50.5ms, got %s", rollup.AvgResponseTime)
+	}
+
+	if rollup.P50ResponseTime != 50*time.Millisecond || rollup.P95ResponseTime != 95*time.Millisecond || rollup.P99ResponseTime != 99*time.Millisecond {
+		t.Errorf("unexpected percentiles %s/%s/%s", rollup.P50ResponseTime, rollup.P95ResponseTime, rollup.P99ResponseTime)
+	}
+
+	if !rollup.BucketEnd().Equal(bucket.Add(time.Hour)) {
+		t.Errorf("expected bucket to end an hour later, got %s", rollup.BucketEnd())
+	}
+}
+
+func TestNewRollup_LeavesOutMaintenance(t *testing.T) {
+	down := NewResult("2", "target-1", StatusServerError, 503, time.Second)
+	down.InMaintenance = true
+
+	rollup := NewRollup("target-1", RollupHourly, time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), []*Result{
+		NewResult("1", "target-1", StatusOK, 200, time.Millisecond),
+		down,
+	})
+
+	if rollup.TotalChecks != 1 || rollup.UptimePercent != 100 || rollup.MaxResponseTime != time.Millisecond {
+		t.Errorf("expected only the check outside maintenance, got %d checks, %f%% and max %s", rollup.TotalChecks, rollup.UptimePercent, rollup.MaxResponseTime)
+	}
+}
+
+func TestMergeRollups(t *testing.T) {
+	day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	first := NewRollup("target-1", RollupHourly, day, []*Result{
+		NewResult("1", "target-1", StatusOK, 200, 10*time.Millisecond),
+		NewResult("2", "target-1", StatusOK, 200, 30*time.Millisecond),
+	})
+	second := NewRollup("target-1", RollupHourly, day.Add(time.Hour), []*Result{
+		NewResult("3", "target-1", StatusServerError, 500, 80*time.Millisecond),
+	})
+
+	merged := MergeRollups("target-1", RollupDaily, day, []*Rollup{first, second})
+
+	if merged.TotalChecks != 3 || merged.StatusCounts[StatusOK] != 2 || merged.StatusCounts[StatusServerError] != 1 {
+		t.Errorf("unexpected counts %d %v", merged.TotalChecks, merged.StatusCounts)
+	}
+
+	if merged.AvgResponseTime != 40*time.Millisecond {
+		t.Errorf("expected exact mean 40ms, got %s", merged.AvgResponseTime)
+	}
+
+	if merged.MinResponseTime != 10*time.Millisecond || merged.MaxResponseTime != 80*time.Millisecond {
+		t.Errorf("expected min 10ms and max 80ms, got %s and %s", merged.MinResponseTime, merged.MaxResponseTime)
+	}
+
+	if merged.P99ResponseTime != 80*time.Millisecond {
+		t.Errorf("expected p99 80ms, got %s", merged.P99ResponseTime)
+	}
+}
+
+func TestRollup_PhaseTimings(t *testing.T) {
+	day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	timed := func(id string, tls, server time.Duration) *Result {
+		result := NewResult(id, "target-1", StatusOK, 200, tls+server)
+		result.Timings = &Timings{TLS: tls, Server: server}
+		return result
+	}
+
+	first := NewRollup("target-1", RollupHourly, day, []*Result{
+		timed("1", 20*time.Millisecond, 100*time.Millisecond),
+		timed("2", 40*time.Millisecond, 300*time.Millisecond),
+		NewResult("3", "target-1", StatusOK, 200, time.Second),
+	})
+
+	if tls := first.PhaseTimings[PhaseTLS]; tls.Count != 2 || tls.Avg != 30*time.Millisecond || tls.Max != 40*time.Millisecond {
+		t.Errorf("expected TLS timings of the 2 timed checks, got %+v", tls)
+	}
+
+	second := NewRollup("target-1", RollupHourly, day.Add(time.Hour), []*Result{
+		timed("4", 60*time.Millisecond, 200*time.Millisecond),
+	})
+
+	merged := MergeRollups("target-1", RollupDaily, day, []*Rollup{first, second})
+
+	if server := merged.PhaseTimings[PhaseServer]; server.Count != 3 || server.Avg != 200*time.Millisecond || server.Max != 300*time.Millisecond {
+		t.Errorf("expected merged server timings, got %+v", server)
+	}
+
+	if untimed := NewRollup("target-1", RollupHourly, day, []*Result{NewResult("5", "target-1", StatusOK, 0, 0)}); len(untimed.PhaseTimings) != 0 {
+		t.Errorf("expected no phase timings without timed checks, got %+v", untimed.PhaseTimings)
+	}
+}
+
+func TestResponseTimeDistribution_SingleRollupRoundTrip(t *testing.T) {
+	rollup := &Rollup{
+		TotalChecks:     200,
+		MinResponseTime: time.Millisecond,
+		AvgResponseTime: 20 * time.Millisecond,
+		MaxResponseTime: 500 * time.Millisecond,
+		P50ResponseTime: 15 * time.Millisecond,
+		P95ResponseTime: 60 * time.Millisecond,
+		P99ResponseTime: 200 * time.Millisecond,
+	}
+
+	var dist ResponseTimeDistribution
+	dist.AddRollup(rollup)
+
+	if dist.Count() != 200 || dist.Mean() != 20*time.Millisecond {
+		t.Errorf("expected 200 checks averaging 20ms, got %d and %s", dist.Count(), dist.Mean())
+	}
+
+	if dist.Percentile(50) != 15*time.Millisecond || dist.Percentile(95) != 60*time.Millisecond || dist.Percentile(99) != 200*time.Millisecond {
+		t.Errorf("expected rollup percentiles back, got %s/%s/%s", dist.Percentile(50), dist.Percentile(95), dist.Percentile(99))
+	}
+
+	if dist.Min() != time.Millisecond || dist.Max() != 500*time.Millisecond {
+		t.Errorf("expected min 1ms and max 500ms, got %s and %s", dist.Min(), dist.Max())
+	}
+}
+
+func TestResponseTimeDistribution_Empty(t *testing.T) {
+	var dist ResponseTimeDistribution
+	dist.AddRollup(&Rollup{})
+
+	if dist.Count() != 0 || dist.Mean() != 0 || dist.Percentile(95) != 0 {
+		t.Errorf("expected zero values, got %d %s %s", dist.Count(), dist.Mean(), dist.Percentile(95))
+	}
+}
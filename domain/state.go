@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// States of a monitored target. A new target is UNKNOWN until its first
+// check decides; a paused target is not checked and resumes as UNKNOWN.
+const (
+	StateUnknown  = "UNKNOWN"
+	StateUp       = "UP"
+	StateDown     = "DOWN"
+	StateDegraded = "DEGRADED"
+	StatePaused   = "PAUSED"
+)
+
+var ErrInvalidTransition = errors.New("invalid state transition")
+
+var stateTransitions = map[string][]string{
+	StateUnknown:  {StateUp, StateDown, StateDegraded, StatePaused},
+	StateUp:       {StateDown, StateDegraded, StatePaused},
+	StateDown:     {StateUp, StateDegraded, StatePaused},
+	StateDegraded: {StateUp, StateDown, StatePaused},
+	StatePaused:   {StateUnknown},
+}
+
+// CanTransition reports whether a target may move from one state to another.
+func CanTransition(from, to string) bool {
+	return slices.Contains(stateTransitions[from], to)
+}
+
+// StateChange records a target moving between states. Reason is the status
+// of the check that caused it, or why the target was paused or resumed.
+type StateChange struct {
+	ID        string
+	TargetID  string
+	From      string
+	To        string
+	Reason    string
+	ChangedAt time.Time
+}
+
+// Activity reports whether the change pauses or resumes the target and, if
+// so, whether the target is active after it.
+func (c *StateChange) Activity() (active, changes bool) {
+	switch {
+	case c.To == StatePaused:
+		return false, true
+	case c.From == StatePaused:
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// Transition moves the target to state and returns the change, or nil when
+// the target already is in that state. A target without a state is UNKNOWN.
+func (t *Target) Transition(state, reason string, at time.Time) (*StateChange, error) {
+	from := t.State
+	if from == "" {
+		from = StateUnknown
+	}
+
+	if from == state {
+		return nil, nil
+	}
+
+	if !CanTransition(from, state) {
+		return nil, fmt.Errorf("%w from %s to %s", ErrInvalidTransition, from, state)
+	}
+
+	change := &StateChange{
+		TargetID:  t.ID,
+		From:      from,
+		To:        state,
+		Reason:    reason,
+		ChangedAt: at,
+	}
+
+	t.State = state
+	t.StateChangedAt = at
+
+	return change, nil
+}
+
+// Pause stops checks of the target.
+func (t *Target) Pause(at time.Time) (*StateChange, error) {
+	t.IsActive = false
+	return t.Transition(StatePaused, "paused", at)
+}
+
+// Resume restarts checks of a paused target, which is UNKNOWN until the next
+// check.
+func (t *Target) Resume(at time.Time) (*StateChange, error) {
+	t.IsActive = true
+	return t.Transition(StateUnknown, "resumed", at)
+}
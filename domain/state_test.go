@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTarget_Transition(t *testing.T) {
+	target := NewTarget("target-1", "https://example.com", "", time.Minute)
+	if target.State != StateUnknown {
+		t.Fatalf("expected a new target to be UNKNOWN, got %s", target.State)
+	}
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	change, err := target.Transition(StateDown, StatusServerError, at)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if change.From != StateUnknown || change.To != StateDown || change.Reason != StatusServerError || !change.ChangedAt.Equal(at) {
+		t.Errorf("unexpected change %+v", change)
+	}
+
+	if target.State != StateDown || !target.StateChangedAt.Equal(at) {
+		t.Errorf("expected the target to be DOWN since %v, got %s since %v", at, target.State, target.StateChangedAt)
+	}
+
+	change, err = target.Transition(StateDown, StatusError, at.Add(time.Minute))
+	if change != nil || err != nil {
+		t.Errorf("expected staying DOWN to be no change, got %+v, %v", change, err)
+	}
+
+	if !target.StateChangedAt.Equal(at) {
+		t.Errorf("expected the entered-at time to be kept, got %v", target.StateChangedAt)
+	}
+}
+
+func TestTarget_PauseAndResume(t *testing.T) {
+	target := NewTarget("target-1", "https://example.com", "", time.Minute)
+	target.State = StateUp
+
+	if _, err := target.Pause(time.Now()); err != nil || target.State != StatePaused || target.IsActive {
+		t.Fatalf("expected the target to be paused, got %s (active %t), %v", target.State, target.IsActive, err)
+	}
+
+	if _, err := target.Transition(StateDown, StatusError, time.Now()); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("expected a paused target not to go DOWN, got %v", err)
+	}
+
+	if _, err := target.Resume(time.Now()); err != nil || target.State != StateUnknown || !target.IsActive {
+		t.Errorf("expected the target to resume as UNKNOWN, got %s (active %t), %v", target.State, target.IsActive, err)
+	}
+}
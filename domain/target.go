@@ -1,6 +1,18 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	TargetTypeHTTP = "http"
+	TargetTypeTCP  = "tcp"
+	TargetTypeDNS  = "dns"
+)
 
 type Target struct {
 	ID        string
@@ -9,19 +21,96 @@ type Target struct {
 	Interval  time.Duration
 	IsActive  bool
 	CreatedAt time.Time
+
+	// HTTP configures checks of http and https targets; nil means
+	// DefaultHTTPCheck.
+	HTTP *HTTPCheck
+
+	// Policy confirms failures and recoveries; nil means the zero policy.
+	Policy *CheckPolicy
+
+	// Latency marks the target DEGRADED when it answers slowly; nil never
+	// does.
+	Latency *LatencyThreshold
+
+	// State is one of the State constants, entered at StateChangedAt.
+	State          string
+	StateChangedAt time.Time
+
+	// AlertEmails receive the target's alerts by email instead of the
+	// default recipients.
+	AlertEmails []string
+
+	// EscalationPolicyID names the policy escalating the target's alerts;
+	// empty means none.
+	EscalationPolicyID string
+
+	// Tags group targets, e.g. for maintenance windows.
+	Tags []string
 }
 
 func NewTarget(id, url, name string, interval time.Duration) *Target {
+	now := time.Now()
+
 	return &Target{
 		ID:        id,
 		URL:       url,
 		Name:      name,
 		Interval:  interval,
 		IsActive:  true,
-		CreatedAt: time.Now(),
+		CreatedAt: now,
+
+		State:          StateUnknown,
+		StateChangedAt: now,
 	}
 }
 
 func (t *Target) IsValid() bool {
 	return len(t.URL) > 0 && t.Interval > 0
-}
\ No newline at end of file
+}
+
+// ValidateAlertEmails checks that every alert email is a bare address such as
+// ops@example.com.
+func (t *Target) ValidateAlertEmails() error {
+	for _, email := range t.AlertEmails {
+		addr, err := mail.ParseAddress(email)
+		if err != nil || addr.Address != email {
+			return fmt.Errorf("invalid alert email %q", email)
+		}
+	}
+
+	return nil
+}
+
+// Type is the kind of check the target needs, taken from its URL scheme.
+// http and https targets share the HTTP type; an unparsable URL has none.
+func (t *Target) Type() string {
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		return ""
+	}
+
+	switch scheme := strings.ToLower(u.Scheme); scheme {
+	case "http", "https":
+		return TargetTypeHTTP
+	default:
+		return scheme
+	}
+}
+
+// EffectiveHTTPCheck returns the target's HTTP check spec or the default one.
+func (t *Target) EffectiveHTTPCheck() *HTTPCheck {
+	if t.HTTP != nil {
+		return t.HTTP
+	}
+
+	return DefaultHTTPCheck()
+}
+
+func (t *Target) EffectiveCheckPolicy() CheckPolicy {
+	if t.Policy != nil {
+		return *t.Policy
+	}
+
+	return CheckPolicy{}
+}
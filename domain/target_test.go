@@ -65,3 +65,46 @@ func TestIsValid(t *testing.T) {
 		t.Errorf("expected interval false, got true for target4")
 	}
 }
+
+func TestTargetType(t *testing.T) {
+	cases := []struct {
+		url      string
+		expected string
+	}{
+		{"https://example.com", TargetTypeHTTP},
+		{"HTTP://example.com", TargetTypeHTTP},
+		{"tcp://db.internal:5432", TargetTypeTCP},
+		{"example.com", ""},
+		{"://bad", ""},
+	}
+
+	for _, tc := range cases {
+		target := NewTarget("target-1", tc.url, "", time.Second)
+
+		if got := target.Type(); got != tc.expected {
+			t.Errorf("%s: expected type %q, got %q", tc.url, tc.expected, got)
+		}
+	}
+}
+
+func TestValidateAlertEmails(t *testing.T) {
+	cases := []struct {
+		emails []string
+		valid  bool
+	}{
+		{nil, true},
+		{[]string{"ops@example.com", "oncall@example.com"}, true},
+		{[]string{"ops"}, false},
+		{[]string{"Ops <ops@example.com>"}, false},
+		{[]string{"ops@example.com", ""}, false},
+	}
+
+	for _, tc := range cases {
+		target := NewTarget("target-1", "https://example.com", "", time.Second)
+		target.AlertEmails = tc.emails
+
+		if err := target.ValidateAlertEmails(); (err == nil) != tc.valid {
+			t.Errorf("%v: expected valid %t, got error %v", tc.emails, tc.valid, err)
+		}
+	}
+}
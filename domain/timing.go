@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+const (
+	PhaseDNS      = "dns"
+	PhaseConnect  = "connect"
+	PhaseTLS      = "tls"
+	PhaseServer   = "server"
+	PhaseTransfer = "transfer"
+)
+
+// TimingPhases lists the phases of an HTTP check in the order they happen.
+var TimingPhases = []string{PhaseDNS, PhaseConnect, PhaseTLS, PhaseServer, PhaseTransfer}
+
+// Timings breaks an HTTP check down into phases. Server is the time between
+// writing the request and the first response byte, Transfer the time spent
+// reading the body. A reused connection has no DNS, connect or TLS time.
+type Timings struct {
+	DNS      time.Duration
+	Connect  time.Duration
+	TLS      time.Duration
+	Server   time.Duration
+	Transfer time.Duration
+}
+
+func (t *Timings) Phase(name string) time.Duration {
+	switch name {
+	case PhaseDNS:
+		return t.DNS
+	case PhaseConnect:
+		return t.Connect
+	case PhaseTLS:
+		return t.TLS
+	case PhaseServer:
+		return t.Server
+	case PhaseTransfer:
+		return t.Transfer
+	default:
+		return 0
+	}
+}
+
+// TimingSummary is what a rollup keeps of a set of durations.
+type TimingSummary struct {
+	Count int
+	Min   time.Duration
+	Avg   time.Duration
+	Max   time.Duration
+	P50   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+}
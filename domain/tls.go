@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+type Certificate struct {
+	Subject   string
+	Issuer    string
+	NotBefore time.Time
+	NotAfter  time.Time
+	DNSNames  []string
+}
+
+// TLSInfo describes the certificate chain a target presented, leaf first.
+// Verified is false when the chain didn't validate against the trusted roots
+// or for the target's host; VerifyError then says why.
+type TLSInfo struct {
+	Chain           []Certificate
+	HostnameMatches bool
+	Verified        bool
+	VerifyError     string
+}
+
+// ExpiresAt is when the first certificate in the chain expires.
+func (t *TLSInfo) ExpiresAt() time.Time {
+	var expiresAt time.Time
+
+	for _, cert := range t.Chain {
+		if expiresAt.IsZero() || cert.NotAfter.Before(expiresAt) {
+			expiresAt = cert.NotAfter
+		}
+	}
+
+	return expiresAt
+}
@@ -0,0 +1,324 @@
+// Package chat posts alert notifications to Slack or Mattermost.
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+const (
+	defaultTimeout  = 10 * time.Second
+	defaultSlackAPI = "https://slack.com/api"
+
+	colorDown     = "#d00000"
+	colorWarning  = "#daa038"
+	colorResolved = "#2eb886"
+)
+
+type Option func(*Notifier)
+
+// WithAPIURL links every message to the target in the uptime API served at
+// url, e.g. https://uptime.example.com.
+func WithAPIURL(url string) Option {
+	return func(n *Notifier) {
+		n.apiURL = strings.TrimRight(url, "/")
+	}
+}
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(n *Notifier) {
+		n.client = client
+	}
+}
+
+// Notifier posts every notification as a message with a colour-coded
+// attachment: red for downtime, amber for other alerts and green once they
+// are resolved.
+//
+// Through an incoming webhook, which Slack and Mattermost both accept, the
+// resolve message refers back to the alert it resolves. Through the Slack Web
+// API the original message is updated in place instead and the resolution,
+// reminders and escalations are posted in its thread.
+type Notifier struct {
+	webhookURL string
+	token      string
+	channel    string
+	slackAPI   string
+	apiURL     string
+	client     *http.Client
+	now        func() time.Time
+
+	// posted maps alert IDs to the timestamp of their Slack message.
+	mu     sync.Mutex
+	posted map[string]string
+}
+
+// NewWebhookNotifier posts to a Slack or Mattermost incoming webhook.
+func NewWebhookNotifier(webhookURL string, opts ...Option) *Notifier {
+	return newNotifier(&Notifier{webhookURL: webhookURL}, opts)
+}
+
+// NewSlackNotifier posts to channel with a Slack bot token, which lets it
+// update the message of an alert once the alert is resolved.
+func NewSlackNotifier(token, channel string, opts ...Option) *Notifier {
+	return newNotifier(&Notifier{token: token, channel: channel}, opts)
+}
+
+func newNotifier(n *Notifier, opts []Option) *Notifier {
+	n.slackAPI = defaultSlackAPI
+	n.client = &http.Client{Timeout: defaultTimeout}
+	n.now = time.Now
+	n.posted = make(map[string]string)
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	return n
+}
+
+// Message is the body of an incoming webhook or a Slack chat.postMessage
+// call.
+type Message struct {
+	Channel     string       `json:"channel,omitempty"`
+	TS          string       `json:"ts,omitempty"`
+	ThreadTS    string       `json:"thread_ts,omitempty"`
+	Text        string       `json:"text"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+type Attachment struct {
+	Fallback  string  `json:"fallback"`
+	Color     string  `json:"color"`
+	Title     string  `json:"title"`
+	TitleLink string  `json:"title_link,omitempty"`
+	Text      string  `json:"text"`
+	Fields    []Field `json:"fields"`
+	Footer    string  `json:"footer"`
+	TS        int64   `json:"ts"`
+}
+
+type Field struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+func (n *Notifier) Notify(ctx context.Context, notification domain.Notification) error {
+	if n.webhookURL != "" {
+		return n.post(ctx, n.webhookURL, n.NewMessage(notification), nil)
+	}
+
+	if notification.Event == domain.NotificationResolved {
+		return n.updateSlack(ctx, notification)
+	}
+
+	msg := n.NewMessage(notification)
+	msg.Channel = n.channel
+
+	n.mu.Lock()
+	ts, exists := n.posted[notification.Alert.ID]
+	n.mu.Unlock()
+
+	if (notification.Event == domain.NotificationRepeated || notification.Event == domain.NotificationEscalated) && exists {
+		msg.ThreadTS = ts
+		_, err := n.callSlack(ctx, "chat.postMessage", msg)
+		return err
+	}
+
+	ts, err := n.callSlack(ctx, "chat.postMessage", msg)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	n.posted[notification.Alert.ID] = ts
+	n.mu.Unlock()
+
+	return nil
+}
+
+// updateSlack turns the alert's message green and replies in its thread. An
+// alert posted before a restart has no known message and gets a new one.
+func (n *Notifier) updateSlack(ctx context.Context, notification domain.Notification) error {
+	n.mu.Lock()
+	ts, exists := n.posted[notification.Alert.ID]
+	n.mu.Unlock()
+
+	msg := n.NewMessage(notification)
+	msg.Channel = n.channel
+
+	if !exists {
+		_, err := n.callSlack(ctx, "chat.postMessage", msg)
+		return err
+	}
+
+	msg.TS = ts
+	if _, err := n.callSlack(ctx, "chat.update", msg); err != nil {
+		return err
+	}
+
+	reply := Message{Channel: n.channel, ThreadTS: ts, Text: msg.Text}
+	if _, err := n.callSlack(ctx, "chat.postMessage", reply); err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	delete(n.posted, notification.Alert.ID)
+	n.mu.Unlock()
+
+	return nil
+}
+
+// NewMessage formats a notification. Resolve messages, reminders and
+// escalations carry the title of the alert they are about and when it was
+// opened.
+func (n *Notifier) NewMessage(notification domain.Notification) Message {
+	alert, target := notification.Alert, notification.Target
+
+	name := target.Name
+	if name == "" {
+		name = target.URL
+	}
+
+	title := fmt.Sprintf("%s: %s", name, alert.Type)
+	text := alert.Message
+	color := colorWarning
+	if alert.IsDowntime() {
+		color = colorDown
+	}
+
+	fields := []Field{
+		{Title: "Target", Value: fmt.Sprintf("<%s|%s>", target.URL, name), Short: true},
+		{Title: "Status", Value: target.State, Short: true},
+	}
+
+	if notification.ResponseTime > 0 {
+		fields = append(fields, Field{Title: "Response time", Value: notification.ResponseTime.Round(time.Millisecond).String(), Short: true})
+	}
+
+	at := alert.CreatedAt
+	if notification.Event == domain.NotificationResolved {
+		at = n.now()
+		if alert.ResolvedAt != nil {
+			at = *alert.ResolvedAt
+		}
+
+		title = "Resolved: " + title
+		text = fmt.Sprintf("Resolved after %s. Opened %s: %s", formatDuration(at.Sub(alert.CreatedAt)), alert.CreatedAt.UTC().Format(time.RFC3339), alert.Message)
+		color = colorResolved
+
+		if alert.IsDowntime() {
+			fields = append(fields, Field{Title: "Was down for", Value: formatDuration(at.Sub(alert.CreatedAt)), Short: true})
+		}
+	} else if target.State == domain.StateDown && !target.StateChangedAt.IsZero() {
+		fields = append(fields, Field{Title: "Down for", Value: formatDuration(n.now().Sub(target.StateChangedAt)), Short: true})
+	}
+
+	switch notification.Event {
+	case domain.NotificationRepeated:
+		at = n.now()
+		title = "Reminder: " + title
+		text = fmt.Sprintf("Still open since %s: %s", alert.CreatedAt.UTC().Format(time.RFC3339), alert.Message)
+	case domain.NotificationEscalated:
+		at = n.now()
+		title = "Escalated: " + title
+		text = fmt.Sprintf("Not acknowledged since %s (escalation step %d): %s", alert.CreatedAt.UTC().Format(time.RFC3339), alert.EscalationStep, alert.Message)
+	}
+
+	var link string
+	if n.apiURL != "" {
+		link = n.apiURL + "/targets/" + target.ID
+	}
+
+	return Message{
+		Text: title,
+		Attachments: []Attachment{{
+			Fallback:  title + " - " + alert.Message,
+			Color:     color,
+			Title:     title,
+			TitleLink: link,
+			Text:      text,
+			Fields:    fields,
+			Footer:    "alert " + alert.ID,
+			TS:        at.Unix(),
+		}},
+	}
+}
+
+// callSlack calls a Slack Web API method and returns the ts of the message
+// it posted or updated.
+func (n *Notifier) callSlack(ctx context.Context, method string, msg Message) (string, error) {
+	var res struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+		TS    string `json:"ts"`
+	}
+
+	if err := n.post(ctx, n.slackAPI+"/"+method, msg, &res); err != nil {
+		return "", err
+	}
+
+	if !res.OK {
+		return "", fmt.Errorf("slack %s failed: %s", method, res.Error)
+	}
+
+	return res.TS, nil
+}
+
+// post sends msg as JSON and decodes the answer into res unless it is nil.
+func (n *Notifier) post(ctx context.Context, url string, msg Message, res any) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("chat %s answered %s: %s", req.URL.Host, resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	if res == nil {
+		return nil
+	}
+
+	return json.Unmarshal(data, res)
+}
+
+// formatDuration rounds d to the second, or to the minute past an hour.
+func formatDuration(d time.Duration) string {
+	if d >= time.Hour {
+		return d.Round(time.Minute).String()
+	}
+
+	return d.Round(time.Second).String()
+}
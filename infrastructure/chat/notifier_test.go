@@ -0,0 +1,224 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+type request struct {
+	path   string
+	header http.Header
+	msg    Message
+}
+
+// receive starts a chat server that answers every request with status and
+// body and passes on the messages it got.
+func receive(t *testing.T, status int, body string) (string, <-chan request) {
+	t.Helper()
+
+	requests := make(chan request, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+
+		var msg Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Errorf("expected a JSON message, got %s", data)
+		}
+
+		requests <- request{path: r.URL.Path, header: r.Header, msg: msg}
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv.URL, requests
+}
+
+var openedAt = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func newNotification(event string) domain.Notification {
+	target := domain.NewTarget("target-1", "https://example.com", "Example", time.Minute)
+	target.State = domain.StateDown
+	target.StateChangedAt = openedAt
+
+	alert := domain.NewAlert("alert-1", target.ID, domain.StatusServerError, "Target https://example.com is SERVER_ERROR")
+	alert.CreatedAt = openedAt
+
+	if event == domain.NotificationResolved {
+		alert.Resolve()
+		resolvedAt := openedAt.Add(90 * time.Minute)
+		alert.ResolvedAt = &resolvedAt
+		target.State = domain.StateUp
+	}
+
+	return domain.Notification{Event: event, Alert: *alert, Target: *target, ResponseTime: 250 * time.Millisecond}
+}
+
+func fieldValue(attachment Attachment, title string) string {
+	for _, field := range attachment.Fields {
+		if field.Title == title {
+			return field.Value
+		}
+	}
+
+	return ""
+}
+
+func TestWebhookNotifier_Opened(t *testing.T) {
+	url, requests := receive(t, http.StatusOK, "ok")
+
+	notifier := NewWebhookNotifier(url, WithAPIURL("https://uptime.example.com/"))
+	notifier.now = func() time.Time { return openedAt.Add(5 * time.Minute) }
+
+	if err := notifier.Notify(context.Background(), newNotification(domain.NotificationOpened)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	req := <-requests
+	if req.header.Get("Authorization") != "" {
+		t.Errorf("expected no token to be sent to a webhook, got %q", req.header.Get("Authorization"))
+	}
+
+	if len(req.msg.Attachments) != 1 {
+		t.Fatalf("expected 1 attachment, got %d", len(req.msg.Attachments))
+	}
+
+	attachment := req.msg.Attachments[0]
+	if attachment.Color != colorDown || attachment.Title != "Example: SERVER_ERROR" || attachment.Text != "Target https://example.com is SERVER_ERROR" {
+		t.Errorf("unexpected attachment %+v", attachment)
+	}
+
+	if attachment.TitleLink != "https://uptime.example.com/targets/target-1" {
+		t.Errorf("expected a link to the target in the API, got %q", attachment.TitleLink)
+	}
+
+	for title, want := range map[string]string{
+		"Target":        "<https://example.com|Example>",
+		"Status":        domain.StateDown,
+		"Response time": "250ms",
+		"Down for":      "5m0s",
+	} {
+		if got := fieldValue(attachment, title); got != want {
+			t.Errorf("expected field %s to be %q, got %q", title, want, got)
+		}
+	}
+}
+
+func TestWebhookNotifier_ResolvedReferencesAlert(t *testing.T) {
+	url, requests := receive(t, http.StatusOK, "ok")
+
+	if err := NewWebhookNotifier(url).Notify(context.Background(), newNotification(domain.NotificationResolved)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	attachment := (<-requests).msg.Attachments[0]
+	if attachment.Color != colorResolved || attachment.Title != "Resolved: Example: SERVER_ERROR" || attachment.Footer != "alert alert-1" {
+		t.Errorf("unexpected attachment %+v", attachment)
+	}
+
+	if !strings.Contains(attachment.Text, "Opened 2024-05-01T12:00:00Z") || !strings.Contains(attachment.Text, "after 1h30m0s") {
+		t.Errorf("expected the resolve message to refer to the opened alert, got %q", attachment.Text)
+	}
+
+	if got := fieldValue(attachment, "Was down for"); got != "1h30m0s" {
+		t.Errorf("expected the downtime, got %q", got)
+	}
+
+	if attachment.TitleLink != "" {
+		t.Errorf("expected no link without an API URL, got %q", attachment.TitleLink)
+	}
+}
+
+func TestWebhookNotifier_ErrorStatus(t *testing.T) {
+	url, _ := receive(t, http.StatusNotFound, "no_team")
+
+	err := NewWebhookNotifier(url).Notify(context.Background(), newNotification(domain.NotificationOpened))
+	if err == nil || !strings.Contains(err.Error(), "no_team") {
+		t.Errorf("expected the receiver's error, got %v", err)
+	}
+}
+
+func TestSlackNotifier_UpdatesMessageOnResolve(t *testing.T) {
+	url, requests := receive(t, http.StatusOK, `{"ok": true, "ts": "1714564800.000100"}`)
+
+	notifier := NewSlackNotifier("xoxb-secret", "#alerts")
+	notifier.slackAPI = url
+
+	if err := notifier.Notify(context.Background(), newNotification(domain.NotificationOpened)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	req := <-requests
+	if req.path != "/chat.postMessage" || req.msg.Channel != "#alerts" || req.header.Get("Authorization") != "Bearer xoxb-secret" {
+		t.Errorf("expected the alert to be posted to #alerts, got %s %+v", req.path, req.msg)
+	}
+
+	if err := notifier.Notify(context.Background(), newNotification(domain.NotificationResolved)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	update := <-requests
+	if update.path != "/chat.update" || update.msg.TS != "1714564800.000100" || update.msg.Attachments[0].Color != colorResolved {
+		t.Errorf("expected the original message to be updated, got %s %+v", update.path, update.msg)
+	}
+
+	reply := <-requests
+	if reply.path != "/chat.postMessage" || reply.msg.ThreadTS != "1714564800.000100" {
+		t.Errorf("expected a reply in the alert's thread, got %s %+v", reply.path, reply.msg)
+	}
+}
+
+func TestSlackNotifier_RemindsInThread(t *testing.T) {
+	url, requests := receive(t, http.StatusOK, `{"ok": true, "ts": "1714564800.000100"}`)
+
+	notifier := NewSlackNotifier("xoxb-secret", "#alerts")
+	notifier.slackAPI = url
+
+	if err := notifier.Notify(context.Background(), newNotification(domain.NotificationOpened)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	<-requests
+
+	if err := notifier.Notify(context.Background(), newNotification(domain.NotificationRepeated)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	reminder := <-requests
+	if reminder.path != "/chat.postMessage" || reminder.msg.ThreadTS != "1714564800.000100" {
+		t.Errorf("expected the reminder in the alert's thread, got %s %+v", reminder.path, reminder.msg)
+	}
+
+	if attachment := reminder.msg.Attachments[0]; attachment.Title != "Reminder: Example: SERVER_ERROR" || !strings.Contains(attachment.Text, "Still open since 2024-05-01T12:00:00Z") {
+		t.Errorf("unexpected reminder %+v", attachment)
+	}
+}
+
+func TestNewMessage_Escalated(t *testing.T) {
+	n := newNotification(domain.NotificationEscalated)
+	n.Alert.EscalationStep = 2
+
+	attachment := NewWebhookNotifier("https://hooks.example.com").NewMessage(n).Attachments[0]
+	if attachment.Title != "Escalated: Example: SERVER_ERROR" || !strings.Contains(attachment.Text, "escalation step 2") {
+		t.Errorf("unexpected escalation %+v", attachment)
+	}
+}
+
+func TestSlackNotifier_APIError(t *testing.T) {
+	url, _ := receive(t, http.StatusOK, `{"ok": false, "error": "channel_not_found"}`)
+
+	notifier := NewSlackNotifier("xoxb-secret", "#nowhere")
+	notifier.slackAPI = url
+
+	err := notifier.Notify(context.Background(), newNotification(domain.NotificationOpened))
+	if err == nil || !strings.Contains(err.Error(), "channel_not_found") {
+		t.Errorf("expected the Slack error, got %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+package clock
+
+import "time"
+
+type SystemClock struct{}
+
+func (c *SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (c *SystemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func NewSystemClock() *SystemClock {
+	return &SystemClock{}
+}
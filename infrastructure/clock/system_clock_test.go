@@ -0,0 +1,28 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSystemClockNow(t *testing.T) {
+	c := NewSystemClock()
+
+	before := time.Now()
+	now := c.Now()
+	after := time.Now()
+
+	if now.Before(before) || now.After(after) {
+		t.Errorf("Now should be between %v and %v, got %v", before, after, now)
+	}
+}
+
+func TestSystemClockAfter(t *testing.T) {
+	c := NewSystemClock()
+
+	select {
+	case <-c.After(10 * time.Millisecond):
+	case <-time.After(time.Second):
+		t.Fatal("expected After to fire within 1s")
+	}
+}
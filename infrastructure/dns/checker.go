@@ -0,0 +1,190 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+const defaultDNSPort = "53"
+
+type Option func(*Checker)
+
+// WithNameserver sets the server queried for targets that don't name one in
+// their URL. Without it the system resolver is used.
+func WithNameserver(addr string) Option {
+	return func(c *Checker) {
+		c.nameserver = withDefaultPort(addr)
+	}
+}
+
+// Checker resolves dns://[nameserver]/name?type=A&expect=... targets. The
+// type is one of A (default), AAAA, CNAME, MX and TXT. Every expect value
+// must be among the answers; MX answers are matched by host. The response
+// time is the resolution time.
+type Checker struct {
+	timeout    time.Duration
+	nameserver string
+}
+
+func NewChecker(timeout time.Duration, opts ...Option) *Checker {
+	c := &Checker{timeout: timeout}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+func (c *Checker) Check(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+	u, err := url.Parse(target.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimPrefix(u.Path, "/")
+	if name == "" {
+		return nil, fmt.Errorf("dns target %s has no name to resolve", target.URL)
+	}
+
+	query := u.Query()
+	recordType := strings.ToUpper(query.Get("type"))
+	if recordType == "" {
+		recordType = "A"
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	resolver := c.resolver(u.Host)
+
+	start := time.Now()
+	answers, err := lookup(ctx, resolver, recordType, name)
+	res := &domain.CheckResponse{ResponseTime: time.Since(start)}
+
+	var dnsErr *net.DNSError
+	switch {
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		res.Error = fmt.Errorf("no %s records for %s: %w", recordType, name, err)
+		return res, nil
+	case err != nil:
+		return nil, err
+	case len(answers) == 0:
+		res.Error = fmt.Errorf("no %s records for %s", recordType, name)
+		return res, nil
+	}
+
+	for _, want := range query["expect"] {
+		if !slices.Contains(answers, normalize(recordType, want)) {
+			res.Error = fmt.Errorf("expected %s record %q for %s, got %v", recordType, want, name, answers)
+			break
+		}
+	}
+
+	return res, nil
+}
+
+func (c *Checker) resolver(host string) *net.Resolver {
+	server := c.nameserver
+	if host != "" {
+		server = withDefaultPort(host)
+	}
+
+	if server == "" {
+		return net.DefaultResolver
+	}
+
+	var dialer net.Dialer
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, server)
+		},
+	}
+}
+
+// lookup returns the answers in the form expect values are normalized to.
+func lookup(ctx context.Context, resolver *net.Resolver, recordType, name string) ([]string, error) {
+	var answers []string
+
+	switch recordType {
+	case "A", "AAAA":
+		network := "ip4"
+		if recordType == "AAAA" {
+			network = "ip6"
+		}
+
+		ips, err := resolver.LookupIP(ctx, network, name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ip := range ips {
+			answers = append(answers, ip.String())
+		}
+
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		answers = append(answers, normalize(recordType, cname))
+
+	case "MX":
+		records, err := resolver.LookupMX(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, mx := range records {
+			answers = append(answers, normalize(recordType, mx.Host))
+		}
+
+	case "TXT":
+		records, err := resolver.LookupTXT(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		answers = records
+
+	default:
+		return nil, fmt.Errorf("unsupported DNS record type %q", recordType)
+	}
+
+	return answers, nil
+}
+
+func normalize(recordType, value string) string {
+	switch recordType {
+	case "A", "AAAA":
+		if ip := net.ParseIP(value); ip != nil {
+			return ip.String()
+		}
+	case "CNAME", "MX":
+		return strings.ToLower(strings.TrimSuffix(value, "."))
+	}
+
+	return value
+}
+
+func withDefaultPort(addr string) string {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr
+	}
+
+	return net.JoinHostPort(strings.Trim(addr, "[]"), defaultDNSPort)
+}
@@ -0,0 +1,193 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// zone maps "name. TYPE" to the records served for it.
+type zone map[string][]dnsmessage.ResourceBody
+
+// serveDNS answers UDP queries on localhost from z until the test ends.
+// Names missing from z get NXDOMAIN.
+func serveDNS(t *testing.T, z zone) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			if reply := answer(z, buf[:n]); reply != nil {
+				conn.WriteTo(reply, addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func answer(z zone, query []byte) []byte {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(query); err != nil || len(msg.Questions) != 1 {
+		return nil
+	}
+
+	q := msg.Questions[0]
+	reply := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: msg.ID, Response: true, Authoritative: true, RecursionAvailable: true},
+		Questions: msg.Questions,
+	}
+
+	known := false
+	for key, records := range z {
+		name, _, _ := strings.Cut(key, " ")
+		if !strings.EqualFold(name, q.Name.String()) {
+			continue
+		}
+		known = true
+
+		if key != name+" "+strings.TrimPrefix(q.Type.String(), "Type") {
+			continue
+		}
+
+		for _, body := range records {
+			reply.Answers = append(reply.Answers, dnsmessage.Resource{
+				Header: dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: dnsmessage.ClassINET, TTL: 60},
+				Body:   body,
+			})
+		}
+	}
+
+	if !known {
+		reply.RCode = dnsmessage.RCodeNameError
+	}
+
+	packed, err := reply.Pack()
+	if err != nil {
+		return nil
+	}
+
+	return packed
+}
+
+func mustName(s string) dnsmessage.Name {
+	return dnsmessage.MustNewName(s)
+}
+
+func testZone() zone {
+	return zone{
+		"example.test. A": {
+			&dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}},
+			&dnsmessage.AResource{A: [4]byte{192, 0, 2, 2}},
+		},
+		"example.test. AAAA": {
+			&dnsmessage.AAAAResource{AAAA: [16]byte{0x20, 0x01, 0x0d, 0xb8, 15: 1}},
+		},
+		"www.example.test. CNAME": {
+			&dnsmessage.CNAMEResource{CNAME: mustName("example.test.")},
+		},
+		"example.test. MX": {
+			&dnsmessage.MXResource{Pref: 10, MX: mustName("mail.example.test.")},
+		},
+		"example.test. TXT": {
+			&dnsmessage.TXTResource{TXT: []string{"v=spf1 -all"}},
+		},
+	}
+}
+
+func newTarget(url string) *domain.Target {
+	return domain.NewTarget("target-1", url, "", time.Minute)
+}
+
+func TestChecker_Records(t *testing.T) {
+	server := serveDNS(t, testZone())
+	checker := NewChecker(time.Second)
+
+	for _, path := range []string{
+		"/example.test?expect=192.0.2.1&expect=192.0.2.2",
+		"/example.test?type=AAAA&expect=2001:db8::1",
+		"/www.example.test?type=CNAME&expect=example.test",
+		"/example.test?type=MX&expect=mail.example.test.",
+		"/example.test?type=TXT&expect=v%3Dspf1+-all",
+	} {
+		resp, err := checker.Check(context.Background(), newTarget("dns://"+server+path))
+
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", path, err)
+			continue
+		}
+
+		if resp.Error != nil {
+			t.Errorf("%s: expected matching answers, got %v", path, resp.Error)
+		}
+
+		if resp.ResponseTime == 0 {
+			t.Errorf("%s: resolution time should be measured", path)
+		}
+	}
+}
+
+func TestChecker_UnexpectedAnswer(t *testing.T) {
+	server := serveDNS(t, testZone())
+
+	resp, err := NewChecker(time.Second).Check(context.Background(), newTarget("dns://"+server+"/example.test?expect=198.51.100.7"))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if resp.Error == nil || !strings.Contains(resp.Error.Error(), "198.51.100.7") {
+		t.Errorf("expected the missing answer to be reported, got %v", resp.Error)
+	}
+}
+
+func TestChecker_NotFound(t *testing.T) {
+	server := serveDNS(t, testZone())
+
+	resp, err := NewChecker(time.Second).Check(context.Background(), newTarget("dns://"+server+"/missing.example.test"))
+
+	if err != nil {
+		t.Fatalf("expected NXDOMAIN to be a failed check, got error %v", err)
+	}
+
+	if resp.Error == nil {
+		t.Error("expected NXDOMAIN to fail the check")
+	}
+}
+
+func TestChecker_DefaultNameserver(t *testing.T) {
+	server := serveDNS(t, testZone())
+
+	resp, err := NewChecker(time.Second, WithNameserver(server)).Check(context.Background(), newTarget("dns:///example.test?type=MX"))
+
+	if err != nil || resp.Error != nil {
+		t.Fatalf("expected the default nameserver to answer, got %v / %v", err, resp.Error)
+	}
+}
+
+func TestChecker_Invalid(t *testing.T) {
+	checker := NewChecker(time.Second, WithNameserver("127.0.0.1:1"))
+
+	for _, url := range []string{"dns://127.0.0.1", "dns:///example.test?type=SRV"} {
+		if _, err := checker.Check(context.Background(), newTarget(url)); err == nil {
+			t.Errorf("%s: expected an error", url)
+		}
+	}
+}
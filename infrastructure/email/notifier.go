@@ -0,0 +1,364 @@
+// Package email sends alert notifications over SMTP.
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+const defaultTimeout = 30 * time.Second
+
+const (
+	DefaultSubjectTemplate = `[{{if eq .Event "resolved"}}RESOLVED{{else if eq .Event "repeated"}}REMINDER{{else if eq .Event "escalated"}}ESCALATED{{else}}ALERT{{end}}] {{.Name}}: {{.Alert.Type}}`
+
+	DefaultTextTemplate = `{{.Alert.Message}}
+
+Target:   {{.Name}} ({{.Target.URL}})
+State:    {{.Target.State}}
+{{- if .ResponseTime}}
+Response: {{.ResponseTime}}
+{{- end}}
+Opened:   {{.Alert.CreatedAt.UTC.Format "2006-01-02 15:04:05 MST"}}
+{{- if .Alert.ResolvedAt}}
+Resolved: {{.Alert.ResolvedAt.UTC.Format "2006-01-02 15:04:05 MST"}} (after {{.OpenFor}})
+{{- end}}
+Alert ID: {{.Alert.ID}}
+`
+
+	DefaultHTMLTemplate = `<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif">
+<h2 style="color: {{if eq .Event "resolved"}}#2eb886{{else}}#d00000{{end}}">{{if eq .Event "resolved"}}Resolved: {{else if eq .Event "repeated"}}Reminder: {{else if eq .Event "escalated"}}Escalated: {{end}}{{.Name}}: {{.Alert.Type}}</h2>
+<p>{{.Alert.Message}}</p>
+<table>
+<tr><th align="left">Target</th><td><a href="{{.Target.URL}}">{{.Name}}</a></td></tr>
+<tr><th align="left">State</th><td>{{.Target.State}}</td></tr>
+{{- if .ResponseTime}}
+<tr><th align="left">Response</th><td>{{.ResponseTime}}</td></tr>
+{{- end}}
+<tr><th align="left">Opened</th><td>{{.Alert.CreatedAt.UTC.Format "2006-01-02 15:04:05 MST"}}</td></tr>
+{{- if .Alert.ResolvedAt}}
+<tr><th align="left">Resolved</th><td>{{.Alert.ResolvedAt.UTC.Format "2006-01-02 15:04:05 MST"}} (after {{.OpenFor}})</td></tr>
+{{- end}}
+</table>
+<p style="color: #888">Alert {{.Alert.ID}}</p>
+</body>
+</html>
+`
+)
+
+type Option func(*Notifier)
+
+// WithAuth logs in with PLAIN auth, which net/smtp only allows over TLS or
+// to localhost.
+func WithAuth(username, password string) Option {
+	return func(n *Notifier) {
+		n.username = username
+		n.password = password
+	}
+}
+
+// WithRecipients sets who gets alerts of targets without AlertEmails of their
+// own.
+func WithRecipients(to ...string) Option {
+	return func(n *Notifier) {
+		n.recipients = to
+	}
+}
+
+// WithTemplates replaces the default subject, plain-text and HTML templates;
+// empty ones keep the default. They are executed with TemplateData.
+func WithTemplates(subject, text, html string) Option {
+	return func(n *Notifier) {
+		n.templates = [3]string{subject, text, html}
+	}
+}
+
+// WithTLSConfig is used for STARTTLS, with the server's host as ServerName
+// unless config sets one.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(n *Notifier) {
+		n.tlsConfig = config
+	}
+}
+
+// WithPlaintext allows sending to a server that doesn't offer STARTTLS, such
+// as a local relay. Otherwise such a server is refused.
+func WithPlaintext() Option {
+	return func(n *Notifier) {
+		n.plaintext = true
+	}
+}
+
+// Notifier emails every notification as a multipart message with a
+// plain-text and an HTML part. Resolve messages, reminders and escalations
+// reply to the one that announced the alert, so mail clients thread them.
+type Notifier struct {
+	addr       string
+	host       string
+	from       string
+	sender     string
+	recipients []string
+	username   string
+	password   string
+	tlsConfig  *tls.Config
+	plaintext  bool
+	now        func() time.Time
+
+	templates [3]string
+	subject   *template.Template
+	text      *template.Template
+	html      *htmltemplate.Template
+}
+
+// NewNotifier sends through the SMTP server at addr (host:port) as from, e.g.
+// "Uptime <uptime@example.com>". It fails when a template doesn't parse.
+func NewNotifier(addr, from string, opts ...Option) (*Notifier, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("smtp address %q: %w", addr, err)
+	}
+
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("sender %q: %w", from, err)
+	}
+
+	n := &Notifier{addr: addr, host: host, from: from, sender: sender.Address, now: time.Now}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	subject, text, html := n.templates[0], n.templates[1], n.templates[2]
+	if subject == "" {
+		subject = DefaultSubjectTemplate
+	}
+	if text == "" {
+		text = DefaultTextTemplate
+	}
+	if html == "" {
+		html = DefaultHTMLTemplate
+	}
+
+	if n.subject, err = template.New("subject").Parse(subject); err != nil {
+		return nil, fmt.Errorf("parsing email subject template: %w", err)
+	}
+	if n.text, err = template.New("text").Parse(text); err != nil {
+		return nil, fmt.Errorf("parsing email text template: %w", err)
+	}
+	if n.html, err = htmltemplate.New("html").Parse(html); err != nil {
+		return nil, fmt.Errorf("parsing email HTML template: %w", err)
+	}
+
+	return n, nil
+}
+
+// TemplateData is what the templates are executed with.
+type TemplateData struct {
+	domain.Notification
+
+	// Name is the target's name, or its URL when it has none.
+	Name string
+
+	// OpenFor is how long a resolved alert was open.
+	OpenFor time.Duration
+}
+
+func newTemplateData(n domain.Notification) TemplateData {
+	data := TemplateData{Notification: n, Name: n.Target.Name}
+
+	if data.Name == "" {
+		data.Name = n.Target.URL
+	}
+
+	if n.Alert.ResolvedAt != nil {
+		data.OpenFor = n.Alert.ResolvedAt.Sub(n.Alert.CreatedAt).Round(time.Second)
+	}
+
+	return data
+}
+
+// Notify emails the target's AlertEmails, or the default recipients when it
+// has none. Without any recipient there is nothing to do.
+func (n *Notifier) Notify(ctx context.Context, notification domain.Notification) error {
+	to := notification.Target.AlertEmails
+	if len(to) == 0 {
+		to = n.recipients
+	}
+
+	if len(to) == 0 {
+		return nil
+	}
+
+	msg, err := n.Compose(notification, to)
+	if err != nil {
+		return err
+	}
+
+	return n.send(ctx, to, msg)
+}
+
+// Compose renders the message for a notification, headers included.
+func (n *Notifier) Compose(notification domain.Notification, to []string) ([]byte, error) {
+	data := newTemplateData(notification)
+
+	var subject, text, html bytes.Buffer
+	if err := n.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("rendering email subject: %w", err)
+	}
+	if err := n.text.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("rendering email text: %w", err)
+	}
+	if err := n.html.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("rendering email HTML: %w", err)
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		qp := quotedprintable.NewWriter(w)
+		qp.Write(part.content)
+		qp.Close()
+	}
+	parts.Close()
+
+	var msg bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&msg, "%s: %s\r\n", name, value)
+	}
+
+	header("From", n.from)
+	header("To", strings.Join(to, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", strings.TrimSpace(subject.String())))
+	header("Date", n.now().Format(time.RFC1123Z))
+
+	// Reminders and escalations repeat, so their IDs carry when or at which
+	// step they were sent.
+	event := notification.Event
+	switch event {
+	case domain.NotificationRepeated:
+		event = fmt.Sprintf("%s.%d", event, notification.Alert.NotifiedAt.Unix())
+	case domain.NotificationEscalated:
+		event = fmt.Sprintf("%s.%d", event, notification.Alert.EscalationStep)
+	}
+
+	opened := n.messageID(notification.Alert.ID, domain.NotificationOpened)
+	header("Message-ID", n.messageID(notification.Alert.ID, event))
+	if event != domain.NotificationOpened {
+		header("In-Reply-To", opened)
+		header("References", opened)
+	}
+
+	header("MIME-Version", "1.0")
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+// messageID is derived from the alert so that later messages can refer to the
+// one that opened it without storing anything.
+func (n *Notifier) messageID(alertID, event string) string {
+	_, domainPart, _ := strings.Cut(n.sender, "@")
+	return fmt.Sprintf("<%s.%s@%s>", alertID, event, domainPart)
+}
+
+func (n *Notifier) send(ctx context.Context, to []string, msg []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = n.now().Add(defaultTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		config := &tls.Config{ServerName: n.host}
+		if n.tlsConfig != nil {
+			config = n.tlsConfig.Clone()
+			if config.ServerName == "" {
+				config.ServerName = n.host
+			}
+		}
+
+		if err := c.StartTLS(config); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	} else if !n.plaintext {
+		return fmt.Errorf("smtp server %s does not offer STARTTLS", n.addr)
+	}
+
+	if n.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", n.username, n.password, n.host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	if err := c.Mail(n.sender); err != nil {
+		return err
+	}
+
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("recipient %s: %w", rcpt, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
@@ -0,0 +1,426 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+// ----- > Fake SMTP server < -----
+
+type captured struct {
+	tls  bool
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// fakeSMTP captures the messages it receives. It offers STARTTLS when it has
+// a certificate and AUTH PLAIN once the connection is encrypted.
+type fakeSMTP struct {
+	t        *testing.T
+	ln       net.Listener
+	tls      *tls.Config
+	messages chan captured
+}
+
+func newFakeSMTP(t *testing.T, offerTLS bool) (*fakeSMTP, *tls.Config) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	s := &fakeSMTP{t: t, ln: ln, messages: make(chan captured, 4)}
+
+	// Borrow httptest's certificate for 127.0.0.1.
+	var clientConfig *tls.Config
+	if offerTLS {
+		srv := httptest.NewTLSServer(http.NotFoundHandler())
+		t.Cleanup(srv.Close)
+
+		roots := x509.NewCertPool()
+		roots.AddCert(srv.Certificate())
+
+		s.tls = &tls.Config{Certificates: srv.TLS.Certificates}
+		clientConfig = &tls.Config{RootCAs: roots, ServerName: "127.0.0.1"}
+	}
+
+	go s.serve()
+	return s, clientConfig
+}
+
+func (s *fakeSMTP) addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *fakeSMTP) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+
+	var (
+		msg       captured
+		encrypted bool
+	)
+
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 fake ESMTP")
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			lines := []string{"fake"}
+			if s.tls != nil && !encrypted {
+				lines = append(lines, "STARTTLS")
+			}
+			if encrypted || s.tls == nil {
+				lines = append(lines, "AUTH PLAIN")
+			}
+			for i, l := range lines {
+				sep := "-"
+				if i == len(lines)-1 {
+					sep = " "
+				}
+				tp.PrintfLine("250%s%s", sep, l)
+			}
+
+		case "STARTTLS":
+			tp.PrintfLine("220 go ahead")
+
+			tlsConn := tls.Server(conn, s.tls)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+
+			conn, encrypted, msg.tls = tlsConn, true, true
+			tp = textproto.NewConn(conn)
+
+		case "AUTH":
+			_, initial, _ := strings.Cut(arg, " ")
+			decoded, _ := base64.StdEncoding.DecodeString(initial)
+			msg.auth = string(decoded)
+			tp.PrintfLine("235 authenticated")
+
+		case "MAIL":
+			msg.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			tp.PrintfLine("250 ok")
+
+		case "RCPT":
+			msg.to = append(msg.to, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			tp.PrintfLine("250 ok")
+
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+
+			data, err := io.ReadAll(tp.DotReader())
+			if err != nil {
+				return
+			}
+
+			msg.data = string(data)
+			s.messages <- msg
+			tp.PrintfLine("250 queued")
+
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+
+		default:
+			tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+// ----- > Helpers < -----
+
+var openedAt = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func newNotification(event string) domain.Notification {
+	target := domain.NewTarget("target-1", "https://example.com", "Example <API>", time.Minute)
+	target.State = domain.StateDown
+
+	alert := domain.NewAlert("alert-1", target.ID, domain.StatusServerError, "Target https://example.com is SERVER_ERROR")
+	alert.CreatedAt = openedAt
+
+	if event == domain.NotificationResolved {
+		alert.Resolve()
+		resolvedAt := openedAt.Add(10 * time.Minute)
+		alert.ResolvedAt = &resolvedAt
+		target.State = domain.StateUp
+	}
+
+	return domain.Notification{Event: event, Alert: *alert, Target: *target, ResponseTime: 250 * time.Millisecond}
+}
+
+// parts reads a captured message and returns its headers and its decoded
+// parts by content type.
+func parts(t *testing.T, data string) (mail.Header, map[string]string) {
+	t.Helper()
+
+	msg, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("reading message: %v", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("expected multipart/alternative, got %q (%v)", msg.Header.Get("Content-Type"), err)
+	}
+
+	found := make(map[string]string)
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := r.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading part: %v", err)
+		}
+
+		content, _ := io.ReadAll(quotedprintable.NewReader(part))
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		found[contentType] = string(content)
+	}
+
+	return msg.Header, found
+}
+
+// ----- > Tests < -----
+
+func TestNotifier_SendsMultipartOverSTARTTLS(t *testing.T) {
+	server, clientTLS := newFakeSMTP(t, true)
+
+	notifier, err := NewNotifier(server.addr(), "Uptime <uptime@example.com>",
+		WithAuth("uptime", "secret"),
+		WithRecipients("ops@example.com"),
+		WithTLSConfig(clientTLS),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := notifier.Notify(context.Background(), newNotification(domain.NotificationOpened)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	msg := <-server.messages
+	if !msg.tls {
+		t.Error("expected the message to be sent after STARTTLS")
+	}
+
+	if msg.auth != "\x00uptime\x00secret" {
+		t.Errorf("expected PLAIN auth as uptime, got %q", msg.auth)
+	}
+
+	if msg.from != "uptime@example.com" || len(msg.to) != 1 || msg.to[0] != "ops@example.com" {
+		t.Errorf("expected mail from uptime@example.com to ops@example.com, got %s to %v", msg.from, msg.to)
+	}
+
+	header, found := parts(t, msg.data)
+
+	subject, _ := new(mime.WordDecoder).DecodeHeader(header.Get("Subject"))
+	if subject != "[ALERT] Example <API>: SERVER_ERROR" {
+		t.Errorf("unexpected subject %q", subject)
+	}
+
+	if header.Get("Message-Id") != "<alert-1.opened@example.com>" {
+		t.Errorf("unexpected Message-ID %q", header.Get("Message-Id"))
+	}
+
+	if text := found["text/plain"]; !strings.Contains(text, "Target https://example.com is SERVER_ERROR") || !strings.Contains(text, "Response: 250ms") {
+		t.Errorf("unexpected text part %q", text)
+	}
+
+	if html := found["text/html"]; !strings.Contains(html, "Example &lt;API&gt;") || !strings.Contains(html, `href="https://example.com"`) {
+		t.Errorf("expected an escaped HTML part, got %q", html)
+	}
+}
+
+func TestNotifier_TLSConfigWithoutServerName(t *testing.T) {
+	server, clientTLS := newFakeSMTP(t, true)
+	clientTLS.ServerName = ""
+
+	notifier, _ := NewNotifier(server.addr(), "uptime@example.com", WithRecipients("ops@example.com"), WithTLSConfig(clientTLS))
+
+	if err := notifier.Notify(context.Background(), newNotification(domain.NotificationOpened)); err != nil {
+		t.Fatalf("expected the certificate to be verified against the server host, got %v", err)
+	}
+
+	if msg := <-server.messages; !msg.tls {
+		t.Error("expected the message to be sent after STARTTLS")
+	}
+}
+
+func TestNotifier_ResolveRepliesToAlert(t *testing.T) {
+	server, clientTLS := newFakeSMTP(t, true)
+
+	notifier, _ := NewNotifier(server.addr(), "uptime@example.com", WithRecipients("ops@example.com"), WithTLSConfig(clientTLS))
+
+	if err := notifier.Notify(context.Background(), newNotification(domain.NotificationResolved)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	header, found := parts(t, (<-server.messages).data)
+
+	if header.Get("In-Reply-To") != "<alert-1.opened@example.com>" {
+		t.Errorf("expected a reply to the alert's message, got %q", header.Get("In-Reply-To"))
+	}
+
+	if subject := header.Get("Subject"); !strings.HasPrefix(subject, "[RESOLVED]") {
+		t.Errorf("unexpected subject %q", subject)
+	}
+
+	if text := found["text/plain"]; !strings.Contains(text, "(after 10m0s)") {
+		t.Errorf("expected how long the alert was open, got %q", text)
+	}
+}
+
+func TestNotifier_ReminderRepliesToAlert(t *testing.T) {
+	server, clientTLS := newFakeSMTP(t, true)
+
+	notifier, _ := NewNotifier(server.addr(), "uptime@example.com", WithRecipients("ops@example.com"), WithTLSConfig(clientTLS))
+
+	n := newNotification(domain.NotificationRepeated)
+	n.Alert.NotifiedAt = openedAt.Add(time.Hour)
+
+	if err := notifier.Notify(context.Background(), n); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	header, _ := parts(t, (<-server.messages).data)
+
+	if header.Get("Message-Id") != "<alert-1.repeated.1714568400@example.com>" || header.Get("In-Reply-To") != "<alert-1.opened@example.com>" {
+		t.Errorf("expected a reply to the alert's message, got %q in reply to %q", header.Get("Message-Id"), header.Get("In-Reply-To"))
+	}
+
+	if subject := header.Get("Subject"); !strings.HasPrefix(subject, "[REMINDER]") {
+		t.Errorf("unexpected subject %q", subject)
+	}
+}
+
+func TestNotifier_EscalationRepliesToAlert(t *testing.T) {
+	server, clientTLS := newFakeSMTP(t, true)
+
+	notifier, _ := NewNotifier(server.addr(), "uptime@example.com", WithRecipients("ops@example.com"), WithTLSConfig(clientTLS))
+
+	n := newNotification(domain.NotificationEscalated)
+	n.Alert.EscalationStep = 2
+
+	if err := notifier.Notify(context.Background(), n); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	header, _ := parts(t, (<-server.messages).data)
+
+	if header.Get("Message-Id") != "<alert-1.escalated.2@example.com>" || header.Get("In-Reply-To") != "<alert-1.opened@example.com>" {
+		t.Errorf("expected a reply to the alert's message, got %q in reply to %q", header.Get("Message-Id"), header.Get("In-Reply-To"))
+	}
+
+	if subject := header.Get("Subject"); !strings.HasPrefix(subject, "[ESCALATED]") {
+		t.Errorf("unexpected subject %q", subject)
+	}
+}
+
+func TestNotifier_TargetRecipientsAndTemplates(t *testing.T) {
+	server, clientTLS := newFakeSMTP(t, true)
+
+	notifier, err := NewNotifier(server.addr(), "uptime@example.com",
+		WithRecipients("ops@example.com"),
+		WithTLSConfig(clientTLS),
+		WithTemplates("{{.Name}} is {{.Target.State}}", "{{.Alert.Message}}", ""),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	n := newNotification(domain.NotificationOpened)
+	n.Target.AlertEmails = []string{"api-team@example.com", "cto@example.com"}
+
+	if err := notifier.Notify(context.Background(), n); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	msg := <-server.messages
+	if strings.Join(msg.to, ",") != "api-team@example.com,cto@example.com" {
+		t.Errorf("expected the target's recipients, got %v", msg.to)
+	}
+
+	header, found := parts(t, msg.data)
+	if header.Get("Subject") != "Example <API> is DOWN" || found["text/plain"] != n.Alert.Message {
+		t.Errorf("expected the custom templates, got subject %q and text %q", header.Get("Subject"), found["text/plain"])
+	}
+
+	if !strings.Contains(found["text/html"], "<table>") {
+		t.Error("expected the default HTML template")
+	}
+}
+
+func TestNotifier_RefusesServerWithoutSTARTTLS(t *testing.T) {
+	server, _ := newFakeSMTP(t, false)
+
+	notifier, _ := NewNotifier(server.addr(), "uptime@example.com", WithRecipients("ops@example.com"))
+
+	err := notifier.Notify(context.Background(), newNotification(domain.NotificationOpened))
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("expected STARTTLS to be required, got %v", err)
+	}
+
+	plain, _ := NewNotifier(server.addr(), "uptime@example.com", WithRecipients("ops@example.com"), WithPlaintext())
+	if err := plain.Notify(context.Background(), newNotification(domain.NotificationOpened)); err != nil {
+		t.Fatalf("expected plaintext delivery to be allowed, got %v", err)
+	}
+
+	if msg := <-server.messages; msg.tls {
+		t.Error("expected a plaintext session")
+	}
+}
+
+func TestNewNotifier_Invalid(t *testing.T) {
+	if _, err := NewNotifier("localhost", "uptime@example.com"); err == nil {
+		t.Error("expected an address without port to be rejected")
+	}
+
+	if _, err := NewNotifier("localhost:25", "uptime"); err == nil {
+		t.Error("expected an invalid sender to be rejected")
+	}
+
+	if _, err := NewNotifier("localhost:25", "uptime@example.com", WithTemplates("{{.Nope", "", "")); err == nil {
+		t.Error("expected a broken template to be rejected")
+	}
+}
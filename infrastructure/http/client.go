@@ -2,44 +2,206 @@ package http
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
 	"time"
+
 	"github.com/karoljaro/go-uptime-monitor/domain"
 )
 
+// maxBodySize caps how much of a body is read to time the transfer and
+// evaluate assertions.
+const maxBodySize = 4 << 20
+
 type DefaultHTTPClient struct {
 	client  *http.Client
 	timeout time.Duration
 }
 
+// NewDefaultHTTPClient opens a new connection for every check, so each one
+// times DNS, connect and TLS rather than reusing a pooled connection.
 func NewDefaultHTTPClient(timeout time.Duration) *DefaultHTTPClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = true
+
 	return &DefaultHTTPClient{
 		client: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: transport,
 		},
 		timeout: timeout,
 	}
 }
 
-func (c *DefaultHTTPClient) Check(ctx context.Context, url string) (*domain.HTTPResponse, error) {
-	start := time.Now()
+// Check implements domain.Checker for http and https targets, sending the
+// request described by the target's HTTP check spec. A certificate that fails
+// verification is reported on the response together with the chain it
+// presented rather than as a connection error. The response carries the
+// timing breakdown of the request.
+func (c *DefaultHTTPClient) Check(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+	spec := target.EffectiveHTTPCheck()
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	trace := &timingTrace{}
+	ctx = httptrace.WithClientTrace(ctx, trace.clientTrace())
+
+	req, err := newRequest(ctx, target.URL, spec)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := c.client.Do(req)
+	client := c.client
+	if !spec.FollowRedirects {
+		noRedirects := *c.client
+		noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+		client = &noRedirects
+	}
+
+	start := time.Now()
+
+	res, err := client.Do(req)
+
+	var certErr *tls.CertificateVerificationError
+	if errors.As(err, &certErr) {
+		info := newTLSInfo(certErr.UnverifiedCertificates, failedHost(req, err))
+		info.VerifyError = certErr.Err.Error()
+
+		return &domain.CheckResponse{
+			ResponseTime: time.Since(start),
+			Error:        fmt.Errorf("TLS certificate verification failed: %w", certErr.Err),
+			TLS:          info,
+			Timings:      trace.finish(),
+		}, nil
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
 	defer res.Body.Close()
 
-	responseTime := time.Since(start)
-
-	return &domain.HTTPResponse{
+	resp := &domain.CheckResponse{
 		StatusCode: res.StatusCode,
-		ResponseTime: responseTime,
-	}, nil
-}
\ No newline at end of file
+	}
+
+	if res.TLS != nil {
+		// After redirects, res.TLS belongs to the last hop.
+		resp.TLS = newTLSInfo(res.TLS.PeerCertificates, res.Request.URL.Hostname())
+		resp.TLS.Verified = len(res.TLS.VerifiedChains) > 0
+	}
+
+	limit := bodyReadLimit(spec)
+	body, err := io.ReadAll(io.LimitReader(res.Body, limit))
+	resp.ResponseTime = time.Since(start)
+	resp.Timings = trace.finish()
+
+	if err != nil {
+		resp.Error = fmt.Errorf("reading response body: %w", err)
+		return resp, nil
+	}
+
+	switch {
+	case len(spec.Assertions) == 0:
+	case int64(len(body)) == limit:
+		resp.FailedAssertion = assertCutOff(spec, body)
+	default:
+		resp.FailedAssertion = spec.AssertBody(body)
+	}
+
+	return resp, nil
+}
+
+// bodyReadLimit reads one byte past maxBodySize or the largest max_size
+// assertion, so that a larger body is noticed without downloading all of it.
+func bodyReadLimit(spec *domain.HTTPCheck) int64 {
+	limit := int64(maxBodySize)
+
+	for _, a := range spec.Assertions {
+		if a.Type == domain.AssertionMaxSize && a.Size > limit {
+			limit = a.Size
+		}
+	}
+
+	return limit + 1
+}
+
+// assertCutOff fails a body that was cut off at the read limit: on its
+// max_size assertion, which any cut-off body exceeds, or else on the first
+// assertion, which can't be judged on part of the body.
+func assertCutOff(spec *domain.HTTPCheck, body []byte) *domain.AssertionError {
+	for _, a := range spec.Assertions {
+		var failed *domain.AssertionError
+		if a.Type == domain.AssertionMaxSize && errors.As(a.Evaluate(body), &failed) {
+			return failed
+		}
+	}
+
+	return &domain.AssertionError{
+		Assertion: spec.Assertions[0],
+		Reason:    fmt.Sprintf("body is larger than %d bytes and was cut off", len(body)-1),
+	}
+}
+
+func newRequest(ctx context.Context, url string, spec *domain.HTTPCheck) (*http.Request, error) {
+	var body io.Reader
+	if spec.Body != "" {
+		body = strings.NewReader(spec.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, spec.Method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range spec.Headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+
+		req.Header.Set(name, value)
+	}
+
+	return req, nil
+}
+
+// failedHost is the host of the request that err came from, which may be a
+// redirect of req.
+func failedHost(req *http.Request, err error) string {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+			return u.Hostname()
+		}
+	}
+
+	return req.URL.Hostname()
+}
+
+func newTLSInfo(certs []*x509.Certificate, host string) *domain.TLSInfo {
+	info := &domain.TLSInfo{}
+
+	for _, cert := range certs {
+		info.Chain = append(info.Chain, domain.Certificate{
+			Subject:   cert.Subject.String(),
+			Issuer:    cert.Issuer.String(),
+			NotBefore: cert.NotBefore,
+			NotAfter:  cert.NotAfter,
+			DNSNames:  cert.DNSNames,
+		})
+	}
+
+	if len(certs) > 0 {
+		info.HostnameMatches = certs[0].VerifyHostname(host) == nil
+	}
+
+	return info
+}
@@ -1,13 +1,30 @@
 package http
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
 )
 
+func newTarget(url string) *domain.Target {
+	return domain.NewTarget("target-1", url, "", time.Minute)
+}
+
 func TestDefaultHTTPClient_Check_Success(t *testing.T) {
 	// Mock Server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -17,7 +34,7 @@ func TestDefaultHTTPClient_Check_Success(t *testing.T) {
 	defer server.Close()
 
 	client := NewDefaultHTTPClient(5 * time.Second)
-	resp, err := client.Check(context.Background(), server.URL)
+	resp, err := client.Check(context.Background(), newTarget(server.URL))
 
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
@@ -37,7 +54,7 @@ func TestDefaultHTTPClient_Check_ServerError(t *testing.T) {
 	defer server.Close()
 
 	client := NewDefaultHTTPClient(5 * time.Second)
-	resp, err := client.Check(context.Background(), server.URL)
+	resp, err := client.Check(context.Background(), newTarget(server.URL))
 
 	if err != nil {
 		t.Errorf("expected no error (500 is valid response), got %v", err)
@@ -55,7 +72,7 @@ func TestDefaultHTTPClient_Check_Timeout(t *testing.T) {
 	defer server.Close()
 
 	client := NewDefaultHTTPClient(100 * time.Millisecond) // timeout 100ms
-	_, err := client.Check(context.Background(), server.URL)
+	_, err := client.Check(context.Background(), newTarget(server.URL))
 
 	if err == nil {
 		t.Error("expected timeout error, got nil")
@@ -64,7 +81,7 @@ func TestDefaultHTTPClient_Check_Timeout(t *testing.T) {
 
 func TestDefaultHTTPClient_Check_InvalidURL(t *testing.T) {
 	client := NewDefaultHTTPClient(5 * time.Second)
-	_, err := client.Check(context.Background(), "not-a-valid-url://[invalid]")
+	_, err := client.Check(context.Background(), newTarget("not-a-valid-url://[invalid]"))
 
 	if err == nil {
 		t.Error("expected error for invalid URL, got nil")
@@ -82,9 +99,324 @@ func TestDefaultHTTPClient_Check_ContextCancel(t *testing.T) {
 	cancel() // cancel context
 
 	client := NewDefaultHTTPClient(5 * time.Second)
-	_, err := client.Check(ctx, server.URL)
+	_, err := client.Check(ctx, newTarget(server.URL))
 
 	if err == nil {
 		t.Error("expected error from cancelled context, got nil")
 	}
 }
+
+// newTLSServer serves 200 over TLS with a self-signed certificate for
+// 127.0.0.1 valid within [notBefore, notAfter). The returned client trusts it.
+func newTLSServer(t *testing.T, notBefore, notAfter time.Time) (*httptest.Server, *DefaultHTTPClient) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "uptime test"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"uptime.test"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	client := NewDefaultHTTPClient(5 * time.Second)
+	client.client.Transport = server.Client().Transport
+
+	return server, client
+}
+
+func TestDefaultHTTPClient_Check_TLSChain(t *testing.T) {
+	notAfter := time.Now().Add(72 * time.Hour).Truncate(time.Second)
+	server, client := newTLSServer(t, time.Now().Add(-time.Hour), notAfter)
+
+	resp, err := client.Check(context.Background(), newTarget(server.URL))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if resp.TLS == nil || len(resp.TLS.Chain) != 1 {
+		t.Fatalf("expected the certificate chain to be captured, got %+v", resp.TLS)
+	}
+
+	if !resp.TLS.Verified || !resp.TLS.HostnameMatches {
+		t.Errorf("expected a verified chain matching the host, got %+v", resp.TLS)
+	}
+
+	if !resp.TLS.ExpiresAt().Equal(notAfter) {
+		t.Errorf("expected expiry %s, got %s", notAfter, resp.TLS.ExpiresAt())
+	}
+
+	if resp.Timings == nil || resp.Timings.TLS <= 0 {
+		t.Errorf("expected the TLS handshake to be timed, got %+v", resp.Timings)
+	}
+
+	leaf := resp.TLS.Chain[0]
+	if leaf.Issuer != "CN=uptime test" || len(leaf.DNSNames) != 1 || leaf.DNSNames[0] != "uptime.test" {
+		t.Errorf("expected issuer and SANs to be captured, got %+v", leaf)
+	}
+}
+
+func TestDefaultHTTPClient_Check_ExpiredCertificate(t *testing.T) {
+	server, client := newTLSServer(t, time.Now().Add(-48*time.Hour), time.Now().Add(-24*time.Hour))
+
+	resp, err := client.Check(context.Background(), newTarget(server.URL))
+
+	if err != nil {
+		t.Fatalf("expected the expired certificate on the response, got error %v", err)
+	}
+
+	if resp.Error == nil {
+		t.Error("expected verification failure to be reported")
+	}
+
+	if resp.TLS == nil || resp.TLS.Verified || resp.TLS.VerifyError == "" {
+		t.Fatalf("expected an unverified chain with its error, got %+v", resp.TLS)
+	}
+
+	if !resp.TLS.ExpiresAt().Before(time.Now()) {
+		t.Errorf("expected an expiry in the past, got %s", resp.TLS.ExpiresAt())
+	}
+}
+
+func TestDefaultHTTPClient_Check_TLSHostAfterRedirect(t *testing.T) {
+	for name, notAfter := range map[string]time.Time{
+		"verified": time.Now().Add(72 * time.Hour),
+		"expired":  time.Now().Add(-time.Hour),
+	} {
+		t.Run(name, func(t *testing.T) {
+			server, client := newTLSServer(t, time.Now().Add(-48*time.Hour), notAfter)
+
+			// The certificate covers 127.0.0.1 but not localhost, the host
+			// the check starts on.
+			redirect := httptest.NewServer(http.RedirectHandler(server.URL, http.StatusFound))
+			defer redirect.Close()
+
+			resp, err := client.Check(context.Background(), newTarget(strings.Replace(redirect.URL, "127.0.0.1", "localhost", 1)))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if resp.TLS == nil || !resp.TLS.HostnameMatches {
+				t.Errorf("expected the certificate to be matched against the redirected host, got %+v", resp.TLS)
+			}
+		})
+	}
+}
+
+func TestDefaultHTTPClient_Check_PlainHTTPHasNoTLS(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	resp, err := NewDefaultHTTPClient(5*time.Second).Check(context.Background(), newTarget(server.URL))
+
+	if err != nil || resp.TLS != nil {
+		t.Errorf("expected no TLS info over plain HTTP, got %+v / %v", resp, err)
+	}
+}
+
+func TestDefaultHTTPClient_Check_AppliesSpec(t *testing.T) {
+	var (
+		method, auth, host string
+		body               []byte
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, auth, host = r.Method, r.Header.Get("Authorization"), r.Host
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	target := newTarget(server.URL)
+	target.HTTP = &domain.HTTPCheck{
+		Method:          "POST",
+		Headers:         map[string]string{"Authorization": "Bearer secret", "Host": "api.example.com"},
+		Body:            `{"ping":true}`,
+		FollowRedirects: true,
+	}
+
+	client := NewDefaultHTTPClient(5 * time.Second)
+	resp, err := client.Check(context.Background(), target)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected StatusCode 204, got %d", resp.StatusCode)
+	}
+	if method != "POST" || auth != "Bearer secret" || host != "api.example.com" || string(body) != `{"ping":true}` {
+		t.Errorf("expected the spec to be applied, got %s %q %q %q", method, auth, host, body)
+	}
+}
+
+func TestDefaultHTTPClient_Check_Redirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewDefaultHTTPClient(5 * time.Second)
+	target := newTarget(server.URL + "/old")
+
+	resp, err := client.Check(context.Background(), target)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the redirect to be followed by default, got %d", resp.StatusCode)
+	}
+
+	target.HTTP = &domain.HTTPCheck{Method: "GET"}
+
+	resp, err = client.Check(context.Background(), target)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.StatusCode != http.StatusMovedPermanently {
+		t.Errorf("expected the redirect itself, got %d", resp.StatusCode)
+	}
+}
+
+func TestDefaultHTTPClient_Check_BodyAssertions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"healthy": false}`))
+	}))
+	defer server.Close()
+
+	target := newTarget(server.URL)
+	target.HTTP = domain.DefaultHTTPCheck()
+	target.HTTP.Assertions = []domain.Assertion{
+		{Type: domain.AssertionMaxSize, Size: 1024},
+		{Type: domain.AssertionJSONPath, Path: "$.healthy", Value: "true"},
+	}
+
+	client := NewDefaultHTTPClient(5 * time.Second)
+	resp, err := client.Check(context.Background(), target)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.FailedAssertion == nil || resp.FailedAssertion.Assertion.Path != "$.healthy" {
+		t.Fatalf("expected the JSONPath assertion to fail, got %v", resp.FailedAssertion)
+	}
+
+	target.HTTP.Assertions = []domain.Assertion{{Type: domain.AssertionMaxSize, Size: 5}}
+
+	resp, err = client.Check(context.Background(), target)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.FailedAssertion == nil || resp.FailedAssertion.Assertion.Type != domain.AssertionMaxSize {
+		t.Errorf("expected the size assertion to fail, got %v", resp.FailedAssertion)
+	}
+}
+
+func TestDefaultHTTPClient_Check_CutOffBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"healthy": true, "padding": "`))
+		w.Write(bytes.Repeat([]byte("x"), maxBodySize))
+		w.Write([]byte(`"}`))
+	}))
+	defer server.Close()
+
+	target := newTarget(server.URL)
+	target.HTTP = domain.DefaultHTTPCheck()
+	target.HTTP.Assertions = []domain.Assertion{
+		{Type: domain.AssertionJSONPath, Path: "$.healthy", Value: "true"},
+	}
+
+	client := NewDefaultHTTPClient(5 * time.Second)
+	resp, err := client.Check(context.Background(), target)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.FailedAssertion == nil || !strings.Contains(resp.FailedAssertion.Reason, "cut off") {
+		t.Fatalf("expected the body to be reported as cut off, got %v", resp.FailedAssertion)
+	}
+
+	target.HTTP.Assertions = append(target.HTTP.Assertions, domain.Assertion{Type: domain.AssertionMaxSize, Size: 1024})
+
+	resp, err = client.Check(context.Background(), target)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.FailedAssertion == nil || resp.FailedAssertion.Assertion.Type != domain.AssertionMaxSize {
+		t.Errorf("expected the size assertion to fail, got %v", resp.FailedAssertion)
+	}
+}
+
+func TestDefaultHTTPClient_Check_Timings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+		w.Write([]byte("first"))
+		w.(http.Flusher).Flush()
+
+		time.Sleep(30 * time.Millisecond)
+		w.Write([]byte("second"))
+	}))
+	defer server.Close()
+
+	client := NewDefaultHTTPClient(5 * time.Second)
+	resp, err := client.Check(context.Background(), newTarget(server.URL))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	timings := resp.Timings
+	if timings == nil {
+		t.Fatal("expected a timing breakdown")
+	}
+
+	if timings.Connect <= 0 {
+		t.Errorf("expected the connect to be timed, got %s", timings.Connect)
+	}
+	if timings.TLS != 0 || timings.DNS != 0 {
+		t.Errorf("expected no TLS or DNS time for a plain request to an IP, got %+v", timings)
+	}
+	if timings.Server < 30*time.Millisecond {
+		t.Errorf("expected at least 30ms of server time, got %s", timings.Server)
+	}
+	if timings.Transfer < 30*time.Millisecond {
+		t.Errorf("expected at least 30ms of transfer time, got %s", timings.Transfer)
+	}
+	if resp.ResponseTime < timings.Server+timings.Transfer {
+		t.Errorf("expected the response time to include the transfer, got %s for %+v", resp.ResponseTime, timings)
+	}
+
+	resp, err = client.Check(context.Background(), newTarget(server.URL))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.Timings.Connect <= 0 {
+		t.Errorf("expected the next check to connect anew, got %+v", resp.Timings)
+	}
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+// timingTrace records the phases of a request. Phases are summed over the
+// hops of a redirect chain; the hooks may fire on other goroutines.
+type timingTrace struct {
+	mu       sync.Mutex
+	timings  domain.Timings
+	dnsStart time.Time
+	dialFrom time.Time
+	tlsStart time.Time
+	wrote    time.Time
+	firstRes time.Time
+}
+
+func (t *timingTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			t.dnsStart = time.Now()
+			t.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			t.timings.DNS += since(t.dnsStart)
+			t.mu.Unlock()
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			// Dual-stack dialing may race several connects; time from the first.
+			if t.dialFrom.IsZero() {
+				t.dialFrom = time.Now()
+			}
+			t.mu.Unlock()
+		},
+		ConnectDone: func(_, _ string, err error) {
+			t.mu.Lock()
+			if err == nil && !t.dialFrom.IsZero() {
+				t.timings.Connect += since(t.dialFrom)
+				t.dialFrom = time.Time{}
+			}
+			t.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			t.tlsStart = time.Now()
+			t.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			t.timings.TLS += since(t.tlsStart)
+			t.mu.Unlock()
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mu.Lock()
+			t.wrote = time.Now()
+			t.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			t.firstRes = time.Now()
+			t.timings.Server += since(t.wrote)
+			t.mu.Unlock()
+		},
+	}
+}
+
+// finish completes the breakdown once the body has been read.
+func (t *timingTrace) finish() *domain.Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timings := t.timings
+	timings.Transfer = since(t.firstRes)
+
+	return &timings
+}
+
+func since(start time.Time) time.Duration {
+	if start.IsZero() {
+		return 0
+	}
+
+	return time.Since(start)
+}
@@ -0,0 +1,192 @@
+// Package incident sends alerts to an incident-management events API such
+// as PagerDuty Events v2.
+package incident
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+const (
+	DefaultEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+	defaultTimeout = 10 * time.Second
+)
+
+const (
+	ActionTrigger = "trigger"
+	ActionResolve = "resolve"
+)
+
+const (
+	SeverityCritical = "critical"
+	SeverityError    = "error"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+type Option func(*Notifier)
+
+// WithEventsURL sends events to url instead of DefaultEventsURL.
+func WithEventsURL(url string) Option {
+	return func(n *Notifier) {
+		n.url = url
+	}
+}
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(n *Notifier) {
+		n.client = client
+	}
+}
+
+// Notifier triggers an incident for every alert that opens and resolves it
+// with the alert. Events of one target and alert type share a dedup key, so
+// the incident tool folds repeats into a single incident. Reminders are left
+// out, as the incident tool escalates on its own. Failed events are left to
+// the outbox to retry.
+type Notifier struct {
+	url        string
+	routingKey string
+	client     *http.Client
+}
+
+// NewNotifier sends events to the service integration with routingKey.
+func NewNotifier(routingKey string, opts ...Option) *Notifier {
+	n := &Notifier{
+		url:        DefaultEventsURL,
+		routingKey: routingKey,
+		client:     &http.Client{Timeout: defaultTimeout},
+	}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	return n
+}
+
+type Event struct {
+	RoutingKey  string   `json:"routing_key"`
+	EventAction string   `json:"event_action"`
+	DedupKey    string   `json:"dedup_key"`
+	Payload     *Payload `json:"payload,omitempty"`
+	Links       []Link   `json:"links,omitempty"`
+}
+
+type Payload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     time.Time         `json:"timestamp"`
+	Component     string            `json:"component,omitempty"`
+	Class         string            `json:"class"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+type Link struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+// DedupKey identifies the incident of an alert: its target and type.
+func DedupKey(alert domain.Alert) string {
+	return alert.TargetID + ":" + alert.Type
+}
+
+// Severity maps an alert type to an event severity: downtime is critical,
+// failed assertions and client errors are errors, and alerts about a target
+// that still answers are warnings.
+func Severity(alertType string) string {
+	switch alertType {
+	case domain.StatusServerError, domain.StatusError:
+		return SeverityCritical
+	case domain.StatusClientError, domain.StatusAssertionFailed:
+		return SeverityError
+	case domain.AlertTypeSlowResponse, domain.AlertTypeCertificateExpiry:
+		return SeverityWarning
+	default:
+		return SeverityError
+	}
+}
+
+// NewEvent builds the event for a notification. Resolve events carry no
+// payload.
+func (n *Notifier) NewEvent(notification domain.Notification) Event {
+	alert, target := notification.Alert, notification.Target
+
+	event := Event{
+		RoutingKey:  n.routingKey,
+		EventAction: ActionTrigger,
+		DedupKey:    DedupKey(alert),
+	}
+
+	if notification.Event == domain.NotificationResolved {
+		event.EventAction = ActionResolve
+		return event
+	}
+
+	details := map[string]string{
+		"alert_id": alert.ID,
+		"state":    target.State,
+	}
+	if notification.ResponseTime > 0 {
+		details["response_time"] = notification.ResponseTime.String()
+	}
+
+	event.Payload = &Payload{
+		Summary:       alert.Message,
+		Source:        target.URL,
+		Severity:      Severity(alert.Type),
+		Timestamp:     alert.CreatedAt,
+		Component:     target.Name,
+		Class:         alert.Type,
+		CustomDetails: details,
+	}
+
+	if strings.HasPrefix(target.URL, "http://") || strings.HasPrefix(target.URL, "https://") {
+		event.Links = []Link{{Href: target.URL, Text: target.URL}}
+	}
+
+	return event
+}
+
+func (n *Notifier) Notify(ctx context.Context, notification domain.Notification) error {
+	if notification.Event == domain.NotificationRepeated {
+		return nil
+	}
+
+	body, err := json.Marshal(n.NewEvent(notification))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("events API answered %s: %s", res.Status, strings.TrimSpace(string(data)))
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package incident
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+// receive starts a stand-in events API that answers with the given statuses
+// in turn, repeating the last one, and passes on the events it got.
+func receive(t *testing.T, statuses ...int) (string, <-chan Event, *atomic.Int32) {
+	t.Helper()
+
+	events := make(chan Event, 8)
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(calls.Add(1))
+
+		data, _ := io.ReadAll(r.Body)
+		var event Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			t.Errorf("expected a JSON event, got %s", data)
+		}
+		events <- event
+
+		w.WriteHeader(statuses[min(call, len(statuses))-1])
+		io.WriteString(w, `{"status": "success", "message": "Event processed"}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv.URL, events, &calls
+}
+
+func newNotification(event, alertType string) domain.Notification {
+	target := domain.NewTarget("target-1", "https://example.com", "Example", time.Minute)
+	target.State = domain.StateDown
+
+	alert := domain.NewAlert("alert-1", target.ID, alertType, "Target https://example.com is "+alertType)
+	if event == domain.NotificationResolved {
+		alert.Resolve()
+	}
+
+	return domain.Notification{Event: event, Alert: *alert, Target: *target, ResponseTime: 250 * time.Millisecond}
+}
+
+func TestNotifier_TriggerAndResolve(t *testing.T) {
+	url, events, _ := receive(t, http.StatusAccepted)
+	notifier := NewNotifier("routing-key", WithEventsURL(url))
+
+	if err := notifier.Notify(context.Background(), newNotification(domain.NotificationOpened, domain.StatusServerError)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	trigger := <-events
+	if trigger.RoutingKey != "routing-key" || trigger.EventAction != ActionTrigger || trigger.DedupKey != "target-1:SERVER_ERROR" {
+		t.Errorf("unexpected trigger event %+v", trigger)
+	}
+
+	if trigger.Payload == nil || trigger.Payload.Severity != SeverityCritical || trigger.Payload.Source != "https://example.com" ||
+		trigger.Payload.Summary != "Target https://example.com is SERVER_ERROR" || trigger.Payload.CustomDetails["alert_id"] != "alert-1" {
+		t.Errorf("unexpected trigger payload %+v", trigger.Payload)
+	}
+
+	if err := notifier.Notify(context.Background(), newNotification(domain.NotificationResolved, domain.StatusServerError)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	resolve := <-events
+	if resolve.EventAction != ActionResolve || resolve.DedupKey != trigger.DedupKey || resolve.Payload != nil {
+		t.Errorf("expected a resolve event for the same dedup key, got %+v", resolve)
+	}
+}
+
+func TestNotifier_SkipsReminders(t *testing.T) {
+	url, _, calls := receive(t, http.StatusAccepted)
+
+	if err := NewNotifier("routing-key", WithEventsURL(url)).Notify(context.Background(), newNotification(domain.NotificationRepeated, domain.StatusError)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if calls.Load() != 0 {
+		t.Errorf("expected no event for a reminder, got %d calls", calls.Load())
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	cases := map[string]string{
+		domain.StatusServerError:          SeverityCritical,
+		domain.StatusError:                SeverityCritical,
+		domain.StatusClientError:          SeverityError,
+		domain.StatusAssertionFailed:      SeverityError,
+		domain.AlertTypeSlowResponse:      SeverityWarning,
+		domain.AlertTypeCertificateExpiry: SeverityWarning,
+		"SOMETHING_ELSE":                  SeverityError,
+	}
+
+	for alertType, want := range cases {
+		if got := Severity(alertType); got != want {
+			t.Errorf("%s: expected severity %s, got %s", alertType, want, got)
+		}
+	}
+}
+
+func TestNotifier_LeavesRetriesToTheOutbox(t *testing.T) {
+	for _, status := range []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusBadRequest} {
+		url, _, calls := receive(t, status)
+
+		if err := NewNotifier("routing-key", WithEventsURL(url)).Notify(context.Background(), newNotification(domain.NotificationOpened, domain.StatusError)); err == nil {
+			t.Errorf("%d: expected an error", status)
+		}
+
+		if calls.Load() != 1 {
+			t.Errorf("%d: expected a single call, got %d", status, calls.Load())
+		}
+	}
+}
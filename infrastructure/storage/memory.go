@@ -1,8 +1,11 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/karoljaro/go-uptime-monitor/domain"
 )
@@ -24,7 +27,7 @@ func (r *MemoryTargetRepository) Save(target *domain.Target) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.targets[target.ID] = target
+	r.targets[target.ID] = copyTarget(target)
 
 	return nil
 }
@@ -36,10 +39,10 @@ func (r *MemoryTargetRepository) FindByID(id string) (*domain.Target, error) {
 	val, exists := r.targets[id]
 
 	if !exists {
-		return nil, fmt.Errorf("target with id: %s not found", id)
+		return nil, fmt.Errorf("target with id: %s %w", id, domain.ErrNotFound)
 	}
 
-	return val, nil
+	return copyTarget(val), nil
 }
 
 func (r *MemoryTargetRepository) GetAll() ([]*domain.Target, error) {
@@ -49,7 +52,7 @@ func (r *MemoryTargetRepository) GetAll() ([]*domain.Target, error) {
 	targets := make([]*domain.Target, 0, len(r.targets))
 
 	for _, target := range r.targets {
-		targets = append(targets, target)
+		targets = append(targets, copyTarget(target))
 	}
 
 	return targets, nil
@@ -60,7 +63,7 @@ func (r *MemoryTargetRepository) Delete(id string) error {
 	defer r.mu.Unlock()
 
 	if _, exists := r.targets[id]; !exists {
-		return fmt.Errorf("target with id: %s not found", id)
+		return fmt.Errorf("target with id: %s %w", id, domain.ErrNotFound)
 	}
 
 	delete(r.targets, id)
@@ -72,13 +75,93 @@ func (r *MemoryTargetRepository) Update(target *domain.Target) error {
 	defer r.mu.Unlock()
 
 	if _, exists := r.targets[target.ID]; !exists {
-		return fmt.Errorf("target with id: %s not found", target.ID)
+		return fmt.Errorf("target with id: %s %w", target.ID, domain.ErrNotFound)
 	}
 
-	r.targets[target.ID] = target
+	r.targets[target.ID] = copyTarget(target)
 	return nil
 }
 
+func (r *MemoryTargetRepository) ChangeState(change *domain.StateChange) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	target, exists := r.targets[change.TargetID]
+	if !exists {
+		return false, nil
+	}
+
+	from := target.State
+	if from == "" {
+		from = domain.StateUnknown
+	}
+	if from != change.From {
+		return false, nil
+	}
+
+	target.State = change.To
+	target.StateChangedAt = change.ChangedAt
+	if active, changes := change.Activity(); changes {
+		target.IsActive = active
+	}
+
+	return true, nil
+}
+
+func (r *MemoryTargetRepository) SetEscalationPolicy(id, policyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	target, exists := r.targets[id]
+	if !exists {
+		return fmt.Errorf("target with id: %s %w", id, domain.ErrNotFound)
+	}
+
+	target.EscalationPolicyID = policyID
+	return nil
+}
+
+// copyTarget keeps callers from changing stored targets other than through
+// the repository. Its settings are shared, as they are replaced rather than
+// changed in place.
+func copyTarget(target *domain.Target) *domain.Target {
+	c := *target
+	return &c
+}
+
+// ========== [STATE CHANGE] ==========
+
+type MemoryStateChangeRepository struct {
+	mu      sync.RWMutex
+	changes map[string][]*domain.StateChange
+}
+
+func NewMemoryStateChangeRepository() *MemoryStateChangeRepository {
+	return &MemoryStateChangeRepository{
+		changes: make(map[string][]*domain.StateChange),
+	}
+}
+
+func (r *MemoryStateChangeRepository) Save(change *domain.StateChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	changes := append(r.changes[change.TargetID], change)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].ChangedAt.Before(changes[j].ChangedAt)
+	})
+	r.changes[change.TargetID] = changes
+
+	return nil
+}
+
+func (r *MemoryStateChangeRepository) FindByTargetID(targetID string) ([]*domain.StateChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]*domain.StateChange{}, r.changes[targetID]...), nil
+}
+
 // ========== [ALERT] ==========
 
 type MemoryAlertRepository struct {
@@ -96,19 +179,44 @@ func (r *MemoryAlertRepository) Save(alert *domain.Alert) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.alerts[alert.TargetID] = append(r.alerts[alert.TargetID], alert)
+	r.alerts[alert.TargetID] = append(r.alerts[alert.TargetID], copyAlert(alert))
 	return nil
 }
 
+func (r *MemoryAlertRepository) FindByID(id string) (*domain.Alert, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	alert, err := r.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return copyAlert(alert), nil
+}
+
+// find returns the stored alert; callers hold r.mu.
+func (r *MemoryAlertRepository) find(id string) (*domain.Alert, error) {
+	for _, alerts := range r.alerts {
+		for _, alert := range alerts {
+			if alert.ID == id {
+				return alert, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("alert with id: %s %w", id, domain.ErrNotFound)
+}
+
 func (r *MemoryAlertRepository) FindByTargetID(targetID string) ([]*domain.Alert, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	if val, exists := r.alerts[targetID]; exists {
-		return val, nil
+		return copyAlerts(val), nil
 	}
 
-	return nil, fmt.Errorf("alerts with targetID: %s not found", targetID)
+	return nil, fmt.Errorf("alerts with targetID: %s %w", targetID, domain.ErrNotFound)
 }
 
 func (r  *MemoryAlertRepository) GetUnresolvedByTargetID(targetID string) ([]*domain.Alert, error) {
@@ -123,10 +231,30 @@ func (r  *MemoryAlertRepository) GetUnresolvedByTargetID(targetID string) ([]*do
 
 	for _, alert := range r.alerts[targetID] {
 		if !alert.IsResolved {
-			alerts = append(alerts, alert)
+			alerts = append(alerts, copyAlert(alert))
+		}
+	}
+
+	return alerts, nil
+}
+
+func (r *MemoryAlertRepository) GetUnacknowledged() ([]*domain.Alert, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	alerts := make([]*domain.Alert, 0)
+	for _, targetAlerts := range r.alerts {
+		for _, alert := range targetAlerts {
+			if !alert.IsResolved && !alert.IsAcknowledged() {
+				alerts = append(alerts, copyAlert(alert))
+			}
 		}
 	}
 
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].CreatedAt.Before(alerts[j].CreatedAt)
+	})
+
 	return alerts, nil
 }
 
@@ -135,17 +263,411 @@ func (r *MemoryAlertRepository) Update(alert *domain.Alert) error {
 	defer r.mu.Unlock()
 
 	if _, exists := r.alerts[alert.TargetID]; !exists {
-		return fmt.Errorf("alert with id: %s not found", alert.ID)
+		return fmt.Errorf("alert with id: %s %w", alert.ID, domain.ErrNotFound)
 	} 
 
 	for idx, _alert := range r.alerts[alert.TargetID] {
 		if alert.ID == _alert.ID {
-			r.alerts[alert.TargetID][idx] = alert
+			r.alerts[alert.TargetID][idx] = copyAlert(alert)
 			return nil
 		}
 	}
 	
-	return fmt.Errorf("alert with id: %s not found", alert.ID)
+	return fmt.Errorf("alert with id: %s %w", alert.ID, domain.ErrNotFound)
+}
+
+func (r *MemoryAlertRepository) Remind(id string, at time.Time) (bool, error) {
+	return r.changeIf(id, func(alert *domain.Alert) bool {
+		if alert.IsResolved || alert.IsSilenced(at) {
+			return false
+		}
+
+		alert.NotifiedAt = at
+		return true
+	})
+}
+
+func (r *MemoryAlertRepository) Resolve(id string, at time.Time) (bool, error) {
+	return r.changeIf(id, func(alert *domain.Alert) bool {
+		if alert.IsResolved {
+			return false
+		}
+
+		alert.ResolvedAt = &at
+		alert.IsResolved = true
+		return true
+	})
+}
+
+func (r *MemoryAlertRepository) Acknowledge(id, by string, at time.Time) error {
+	return r.change(id, func(alert *domain.Alert) error {
+		return alert.Acknowledge(by, at)
+	})
+}
+
+func (r *MemoryAlertRepository) Snooze(id string, until time.Time) error {
+	return r.change(id, func(alert *domain.Alert) error {
+		return alert.Snooze(until)
+	})
+}
+
+func (r *MemoryAlertRepository) Assign(id, assignee string) error {
+	return r.change(id, func(alert *domain.Alert) error {
+		return alert.Assign(assignee)
+	})
+}
+
+// change applies fn to the stored alert under the lock.
+func (r *MemoryAlertRepository) change(id string, fn func(alert *domain.Alert) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	alert, err := r.find(id)
+	if err != nil {
+		return err
+	}
+
+	return fn(alert)
+}
+
+// changeIf applies fn to alert id and reports whether fn changed it. A
+// missing alert is not changed.
+func (r *MemoryAlertRepository) changeIf(id string, fn func(alert *domain.Alert) bool) (bool, error) {
+	changed := false
+	err := r.change(id, func(alert *domain.Alert) error {
+		changed = fn(alert)
+		return nil
+	})
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+
+	return changed, err
+}
+
+// copyAlert keeps callers from changing stored alerts other than through
+// the repository.
+func copyAlert(alert *domain.Alert) *domain.Alert {
+	c := *alert
+	c.ResolvedAt = copyTime(alert.ResolvedAt)
+	c.AcknowledgedAt = copyTime(alert.AcknowledgedAt)
+	c.SnoozedUntil = copyTime(alert.SnoozedUntil)
+
+	return &c
+}
+
+func copyAlerts(alerts []*domain.Alert) []*domain.Alert {
+	copies := make([]*domain.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		copies = append(copies, copyAlert(alert))
+	}
+
+	return copies
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	c := *t
+	return &c
+}
+
+// ========== [ESCALATION POLICY] ==========
+
+type MemoryEscalationPolicyRepository struct {
+	mu       sync.RWMutex
+	policies map[string]*domain.EscalationPolicy
+}
+
+func NewMemoryEscalationPolicyRepository() *MemoryEscalationPolicyRepository {
+	return &MemoryEscalationPolicyRepository{
+		policies: make(map[string]*domain.EscalationPolicy),
+	}
+}
+
+func (r *MemoryEscalationPolicyRepository) Save(policy *domain.EscalationPolicy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.policies[policy.ID] = policy
+	return nil
+}
+
+func (r *MemoryEscalationPolicyRepository) FindByID(id string) (*domain.EscalationPolicy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	policy, exists := r.policies[id]
+	if !exists {
+		return nil, fmt.Errorf("escalation policy with id: %s %w", id, domain.ErrNotFound)
+	}
+
+	return policy, nil
+}
+
+func (r *MemoryEscalationPolicyRepository) GetAll() ([]*domain.EscalationPolicy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	policies := make([]*domain.EscalationPolicy, 0, len(r.policies))
+	for _, policy := range r.policies {
+		policies = append(policies, policy)
+	}
+
+	sort.Slice(policies, func(i, j int) bool {
+		if !policies[i].CreatedAt.Equal(policies[j].CreatedAt) {
+			return policies[i].CreatedAt.Before(policies[j].CreatedAt)
+		}
+		return policies[i].ID < policies[j].ID
+	})
+
+	return policies, nil
+}
+
+func (r *MemoryEscalationPolicyRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.policies[id]; !exists {
+		return fmt.Errorf("escalation policy with id: %s %w", id, domain.ErrNotFound)
+	}
+
+	delete(r.policies, id)
+	return nil
+}
+
+// ========== [MAINTENANCE WINDOW] ==========
+
+type MemoryMaintenanceWindowRepository struct {
+	mu      sync.RWMutex
+	windows map[string]*domain.MaintenanceWindow
+}
+
+func NewMemoryMaintenanceWindowRepository() *MemoryMaintenanceWindowRepository {
+	return &MemoryMaintenanceWindowRepository{
+		windows: make(map[string]*domain.MaintenanceWindow),
+	}
+}
+
+func (r *MemoryMaintenanceWindowRepository) Save(window *domain.MaintenanceWindow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.windows[window.ID] = window
+	return nil
+}
+
+func (r *MemoryMaintenanceWindowRepository) FindByID(id string) (*domain.MaintenanceWindow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	window, exists := r.windows[id]
+	if !exists {
+		return nil, fmt.Errorf("maintenance window with id: %s %w", id, domain.ErrNotFound)
+	}
+
+	return window, nil
+}
+
+func (r *MemoryMaintenanceWindowRepository) GetAll() ([]*domain.MaintenanceWindow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	windows := make([]*domain.MaintenanceWindow, 0, len(r.windows))
+	for _, window := range r.windows {
+		windows = append(windows, window)
+	}
+
+	sort.Slice(windows, func(i, j int) bool {
+		if !windows[i].CreatedAt.Equal(windows[j].CreatedAt) {
+			return windows[i].CreatedAt.Before(windows[j].CreatedAt)
+		}
+		return windows[i].ID < windows[j].ID
+	})
+
+	return windows, nil
+}
+
+func (r *MemoryMaintenanceWindowRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.windows[id]; !exists {
+		return fmt.Errorf("maintenance window with id: %s %w", id, domain.ErrNotFound)
+	}
+
+	delete(r.windows, id)
+	return nil
+}
+
+// ========== [OUTBOX] ==========
+
+type MemoryOutboxRepository struct {
+	mu       sync.RWMutex
+	alerts   *MemoryAlertRepository
+	messages map[string]*domain.OutboxMessage
+}
+
+// NewMemoryOutboxRepository saves alerts to alerts, so they are visible
+// through it as usual.
+func NewMemoryOutboxRepository(alerts *MemoryAlertRepository) *MemoryOutboxRepository {
+	return &MemoryOutboxRepository{
+		alerts:   alerts,
+		messages: make(map[string]*domain.OutboxMessage),
+	}
+}
+
+func (r *MemoryOutboxRepository) SaveAlert(alert *domain.Alert, messages []*domain.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.alerts.Save(alert); err != nil {
+		return err
+	}
+
+	r.enqueue(messages)
+	return nil
+}
+
+func (r *MemoryOutboxRepository) RemindAlert(alertID string, at time.Time, messages []*domain.OutboxMessage) (bool, error) {
+	return r.changeAlert(messages, func() (bool, error) {
+		return r.alerts.Remind(alertID, at)
+	})
+}
+
+func (r *MemoryOutboxRepository) ResolveAlert(alertID string, at time.Time, messages []*domain.OutboxMessage) (bool, error) {
+	return r.changeAlert(messages, func() (bool, error) {
+		return r.alerts.Resolve(alertID, at)
+	})
+}
+
+func (r *MemoryOutboxRepository) AdvanceEscalation(alertID string, from, to int, messages []*domain.OutboxMessage) (bool, error) {
+	return r.changeAlert(messages, func() (bool, error) {
+		return r.alerts.changeIf(alertID, func(alert *domain.Alert) bool {
+			if alert.IsResolved || alert.IsAcknowledged() || alert.EscalationStep != from {
+				return false
+			}
+
+			alert.EscalationStep = to
+			return true
+		})
+	})
+}
+
+// changeAlert runs change and queues messages if it changed the alert.
+func (r *MemoryOutboxRepository) changeAlert(messages []*domain.OutboxMessage, change func() (bool, error)) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	changed, err := change()
+	if err != nil || !changed {
+		return false, err
+	}
+
+	r.enqueue(messages)
+	return true, nil
+}
+
+func (r *MemoryOutboxRepository) enqueue(messages []*domain.OutboxMessage) {
+	for _, message := range messages {
+		r.messages[message.ID] = message
+	}
+}
+
+func (r *MemoryOutboxRepository) FindByID(id string) (*domain.OutboxMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if message, exists := r.messages[id]; exists {
+		return message, nil
+	}
+
+	return nil, fmt.Errorf("outbox message with id: %s %w", id, domain.ErrNotFound)
+}
+
+func (r *MemoryOutboxRepository) Due(now time.Time, limit int) ([]*domain.OutboxMessage, error) {
+	messages := r.filter(func(m *domain.OutboxMessage) bool {
+		return !m.IsDead() && !m.NextAttemptAt.After(now) && !r.waiting(m)
+	})
+
+	sort.Slice(messages, func(i, j int) bool {
+		if !messages[i].NextAttemptAt.Equal(messages[j].NextAttemptAt) {
+			return messages[i].NextAttemptAt.Before(messages[j].NextAttemptAt)
+		}
+		return messages[i].ID < messages[j].ID
+	})
+
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+
+	return messages, nil
+}
+
+// waiting reports whether a live message precedes message. The caller holds
+// the lock.
+func (r *MemoryOutboxRepository) waiting(message *domain.OutboxMessage) bool {
+	for _, other := range r.messages {
+		if !other.IsDead() && other.Precedes(message) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *MemoryOutboxRepository) DeadLetters() ([]*domain.OutboxMessage, error) {
+	messages := r.filter((*domain.OutboxMessage).IsDead)
+
+	sort.Slice(messages, func(i, j int) bool {
+		if !messages[i].DeadAt.Equal(*messages[j].DeadAt) {
+			return messages[i].DeadAt.Before(*messages[j].DeadAt)
+		}
+		return messages[i].ID < messages[j].ID
+	})
+
+	return messages, nil
+}
+
+func (r *MemoryOutboxRepository) filter(keep func(*domain.OutboxMessage) bool) []*domain.OutboxMessage {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	messages := make([]*domain.OutboxMessage, 0)
+
+	for _, message := range r.messages {
+		if keep(message) {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages
+}
+
+func (r *MemoryOutboxRepository) Update(message *domain.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.messages[message.ID]; !exists {
+		return fmt.Errorf("outbox message with id: %s %w", message.ID, domain.ErrNotFound)
+	}
+
+	r.messages[message.ID] = message
+	return nil
+}
+
+func (r *MemoryOutboxRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.messages[id]; !exists {
+		return fmt.Errorf("outbox message with id: %s %w", id, domain.ErrNotFound)
+	}
+
+	delete(r.messages, id)
+	return nil
 }
 
 // ========== [RESULT] ==========
@@ -161,11 +683,22 @@ func NewMemoryResultRepository() *MemoryResultRepository {
 	}
 }
 
+// Save keeps each target's results sorted by (CheckedAt, ID) so that range
+// queries can binary search instead of scanning every result.
 func (r *MemoryResultRepository) Save(result *domain.Result) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.results[result.TargetID] = append(r.results[result.TargetID], result)
+	results := r.results[result.TargetID]
+	idx := sort.Search(len(results), func(i int) bool {
+		return resultAfter(results[i], result.CheckedAt, result.ID)
+	})
+
+	results = append(results, nil)
+	copy(results[idx+1:], results[idx:])
+	results[idx] = result
+
+	r.results[result.TargetID] = results
 	return nil
 }
 
@@ -177,7 +710,7 @@ func (r *MemoryResultRepository) FindByTargetID(targetID string) ([]*domain.Resu
 		return val, nil
 	} 
 		
-	return nil, fmt.Errorf("result with targetID: %s not found", targetID)
+	return nil, fmt.Errorf("result with targetID: %s %w", targetID, domain.ErrNotFound)
 }
 
 func (r *MemoryResultRepository) GetLastByTargetID(targetID string) (*domain.Result, error) {
@@ -187,8 +720,184 @@ func (r *MemoryResultRepository) GetLastByTargetID(targetID string) (*domain.Res
 	val, exists := r.results[targetID]
 
 	if !exists || len(val) == 0 {
-		return nil, fmt.Errorf("last result with targetID %s not found", targetID)
+		return nil, fmt.Errorf("last result with targetID %s %w", targetID, domain.ErrNotFound)
 	}
 
 	return val[len(val)-1], nil
 }
+
+func (r *MemoryResultRepository) QueryByTargetID(targetID string, query domain.ResultQuery) (*domain.ResultPage, error) {
+	var cursor *domain.ResultCursor
+	if query.Cursor != "" {
+		c, err := domain.DecodeResultCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursor = &c
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := r.results[targetID]
+	limit := query.EffectiveLimit()
+
+	lo := 0
+	if !query.From.IsZero() {
+		lo = sort.Search(len(results), func(i int) bool {
+			return !results[i].CheckedAt.Before(query.From)
+		})
+	}
+
+	hi := len(results)
+	if !query.To.IsZero() {
+		hi = sort.Search(len(results), func(i int) bool {
+			return !results[i].CheckedAt.Before(query.To)
+		})
+	}
+
+	if cursor != nil {
+		idx := sort.Search(len(results), func(i int) bool {
+			return !cursor.Before(results[i])
+		})
+		hi = min(hi, idx)
+	}
+
+	page := &domain.ResultPage{Results: make([]*domain.Result, 0, min(limit, max(hi-lo, 0)))}
+
+	for i := hi - 1; i >= lo; i-- {
+		if query.Status != "" && results[i].Status != query.Status {
+			continue
+		}
+
+		if len(page.Results) == limit {
+			page.NextCursor = domain.NewResultCursor(page.Results[limit-1]).Encode()
+			break
+		}
+
+		page.Results = append(page.Results, results[i])
+	}
+
+	return page, nil
+}
+
+// DeleteBefore drops results checked before the given time. The remaining
+// results are copied so the dropped ones can be garbage collected.
+func (r *MemoryResultRepository) DeleteBefore(targetID string, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := r.results[targetID]
+	idx := sort.Search(len(results), func(i int) bool {
+		return !results[i].CheckedAt.Before(before)
+	})
+
+	if idx == 0 {
+		return 0, nil
+	}
+
+	if idx == len(results) {
+		delete(r.results, targetID)
+		return idx, nil
+	}
+
+	r.results[targetID] = append([]*domain.Result(nil), results[idx:]...)
+	return idx, nil
+}
+
+func resultAfter(result *domain.Result, checkedAt time.Time, id string) bool {
+	if result.CheckedAt.Equal(checkedAt) {
+		return result.ID > id
+	}
+
+	return result.CheckedAt.After(checkedAt)
+}
+
+// ========== [ROLLUP] ==========
+
+type rollupKey struct {
+	targetID   string
+	resolution domain.RollupResolution
+}
+
+type MemoryRollupRepository struct {
+	mu      sync.RWMutex
+	rollups map[rollupKey][]*domain.Rollup
+}
+
+func NewMemoryRollupRepository() *MemoryRollupRepository {
+	return &MemoryRollupRepository{
+		rollups: make(map[rollupKey][]*domain.Rollup),
+	}
+}
+
+// Save keeps each target's rollups sorted by bucket and replaces a rollup
+// saved earlier for the same bucket.
+func (r *MemoryRollupRepository) Save(rollup *domain.Rollup) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := rollupKey{targetID: rollup.TargetID, resolution: rollup.Resolution}
+	rollups := r.rollups[key]
+
+	idx := sort.Search(len(rollups), func(i int) bool {
+		return !rollups[i].BucketStart.Before(rollup.BucketStart)
+	})
+
+	if idx < len(rollups) && rollups[idx].BucketStart.Equal(rollup.BucketStart) {
+		rollups[idx] = rollup
+		return nil
+	}
+
+	rollups = append(rollups, nil)
+	copy(rollups[idx+1:], rollups[idx:])
+	rollups[idx] = rollup
+
+	r.rollups[key] = rollups
+	return nil
+}
+
+func (r *MemoryRollupRepository) FindByTargetID(targetID string, resolution domain.RollupResolution, from, to time.Time) ([]*domain.Rollup, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rollups := r.rollups[rollupKey{targetID: targetID, resolution: resolution}]
+
+	lo := sort.Search(len(rollups), func(i int) bool {
+		return !rollups[i].BucketStart.Before(from)
+	})
+	hi := sort.Search(len(rollups), func(i int) bool {
+		return !rollups[i].BucketStart.Before(to)
+	})
+
+	found := make([]*domain.Rollup, 0, max(hi-lo, 0))
+	for i := lo; i < hi; i++ {
+		found = append(found, rollups[i])
+	}
+
+	return found, nil
+}
+
+func (r *MemoryRollupRepository) DeleteBefore(targetID string, resolution domain.RollupResolution, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := rollupKey{targetID: targetID, resolution: resolution}
+	rollups := r.rollups[key]
+
+	idx := sort.Search(len(rollups), func(i int) bool {
+		return !rollups[i].BucketStart.Before(before)
+	})
+
+	if idx == 0 {
+		return 0, nil
+	}
+
+	if idx == len(rollups) {
+		delete(r.rollups, key)
+		return idx, nil
+	}
+
+	r.rollups[key] = append([]*domain.Rollup(nil), rollups[idx:]...)
+	return idx, nil
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"github.com/karoljaro/go-uptime-monitor/domain"
+	"github.com/karoljaro/go-uptime-monitor/infrastructure/storage/storagetest"
 	"testing"
 	"time"
 )
@@ -466,3 +467,60 @@ func TestMemoryAlertUpdate(t *testing.T) {
 		t.Errorf("expected %s, got %s", message2, found.Message)
 	}
 }
+
+// ======================[Contract]======================
+
+func TestMemoryTargetRepository_Contract(t *testing.T) {
+	storagetest.RunTargetRepositoryTests(t, func(t *testing.T) domain.TargetRepository {
+		return NewMemoryTargetRepository()
+	})
+}
+
+func TestMemoryStateChangeRepository_Contract(t *testing.T) {
+	storagetest.RunStateChangeRepositoryTests(t, func(t *testing.T) domain.StateChangeRepository {
+		return NewMemoryStateChangeRepository()
+	})
+}
+
+func TestMemoryOutboxRepository_Contract(t *testing.T) {
+	storagetest.RunOutboxRepositoryTests(t, func(t *testing.T) (domain.OutboxRepository, domain.AlertRepository) {
+		alerts := NewMemoryAlertRepository()
+		return NewMemoryOutboxRepository(alerts), alerts
+	})
+}
+
+func TestMemoryMaintenanceWindowRepository_Contract(t *testing.T) {
+	storagetest.RunMaintenanceWindowRepositoryTests(t, func(t *testing.T) domain.MaintenanceWindowRepository {
+		return NewMemoryMaintenanceWindowRepository()
+	})
+}
+
+func TestMemoryEscalationPolicyRepository_Contract(t *testing.T) {
+	storagetest.RunEscalationPolicyRepositoryTests(t, func(t *testing.T) domain.EscalationPolicyRepository {
+		return NewMemoryEscalationPolicyRepository()
+	})
+}
+
+func TestMemoryResultRepository_Contract(t *testing.T) {
+	storagetest.RunResultRepositoryTests(t, func(t *testing.T) domain.ResultRepository {
+		return NewMemoryResultRepository()
+	})
+}
+
+func TestMemoryResultRepository_QueryContract(t *testing.T) {
+	storagetest.RunResultQueryTests(t, func(t *testing.T) domain.ResultRepository {
+		return NewMemoryResultRepository()
+	})
+}
+
+func TestMemoryAlertRepository_Contract(t *testing.T) {
+	storagetest.RunAlertRepositoryTests(t, func(t *testing.T) domain.AlertRepository {
+		return NewMemoryAlertRepository()
+	})
+}
+
+func TestMemoryRollupRepository_Contract(t *testing.T) {
+	storagetest.RunRollupRepositoryTests(t, func(t *testing.T) domain.RollupRepository {
+		return NewMemoryRollupRepository()
+	})
+}
@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// The integration tests need a PostgreSQL server. UPTIME_TEST_POSTGRES_DSN
+// points them at an existing one; otherwise a throwaway cluster is started
+// with initdb/pg_ctl when they are installed, and the tests are skipped when
+// neither is available.
+
+var (
+	testDSN     string
+	skipReason  string
+	schemaCount atomic.Int64
+)
+
+func TestMain(m *testing.M) {
+	stop, err := startPostgres()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "starting postgres: %v\n", err)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	stop()
+	os.Exit(code)
+}
+
+func startPostgres() (func(), error) {
+	if dsn := os.Getenv("UPTIME_TEST_POSTGRES_DSN"); dsn != "" {
+		testDSN = dsn
+		return func() {}, nil
+	}
+
+	initdb, err := findPostgresBinary("initdb")
+	if err != nil {
+		skipReason = "no PostgreSQL available: set UPTIME_TEST_POSTGRES_DSN or install initdb/pg_ctl"
+		return func() {}, nil
+	}
+
+	pgCtl, err := findPostgresBinary("pg_ctl")
+	if err != nil {
+		skipReason = "no PostgreSQL available: pg_ctl not found"
+		return func() {}, nil
+	}
+
+	dir, err := os.MkdirTemp("", "uptime-pg-")
+	if err != nil {
+		return nil, err
+	}
+
+	dataDir := filepath.Join(dir, "data")
+	out, err := exec.Command(initdb, "-D", dataDir, "-U", "postgres", "-A", "trust", "--no-sync").CombinedOutput()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("initdb: %v: %s", err, out)
+	}
+
+	port, err := freePort()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	opts := fmt.Sprintf("-p %d -k %s -c listen_addresses=127.0.0.1 -c fsync=off", port, dir)
+	out, err = exec.Command(pgCtl, "-D", dataDir, "-o", opts, "-l", filepath.Join(dir, "postgres.log"), "-w", "start").CombinedOutput()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("pg_ctl start: %v: %s", err, out)
+	}
+
+	testDSN = fmt.Sprintf("postgres://postgres@127.0.0.1:%d/postgres?sslmode=disable", port)
+
+	return func() {
+		exec.Command(pgCtl, "-D", dataDir, "-m", "immediate", "stop").Run()
+		os.RemoveAll(dir)
+	}, nil
+}
+
+func findPostgresBinary(name string) (string, error) {
+	if path, err := exec.LookPath(name); err == nil {
+		return path, nil
+	}
+
+	// Debian and Ubuntu keep the server binaries out of PATH.
+	matches, _ := filepath.Glob(filepath.Join("/usr/lib/postgresql", "*", "bin", name))
+	if len(matches) > 0 {
+		return matches[len(matches)-1], nil
+	}
+
+	return "", fmt.Errorf("%s not found", name)
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// openTestDB opens a connection scoped to a fresh schema, so every test
+// starts from an empty, freshly migrated database.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	if testDSN == "" {
+		t.Skip(skipReason)
+	}
+
+	admin, err := sql.Open("pgx", testDSN)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer admin.Close()
+
+	schema := fmt.Sprintf("test_%d_%d", time.Now().UnixNano(), schemaCount.Add(1))
+	if _, err := admin.Exec(`CREATE SCHEMA ` + schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+
+	db, err := Open(withSearchPath(testDSN, schema))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+
+		admin, err := sql.Open("pgx", testDSN)
+		if err != nil {
+			return
+		}
+		defer admin.Close()
+
+		admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`)
+	})
+
+	return db
+}
+
+func withSearchPath(dsn, schema string) string {
+	if strings.Contains(dsn, "?") {
+		return dsn + "&search_path=" + schema
+	}
+
+	return dsn + "?search_path=" + schema
+}
@@ -0,0 +1,179 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migrations are applied in order and recorded in schema_migrations, so
+// existing entries must never be edited - append a new one instead.
+var migrations = []string{
+	`CREATE TABLE targets (
+		id          TEXT PRIMARY KEY,
+		url         TEXT NOT NULL,
+		name        TEXT NOT NULL,
+		interval_ns BIGINT NOT NULL,
+		is_active   BOOLEAN NOT NULL,
+		created_at  TIMESTAMPTZ NOT NULL
+	);
+
+	CREATE TABLE results (
+		id            TEXT NOT NULL,
+		target_id     TEXT NOT NULL,
+		status        TEXT NOT NULL,
+		status_code   INTEGER NOT NULL,
+		response_time BIGINT NOT NULL,
+		checked_at    TIMESTAMPTZ NOT NULL,
+		error         TEXT,
+		PRIMARY KEY (id, checked_at)
+	) PARTITION BY RANGE (checked_at);
+
+	CREATE INDEX idx_results_target_checked_at ON results (target_id, checked_at);
+
+	CREATE TABLE alerts (
+		id          TEXT PRIMARY KEY,
+		target_id   TEXT NOT NULL,
+		type        TEXT NOT NULL,
+		message     TEXT NOT NULL,
+		created_at  TIMESTAMPTZ NOT NULL,
+		resolved_at TIMESTAMPTZ,
+		is_resolved BOOLEAN NOT NULL
+	);
+
+	CREATE INDEX idx_alerts_target_created_at ON alerts (target_id, created_at);`,
+
+	`CREATE TABLE rollups (
+		target_id         TEXT NOT NULL,
+		resolution        TEXT NOT NULL,
+		bucket_start      TIMESTAMPTZ NOT NULL,
+		total_checks      INTEGER NOT NULL,
+		status_counts     JSONB NOT NULL,
+		min_response_time BIGINT NOT NULL,
+		avg_response_time BIGINT NOT NULL,
+		max_response_time BIGINT NOT NULL,
+		p50_response_time BIGINT NOT NULL,
+		p95_response_time BIGINT NOT NULL,
+		p99_response_time BIGINT NOT NULL,
+		uptime_percent    DOUBLE PRECISION NOT NULL,
+		PRIMARY KEY (target_id, resolution, bucket_start)
+	);`,
+
+	`ALTER TABLE targets ADD COLUMN http_check JSONB;`,
+
+	`ALTER TABLE results ADD COLUMN timings JSONB;
+	ALTER TABLE rollups ADD COLUMN phase_timings JSONB NOT NULL DEFAULT '{}';`,
+
+	`ALTER TABLE targets ADD COLUMN check_policy JSONB;`,
+
+	`ALTER TABLE results ADD COLUMN error_kind TEXT NOT NULL DEFAULT '';`,
+
+	`ALTER TABLE targets ADD COLUMN state TEXT NOT NULL DEFAULT 'UNKNOWN';
+	ALTER TABLE targets ADD COLUMN state_changed_at TIMESTAMPTZ;
+	UPDATE targets SET state_changed_at = created_at;
+	UPDATE targets SET state = 'PAUSED' WHERE NOT is_active;
+	ALTER TABLE targets ALTER COLUMN state_changed_at SET NOT NULL;
+
+	CREATE TABLE state_changes (
+		id         TEXT PRIMARY KEY,
+		target_id  TEXT NOT NULL,
+		from_state TEXT NOT NULL,
+		to_state   TEXT NOT NULL,
+		reason     TEXT NOT NULL,
+		changed_at TIMESTAMPTZ NOT NULL
+	);
+
+	CREATE INDEX idx_state_changes_target_changed_at ON state_changes (target_id, changed_at);`,
+
+	`ALTER TABLE targets ADD COLUMN latency_threshold JSONB;`,
+
+	`CREATE TABLE outbox (
+		id              TEXT PRIMARY KEY,
+		channel         TEXT NOT NULL,
+		notification    JSONB NOT NULL,
+		attempts        INTEGER NOT NULL,
+		next_attempt_at TIMESTAMPTZ NOT NULL,
+		last_error      TEXT NOT NULL,
+		created_at      TIMESTAMPTZ NOT NULL,
+		dead_at         TIMESTAMPTZ
+	);
+
+	CREATE INDEX idx_outbox_next_attempt_at ON outbox (next_attempt_at);`,
+
+	`ALTER TABLE targets ADD COLUMN alert_emails JSONB;`,
+
+	`ALTER TABLE alerts ADD COLUMN acknowledged_by TEXT NOT NULL DEFAULT '';
+	ALTER TABLE alerts ADD COLUMN acknowledged_at TIMESTAMPTZ;
+	ALTER TABLE alerts ADD COLUMN snoozed_until TIMESTAMPTZ;
+	ALTER TABLE alerts ADD COLUMN assignee TEXT NOT NULL DEFAULT '';
+	ALTER TABLE alerts ADD COLUMN notified_at TIMESTAMPTZ;
+	UPDATE alerts SET notified_at = created_at;
+	ALTER TABLE alerts ALTER COLUMN notified_at SET NOT NULL;
+
+	CREATE INDEX idx_alerts_open_created_at ON alerts (created_at) WHERE NOT is_resolved;`,
+
+	`CREATE TABLE escalation_policies (
+		id         TEXT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		steps      JSONB NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL
+	);
+
+	ALTER TABLE targets ADD COLUMN escalation_policy_id TEXT NOT NULL DEFAULT '';
+	ALTER TABLE alerts ADD COLUMN escalation_step INTEGER NOT NULL DEFAULT 0;`,
+
+	`CREATE TABLE maintenance_windows (
+		id          TEXT PRIMARY KEY,
+		name        TEXT NOT NULL,
+		starts_at   TIMESTAMPTZ NOT NULL,
+		duration_ns BIGINT NOT NULL,
+		schedule    TEXT NOT NULL,
+		location    TEXT NOT NULL,
+		target_ids  JSONB,
+		tags        JSONB,
+		created_at  TIMESTAMPTZ NOT NULL
+	);
+
+	ALTER TABLE targets ADD COLUMN tags JSONB;
+	ALTER TABLE results ADD COLUMN in_maintenance BOOLEAN NOT NULL DEFAULT FALSE;`,
+
+	`ALTER TABLE outbox ADD COLUMN alert_id TEXT NOT NULL DEFAULT '';
+	UPDATE outbox SET alert_id = COALESCE(notification->'Alert'->>'ID', '');
+
+	CREATE INDEX idx_outbox_alert_channel ON outbox (alert_id, channel, created_at);`,
+}
+
+const migrationLockID = 7240518
+
+func Migrate(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Serialise migrations between server instances starting at the same time.
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`); err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
+
+	var version int
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version); err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+
+	for i := version; i < len(migrations); i++ {
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			return fmt.Errorf("applying migration %d: %w", i+1, err)
+		}
+
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES ($1)`, i+1); err != nil {
+			return fmt.Errorf("applying migration %d: %w", i+1, err)
+		}
+	}
+
+	return tx.Commit()
+}
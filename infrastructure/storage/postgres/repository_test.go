@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+	"github.com/karoljaro/go-uptime-monitor/infrastructure/storage/storagetest"
+)
+
+func TestTargetRepository(t *testing.T) {
+	storagetest.RunTargetRepositoryTests(t, func(t *testing.T) domain.TargetRepository {
+		return NewTargetRepository(openTestDB(t))
+	})
+}
+
+func TestResultRepository(t *testing.T) {
+	storagetest.RunResultRepositoryTests(t, func(t *testing.T) domain.ResultRepository {
+		return NewResultRepository(openTestDB(t))
+	})
+}
+
+func TestResultRepository_Query(t *testing.T) {
+	storagetest.RunResultQueryTests(t, func(t *testing.T) domain.ResultRepository {
+		return NewResultRepository(openTestDB(t))
+	})
+}
+
+func TestStateChangeRepository(t *testing.T) {
+	storagetest.RunStateChangeRepositoryTests(t, func(t *testing.T) domain.StateChangeRepository {
+		return NewStateChangeRepository(openTestDB(t))
+	})
+}
+
+func TestAlertRepository(t *testing.T) {
+	storagetest.RunAlertRepositoryTests(t, func(t *testing.T) domain.AlertRepository {
+		return NewAlertRepository(openTestDB(t))
+	})
+}
+
+func TestMaintenanceWindowRepository(t *testing.T) {
+	storagetest.RunMaintenanceWindowRepositoryTests(t, func(t *testing.T) domain.MaintenanceWindowRepository {
+		return NewMaintenanceWindowRepository(openTestDB(t))
+	})
+}
+
+func TestEscalationPolicyRepository(t *testing.T) {
+	storagetest.RunEscalationPolicyRepositoryTests(t, func(t *testing.T) domain.EscalationPolicyRepository {
+		return NewEscalationPolicyRepository(openTestDB(t))
+	})
+}
+
+func TestOutboxRepository(t *testing.T) {
+	storagetest.RunOutboxRepositoryTests(t, func(t *testing.T) (domain.OutboxRepository, domain.AlertRepository) {
+		db := openTestDB(t)
+		return NewOutboxRepository(db), NewAlertRepository(db)
+	})
+}
+
+func TestResultRepository_Partitions(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewResultRepository(db)
+
+	months := []time.Time{
+		time.Date(2025, 1, 31, 23, 59, 0, 0, time.UTC),
+		time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC),
+	}
+
+	for i, checkedAt := range months {
+		result := domain.NewResult(string(rune('a'+i)), "target-1", "OK", 200, time.Millisecond)
+		result.CheckedAt = checkedAt
+
+		if err := repo.Save(result); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	var partitions int
+	err := db.QueryRow(`SELECT count(*) FROM pg_inherits WHERE inhparent = 'results'::regclass`).Scan(&partitions)
+	if err != nil {
+		t.Fatalf("failed to count partitions: %v", err)
+	}
+
+	if partitions != 3 {
+		t.Errorf("expected 3 monthly partitions, got %d", partitions)
+	}
+
+	found, err := repo.FindByTargetID("target-1")
+	if err != nil {
+		t.Fatalf("expected results, got %v", err)
+	}
+
+	if len(found) != 3 {
+		t.Errorf("expected 3 results across partitions, got %d", len(found))
+	}
+
+	// A fresh repository must cope with partitions created by another instance.
+	other := NewResultRepository(db)
+	result := domain.NewResult("d", "target-1", "OK", 200, time.Millisecond)
+	result.CheckedAt = months[0].Add(-time.Hour)
+
+	if err := other.Save(result); err != nil {
+		t.Errorf("expected no error saving into existing partition, got %v", err)
+	}
+}
+
+func TestRollupRepository(t *testing.T) {
+	storagetest.RunRollupRepositoryTests(t, func(t *testing.T) domain.RollupRepository {
+		return NewRollupRepository(openTestDB(t))
+	})
+}
@@ -0,0 +1,170 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migrations are applied in order and tracked with PRAGMA user_version, so
+// existing entries must never be edited - append a new one instead.
+var migrations = []string{
+	`CREATE TABLE targets (
+		id         TEXT PRIMARY KEY,
+		url        TEXT NOT NULL,
+		name       TEXT NOT NULL,
+		interval   INTEGER NOT NULL,
+		is_active  INTEGER NOT NULL,
+		created_at INTEGER NOT NULL
+	);
+
+	CREATE TABLE results (
+		id            TEXT PRIMARY KEY,
+		target_id     TEXT NOT NULL,
+		status        TEXT NOT NULL,
+		status_code   INTEGER NOT NULL,
+		response_time INTEGER NOT NULL,
+		checked_at    INTEGER NOT NULL,
+		error         TEXT
+	);
+
+	CREATE INDEX idx_results_target_checked_at ON results (target_id, checked_at);
+
+	CREATE TABLE alerts (
+		id          TEXT PRIMARY KEY,
+		target_id   TEXT NOT NULL,
+		type        TEXT NOT NULL,
+		message     TEXT NOT NULL,
+		created_at  INTEGER NOT NULL,
+		resolved_at INTEGER,
+		is_resolved INTEGER NOT NULL
+	);
+
+	CREATE INDEX idx_alerts_target_created_at ON alerts (target_id, created_at);`,
+
+	`CREATE TABLE rollups (
+		target_id         TEXT NOT NULL,
+		resolution        TEXT NOT NULL,
+		bucket_start      INTEGER NOT NULL,
+		total_checks      INTEGER NOT NULL,
+		status_counts     TEXT NOT NULL,
+		min_response_time INTEGER NOT NULL,
+		avg_response_time INTEGER NOT NULL,
+		max_response_time INTEGER NOT NULL,
+		p50_response_time INTEGER NOT NULL,
+		p95_response_time INTEGER NOT NULL,
+		p99_response_time INTEGER NOT NULL,
+		uptime_percent    REAL NOT NULL,
+		PRIMARY KEY (target_id, resolution, bucket_start)
+	);`,
+
+	`ALTER TABLE targets ADD COLUMN http_check TEXT;`,
+
+	`ALTER TABLE results ADD COLUMN timings TEXT;
+	ALTER TABLE rollups ADD COLUMN phase_timings TEXT NOT NULL DEFAULT '{}';`,
+
+	`ALTER TABLE targets ADD COLUMN check_policy TEXT;`,
+
+	`ALTER TABLE results ADD COLUMN error_kind TEXT NOT NULL DEFAULT '';`,
+
+	`ALTER TABLE targets ADD COLUMN state TEXT NOT NULL DEFAULT 'UNKNOWN';
+	ALTER TABLE targets ADD COLUMN state_changed_at INTEGER NOT NULL DEFAULT 0;
+	UPDATE targets SET state_changed_at = created_at;
+	UPDATE targets SET state = 'PAUSED' WHERE is_active = 0;
+
+	CREATE TABLE state_changes (
+		id         TEXT PRIMARY KEY,
+		target_id  TEXT NOT NULL,
+		from_state TEXT NOT NULL,
+		to_state   TEXT NOT NULL,
+		reason     TEXT NOT NULL,
+		changed_at INTEGER NOT NULL
+	);
+
+	CREATE INDEX idx_state_changes_target_changed_at ON state_changes (target_id, changed_at);`,
+
+	`ALTER TABLE targets ADD COLUMN latency_threshold TEXT;`,
+
+	`CREATE TABLE outbox (
+		id              TEXT PRIMARY KEY,
+		channel         TEXT NOT NULL,
+		notification    TEXT NOT NULL,
+		attempts        INTEGER NOT NULL,
+		next_attempt_at INTEGER NOT NULL,
+		last_error      TEXT NOT NULL,
+		created_at      INTEGER NOT NULL,
+		dead_at         INTEGER
+	);
+
+	CREATE INDEX idx_outbox_next_attempt_at ON outbox (next_attempt_at);`,
+
+	`ALTER TABLE targets ADD COLUMN alert_emails TEXT;`,
+
+	`ALTER TABLE alerts ADD COLUMN acknowledged_by TEXT NOT NULL DEFAULT '';
+	ALTER TABLE alerts ADD COLUMN acknowledged_at INTEGER;
+	ALTER TABLE alerts ADD COLUMN snoozed_until INTEGER;
+	ALTER TABLE alerts ADD COLUMN assignee TEXT NOT NULL DEFAULT '';
+	ALTER TABLE alerts ADD COLUMN notified_at INTEGER NOT NULL DEFAULT 0;
+	UPDATE alerts SET notified_at = created_at;
+
+	CREATE INDEX idx_alerts_open_created_at ON alerts (created_at) WHERE is_resolved = 0;`,
+
+	`CREATE TABLE escalation_policies (
+		id         TEXT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		steps      TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	);
+
+	ALTER TABLE targets ADD COLUMN escalation_policy_id TEXT NOT NULL DEFAULT '';
+	ALTER TABLE alerts ADD COLUMN escalation_step INTEGER NOT NULL DEFAULT 0;`,
+
+	`CREATE TABLE maintenance_windows (
+		id         TEXT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		starts_at  INTEGER NOT NULL,
+		duration   INTEGER NOT NULL,
+		schedule   TEXT NOT NULL,
+		location   TEXT NOT NULL,
+		target_ids TEXT,
+		tags       TEXT,
+		created_at INTEGER NOT NULL
+	);
+
+	ALTER TABLE targets ADD COLUMN tags TEXT;
+	ALTER TABLE results ADD COLUMN in_maintenance INTEGER NOT NULL DEFAULT 0;`,
+
+	`ALTER TABLE outbox ADD COLUMN alert_id TEXT NOT NULL DEFAULT '';
+	UPDATE outbox SET alert_id = COALESCE(json_extract(notification, '$.Alert.ID'), '');
+
+	CREATE INDEX idx_outbox_alert_channel ON outbox (alert_id, channel, created_at);`,
+}
+
+func Migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("applying migration %d: %w", i+1, err)
+		}
+
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("applying migration %d: %w", i+1, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,1355 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// execer is a *sql.DB or a *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// ========== [TARGET] ==========
+
+const targetColumns = `id, url, name, interval, is_active, created_at, http_check, check_policy, state, state_changed_at, latency_threshold, alert_emails, escalation_policy_id, tags`
+
+type TargetRepository struct {
+	db *sql.DB
+}
+
+func NewTargetRepository(db *sql.DB) *TargetRepository {
+	return &TargetRepository{db: db}
+}
+
+func (r *TargetRepository) Save(target *domain.Target) error {
+	httpCheck, err := nullJSON(target.HTTP)
+	if err != nil {
+		return err
+	}
+
+	policy, err := nullJSON(target.Policy)
+	if err != nil {
+		return err
+	}
+
+	latency, err := nullJSON(target.Latency)
+	if err != nil {
+		return err
+	}
+
+	alertEmails, err := nullList(target.AlertEmails)
+	if err != nil {
+		return err
+	}
+
+	tags, err := nullList(target.Tags)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(
+		`INSERT INTO targets (`+targetColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			url = excluded.url,
+			name = excluded.name,
+			interval = excluded.interval,
+			is_active = excluded.is_active,
+			created_at = excluded.created_at,
+			http_check = excluded.http_check,
+			check_policy = excluded.check_policy,
+			state = excluded.state,
+			state_changed_at = excluded.state_changed_at,
+			latency_threshold = excluded.latency_threshold,
+			alert_emails = excluded.alert_emails,
+			escalation_policy_id = excluded.escalation_policy_id,
+			tags = excluded.tags`,
+		target.ID,
+		target.URL,
+		target.Name,
+		int64(target.Interval),
+		target.IsActive,
+		target.CreatedAt.UnixNano(),
+		httpCheck,
+		policy,
+		target.State,
+		target.StateChangedAt.UnixNano(),
+		latency,
+		alertEmails,
+		target.EscalationPolicyID,
+		tags,
+	)
+
+	return err
+}
+
+func (r *TargetRepository) FindByID(id string) (*domain.Target, error) {
+	row := r.db.QueryRow(`SELECT `+targetColumns+` FROM targets WHERE id = ?`, id)
+
+	target, err := scanTarget(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("target with id: %s %w", id, domain.ErrNotFound)
+	}
+
+	return target, err
+}
+
+func (r *TargetRepository) GetAll() ([]*domain.Target, error) {
+	rows, err := r.db.Query(`SELECT ` + targetColumns + ` FROM targets ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	targets := make([]*domain.Target, 0)
+
+	for rows.Next() {
+		target, err := scanTarget(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		targets = append(targets, target)
+	}
+
+	return targets, rows.Err()
+}
+
+func (r *TargetRepository) Delete(id string) error {
+	res, err := r.db.Exec(`DELETE FROM targets WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res, fmt.Errorf("target with id: %s %w", id, domain.ErrNotFound))
+}
+
+func (r *TargetRepository) Update(target *domain.Target) error {
+	httpCheck, err := nullJSON(target.HTTP)
+	if err != nil {
+		return err
+	}
+
+	policy, err := nullJSON(target.Policy)
+	if err != nil {
+		return err
+	}
+
+	latency, err := nullJSON(target.Latency)
+	if err != nil {
+		return err
+	}
+
+	alertEmails, err := nullList(target.AlertEmails)
+	if err != nil {
+		return err
+	}
+
+	tags, err := nullList(target.Tags)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.db.Exec(
+		`UPDATE targets SET url = ?, name = ?, interval = ?, is_active = ?, created_at = ?, http_check = ?, check_policy = ?,
+			state = ?, state_changed_at = ?, latency_threshold = ?, alert_emails = ?,
+			escalation_policy_id = ?, tags = ? WHERE id = ?`,
+		target.URL,
+		target.Name,
+		int64(target.Interval),
+		target.IsActive,
+		target.CreatedAt.UnixNano(),
+		httpCheck,
+		policy,
+		target.State,
+		target.StateChangedAt.UnixNano(),
+		latency,
+		alertEmails,
+		target.EscalationPolicyID,
+		tags,
+		target.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res, fmt.Errorf("target with id: %s %w", target.ID, domain.ErrNotFound))
+}
+
+func (r *TargetRepository) ChangeState(change *domain.StateChange) (bool, error) {
+	var active sql.NullBool
+	active.Bool, active.Valid = change.Activity()
+
+	res, err := r.db.Exec(
+		`UPDATE targets SET state = ?, state_changed_at = ?, is_active = COALESCE(?, is_active) WHERE id = ? AND COALESCE(NULLIF(state, ''), 'UNKNOWN') = ?`,
+		change.To,
+		change.ChangedAt.UnixNano(),
+		active,
+		change.TargetID,
+		change.From,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *TargetRepository) SetEscalationPolicy(id, policyID string) error {
+	res, err := r.db.Exec(`UPDATE targets SET escalation_policy_id = ? WHERE id = ?`, policyID, id)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res, fmt.Errorf("target with id: %s %w", id, domain.ErrNotFound))
+}
+
+func scanTarget(row scanner) (*domain.Target, error) {
+	var (
+		target         domain.Target
+		interval       int64
+		createdAt      int64
+		httpCheck      sql.NullString
+		policy         sql.NullString
+		stateChangedAt int64
+		latency        sql.NullString
+		emails         sql.NullString
+		tags           sql.NullString
+	)
+
+	err := row.Scan(&target.ID, &target.URL, &target.Name, &interval, &target.IsActive, &createdAt, &httpCheck, &policy,
+		&target.State, &stateChangedAt, &latency, &emails, &target.EscalationPolicyID, &tags)
+	if err != nil {
+		return nil, err
+	}
+
+	target.Interval = time.Duration(interval)
+	target.CreatedAt = time.Unix(0, createdAt)
+	target.StateChangedAt = time.Unix(0, stateChangedAt)
+
+	if httpCheck.Valid {
+		if err := json.Unmarshal([]byte(httpCheck.String), &target.HTTP); err != nil {
+			return nil, fmt.Errorf("decoding HTTP check of target %s: %w", target.ID, err)
+		}
+	}
+
+	if policy.Valid {
+		if err := json.Unmarshal([]byte(policy.String), &target.Policy); err != nil {
+			return nil, fmt.Errorf("decoding check policy of target %s: %w", target.ID, err)
+		}
+	}
+
+	if latency.Valid {
+		if err := json.Unmarshal([]byte(latency.String), &target.Latency); err != nil {
+			return nil, fmt.Errorf("decoding latency threshold of target %s: %w", target.ID, err)
+		}
+	}
+
+	if emails.Valid {
+		if err := json.Unmarshal([]byte(emails.String), &target.AlertEmails); err != nil {
+			return nil, fmt.Errorf("decoding alert emails of target %s: %w", target.ID, err)
+		}
+	}
+
+	if tags.Valid {
+		if err := json.Unmarshal([]byte(tags.String), &target.Tags); err != nil {
+			return nil, fmt.Errorf("decoding tags of target %s: %w", target.ID, err)
+		}
+	}
+
+	return &target, nil
+}
+
+// nullList encodes v as a JSON array, or NULL when it is empty.
+func nullList[T any](v []T) (any, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+
+	return nullJSON(&v)
+}
+
+// nullJSON encodes v as JSON, or NULL when it is nil.
+func nullJSON[T any](v *T) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+// ========== [STATE CHANGE] ==========
+
+const stateChangeColumns = `id, target_id, from_state, to_state, reason, changed_at`
+
+type StateChangeRepository struct {
+	db *sql.DB
+}
+
+func NewStateChangeRepository(db *sql.DB) *StateChangeRepository {
+	return &StateChangeRepository{db: db}
+}
+
+func (r *StateChangeRepository) Save(change *domain.StateChange) error {
+	_, err := r.db.Exec(
+		`INSERT INTO state_changes (`+stateChangeColumns+`) VALUES (?, ?, ?, ?, ?, ?)`,
+		change.ID,
+		change.TargetID,
+		change.From,
+		change.To,
+		change.Reason,
+		change.ChangedAt.UnixNano(),
+	)
+
+	return err
+}
+
+func (r *StateChangeRepository) FindByTargetID(targetID string) ([]*domain.StateChange, error) {
+	rows, err := r.db.Query(
+		`SELECT `+stateChangeColumns+` FROM state_changes WHERE target_id = ? ORDER BY changed_at, rowid`,
+		targetID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := make([]*domain.StateChange, 0)
+
+	for rows.Next() {
+		var (
+			change    domain.StateChange
+			changedAt int64
+		)
+
+		if err := rows.Scan(&change.ID, &change.TargetID, &change.From, &change.To, &change.Reason, &changedAt); err != nil {
+			return nil, err
+		}
+
+		change.ChangedAt = time.Unix(0, changedAt)
+		changes = append(changes, &change)
+	}
+
+	return changes, rows.Err()
+}
+
+// ========== [ALERT] ==========
+
+const alertColumns = `id, target_id, type, message, created_at, resolved_at, is_resolved, acknowledged_by, acknowledged_at, snoozed_until, assignee, notified_at, escalation_step`
+
+type AlertRepository struct {
+	db *sql.DB
+}
+
+func NewAlertRepository(db *sql.DB) *AlertRepository {
+	return &AlertRepository{db: db}
+}
+
+func (r *AlertRepository) Save(alert *domain.Alert) error {
+	return saveAlert(r.db, alert)
+}
+
+func saveAlert(db execer, alert *domain.Alert) error {
+	_, err := db.Exec(
+		`INSERT INTO alerts (`+alertColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		alert.ID,
+		alert.TargetID,
+		alert.Type,
+		alert.Message,
+		alert.CreatedAt.UnixNano(),
+		nullTime(alert.ResolvedAt),
+		alert.IsResolved,
+		alert.AcknowledgedBy,
+		nullTime(alert.AcknowledgedAt),
+		nullTime(alert.SnoozedUntil),
+		alert.Assignee,
+		alert.NotifiedAt.UnixNano(),
+		alert.EscalationStep,
+	)
+
+	return err
+}
+
+func (r *AlertRepository) FindByID(id string) (*domain.Alert, error) {
+	alert, err := scanAlert(r.db.QueryRow(`SELECT `+alertColumns+` FROM alerts WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("alert with id: %s %w", id, domain.ErrNotFound)
+	}
+
+	return alert, err
+}
+
+func (r *AlertRepository) FindByTargetID(targetID string) ([]*domain.Alert, error) {
+	alerts, err := r.queryAlerts(
+		`SELECT `+alertColumns+` FROM alerts WHERE target_id = ? ORDER BY created_at, rowid`,
+		targetID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(alerts) == 0 {
+		return nil, fmt.Errorf("alerts with targetID: %s %w", targetID, domain.ErrNotFound)
+	}
+
+	return alerts, nil
+}
+
+func (r *AlertRepository) GetUnresolvedByTargetID(targetID string) ([]*domain.Alert, error) {
+	return r.queryAlerts(
+		`SELECT `+alertColumns+` FROM alerts WHERE target_id = ? AND is_resolved = 0 ORDER BY created_at, rowid`,
+		targetID,
+	)
+}
+
+func (r *AlertRepository) GetUnacknowledged() ([]*domain.Alert, error) {
+	return r.queryAlerts(
+		`SELECT ` + alertColumns + ` FROM alerts WHERE is_resolved = 0 AND acknowledged_at IS NULL ORDER BY created_at, rowid`,
+	)
+}
+
+func (r *AlertRepository) Update(alert *domain.Alert) error {
+	return updateAlert(r.db, alert)
+}
+
+func (r *AlertRepository) Remind(id string, at time.Time) (bool, error) {
+	return execChanged(r.db, remindAlertQuery, at.UnixNano(), id, at.UnixNano())
+}
+
+func (r *AlertRepository) Resolve(id string, at time.Time) (bool, error) {
+	return execChanged(r.db, resolveAlertQuery, at.UnixNano(), id)
+}
+
+func (r *AlertRepository) Acknowledge(id, by string, at time.Time) error {
+	return r.change(id, `UPDATE alerts SET acknowledged_by = ?, acknowledged_at = ? WHERE id = ? AND is_resolved = 0`, by, at.UnixNano(), id)
+}
+
+func (r *AlertRepository) Snooze(id string, until time.Time) error {
+	return r.change(id, `UPDATE alerts SET snoozed_until = ? WHERE id = ? AND is_resolved = 0`, until.UnixNano(), id)
+}
+
+func (r *AlertRepository) Assign(id, assignee string) error {
+	return r.change(id, `UPDATE alerts SET assignee = ? WHERE id = ? AND is_resolved = 0`, assignee, id)
+}
+
+// change runs an update of alert id that skips resolved alerts, and tells
+// why when it changed nothing.
+func (r *AlertRepository) change(id, query string, args ...any) error {
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+
+	if _, err := r.FindByID(id); err != nil {
+		return err
+	}
+
+	return domain.ErrAlertResolved
+}
+
+const (
+	// remindAlertQuery records a reminder about an alert that is still
+	// open, unacknowledged and not snoozed.
+	remindAlertQuery  = `UPDATE alerts SET notified_at = ? WHERE id = ? AND is_resolved = 0 AND acknowledged_at IS NULL AND (snoozed_until IS NULL OR snoozed_until <= ?)`
+	resolveAlertQuery = `UPDATE alerts SET is_resolved = 1, resolved_at = ? WHERE id = ? AND is_resolved = 0`
+)
+
+// execChanged runs a conditional update and reports whether it matched.
+func execChanged(db execer, query string, args ...any) (bool, error) {
+	res, err := db.Exec(query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func updateAlert(db execer, alert *domain.Alert) error {
+	res, err := db.Exec(
+		`UPDATE alerts SET target_id = ?, type = ?, message = ?, created_at = ?, resolved_at = ?, is_resolved = ?,
+			acknowledged_by = ?, acknowledged_at = ?, snoozed_until = ?, assignee = ?, notified_at = ?, escalation_step = ? WHERE id = ?`,
+		alert.TargetID,
+		alert.Type,
+		alert.Message,
+		alert.CreatedAt.UnixNano(),
+		nullTime(alert.ResolvedAt),
+		alert.IsResolved,
+		alert.AcknowledgedBy,
+		nullTime(alert.AcknowledgedAt),
+		nullTime(alert.SnoozedUntil),
+		alert.Assignee,
+		alert.NotifiedAt.UnixNano(),
+		alert.EscalationStep,
+		alert.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res, fmt.Errorf("alert with id: %s %w", alert.ID, domain.ErrNotFound))
+}
+
+func (r *AlertRepository) queryAlerts(query string, args ...any) ([]*domain.Alert, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := make([]*domain.Alert, 0)
+
+	for rows.Next() {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, rows.Err()
+}
+
+func scanAlert(row scanner) (*domain.Alert, error) {
+	var (
+		alert          domain.Alert
+		createdAt      int64
+		resolvedAt     sql.NullInt64
+		acknowledgedAt sql.NullInt64
+		snoozedUntil   sql.NullInt64
+		notifiedAt     int64
+	)
+
+	err := row.Scan(
+		&alert.ID,
+		&alert.TargetID,
+		&alert.Type,
+		&alert.Message,
+		&createdAt,
+		&resolvedAt,
+		&alert.IsResolved,
+		&alert.AcknowledgedBy,
+		&acknowledgedAt,
+		&snoozedUntil,
+		&alert.Assignee,
+		&notifiedAt,
+		&alert.EscalationStep,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	alert.CreatedAt = time.Unix(0, createdAt)
+	alert.ResolvedAt = timePtr(resolvedAt)
+	alert.AcknowledgedAt = timePtr(acknowledgedAt)
+	alert.SnoozedUntil = timePtr(snoozedUntil)
+	alert.NotifiedAt = time.Unix(0, notifiedAt)
+
+	return &alert, nil
+}
+
+// ========== [ESCALATION POLICY] ==========
+
+const escalationPolicyColumns = `id, name, steps, created_at`
+
+type EscalationPolicyRepository struct {
+	db *sql.DB
+}
+
+func NewEscalationPolicyRepository(db *sql.DB) *EscalationPolicyRepository {
+	return &EscalationPolicyRepository{db: db}
+}
+
+func (r *EscalationPolicyRepository) Save(policy *domain.EscalationPolicy) error {
+	steps, err := json.Marshal(policy.Steps)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(
+		`INSERT INTO escalation_policies (`+escalationPolicyColumns+`) VALUES (?, ?, ?, ?)`,
+		policy.ID,
+		policy.Name,
+		string(steps),
+		policy.CreatedAt.UnixNano(),
+	)
+
+	return err
+}
+
+func (r *EscalationPolicyRepository) FindByID(id string) (*domain.EscalationPolicy, error) {
+	row := r.db.QueryRow(`SELECT `+escalationPolicyColumns+` FROM escalation_policies WHERE id = ?`, id)
+
+	policy, err := scanEscalationPolicy(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("escalation policy with id: %s %w", id, domain.ErrNotFound)
+	}
+
+	return policy, err
+}
+
+func (r *EscalationPolicyRepository) GetAll() ([]*domain.EscalationPolicy, error) {
+	rows, err := r.db.Query(`SELECT ` + escalationPolicyColumns + ` FROM escalation_policies ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	policies := make([]*domain.EscalationPolicy, 0)
+
+	for rows.Next() {
+		policy, err := scanEscalationPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		policies = append(policies, policy)
+	}
+
+	return policies, rows.Err()
+}
+
+func (r *EscalationPolicyRepository) Delete(id string) error {
+	res, err := r.db.Exec(`DELETE FROM escalation_policies WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res, fmt.Errorf("escalation policy with id: %s %w", id, domain.ErrNotFound))
+}
+
+func scanEscalationPolicy(row scanner) (*domain.EscalationPolicy, error) {
+	var (
+		policy    domain.EscalationPolicy
+		steps     string
+		createdAt int64
+	)
+
+	if err := row.Scan(&policy.ID, &policy.Name, &steps, &createdAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(steps), &policy.Steps); err != nil {
+		return nil, fmt.Errorf("decoding steps of escalation policy %s: %w", policy.ID, err)
+	}
+
+	policy.CreatedAt = time.Unix(0, createdAt)
+
+	return &policy, nil
+}
+
+// ========== [MAINTENANCE WINDOW] ==========
+
+const maintenanceWindowColumns = `id, name, starts_at, duration, schedule, location, target_ids, tags, created_at`
+
+type MaintenanceWindowRepository struct {
+	db *sql.DB
+}
+
+func NewMaintenanceWindowRepository(db *sql.DB) *MaintenanceWindowRepository {
+	return &MaintenanceWindowRepository{db: db}
+}
+
+func (r *MaintenanceWindowRepository) Save(window *domain.MaintenanceWindow) error {
+	targetIDs, err := nullList(window.TargetIDs)
+	if err != nil {
+		return err
+	}
+
+	tags, err := nullList(window.Tags)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(
+		`INSERT INTO maintenance_windows (`+maintenanceWindowColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		window.ID,
+		window.Name,
+		window.Start.UnixNano(),
+		int64(window.Duration),
+		window.Schedule,
+		window.Location,
+		targetIDs,
+		tags,
+		window.CreatedAt.UnixNano(),
+	)
+
+	return err
+}
+
+func (r *MaintenanceWindowRepository) FindByID(id string) (*domain.MaintenanceWindow, error) {
+	row := r.db.QueryRow(`SELECT `+maintenanceWindowColumns+` FROM maintenance_windows WHERE id = ?`, id)
+
+	window, err := scanMaintenanceWindow(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("maintenance window with id: %s %w", id, domain.ErrNotFound)
+	}
+
+	return window, err
+}
+
+func (r *MaintenanceWindowRepository) GetAll() ([]*domain.MaintenanceWindow, error) {
+	rows, err := r.db.Query(`SELECT ` + maintenanceWindowColumns + ` FROM maintenance_windows ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := make([]*domain.MaintenanceWindow, 0)
+
+	for rows.Next() {
+		window, err := scanMaintenanceWindow(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		windows = append(windows, window)
+	}
+
+	return windows, rows.Err()
+}
+
+func (r *MaintenanceWindowRepository) Delete(id string) error {
+	res, err := r.db.Exec(`DELETE FROM maintenance_windows WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res, fmt.Errorf("maintenance window with id: %s %w", id, domain.ErrNotFound))
+}
+
+func scanMaintenanceWindow(row scanner) (*domain.MaintenanceWindow, error) {
+	var (
+		window    domain.MaintenanceWindow
+		start     int64
+		duration  int64
+		targetIDs sql.NullString
+		tags      sql.NullString
+		createdAt int64
+	)
+
+	err := row.Scan(&window.ID, &window.Name, &start, &duration, &window.Schedule, &window.Location, &targetIDs, &tags, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	window.Start = time.Unix(0, start)
+	window.Duration = time.Duration(duration)
+	window.CreatedAt = time.Unix(0, createdAt)
+
+	if targetIDs.Valid {
+		if err := json.Unmarshal([]byte(targetIDs.String), &window.TargetIDs); err != nil {
+			return nil, fmt.Errorf("decoding target IDs of maintenance window %s: %w", window.ID, err)
+		}
+	}
+
+	if tags.Valid {
+		if err := json.Unmarshal([]byte(tags.String), &window.Tags); err != nil {
+			return nil, fmt.Errorf("decoding tags of maintenance window %s: %w", window.ID, err)
+		}
+	}
+
+	return &window, nil
+}
+
+// ========== [OUTBOX] ==========
+
+const outboxColumns = `id, channel, notification, attempts, next_attempt_at, last_error, created_at, dead_at`
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+func (r *OutboxRepository) SaveAlert(alert *domain.Alert, messages []*domain.OutboxMessage) error {
+	return r.inTx(messages, func(tx *sql.Tx) error {
+		return saveAlert(tx, alert)
+	})
+}
+
+func (r *OutboxRepository) RemindAlert(alertID string, at time.Time, messages []*domain.OutboxMessage) (bool, error) {
+	return r.changeAlert(messages, remindAlertQuery, at.UnixNano(), alertID, at.UnixNano())
+}
+
+func (r *OutboxRepository) ResolveAlert(alertID string, at time.Time, messages []*domain.OutboxMessage) (bool, error) {
+	return r.changeAlert(messages, resolveAlertQuery, at.UnixNano(), alertID)
+}
+
+func (r *OutboxRepository) AdvanceEscalation(alertID string, from, to int, messages []*domain.OutboxMessage) (bool, error) {
+	return r.changeAlert(messages, `UPDATE alerts SET escalation_step = ? WHERE id = ? AND escalation_step = ? AND is_resolved = 0 AND acknowledged_at IS NULL`, to, alertID, from)
+}
+
+// errAlertChanged rolls back the messages about an alert that changed
+// meanwhile.
+var errAlertChanged = errors.New("alert changed meanwhile")
+
+// changeAlert runs a conditional update of an alert and queues messages with
+// it, unless the update matched no alert. It reports whether it did.
+func (r *OutboxRepository) changeAlert(messages []*domain.OutboxMessage, query string, args ...any) (bool, error) {
+	err := r.inTx(messages, func(tx *sql.Tx) error {
+		changed, err := execChanged(tx, query, args...)
+		if err == nil && !changed {
+			return errAlertChanged
+		}
+
+		return err
+	})
+	if errors.Is(err, errAlertChanged) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// inTx runs fn and enqueues messages in a single transaction.
+func (r *OutboxRepository) inTx(messages []*domain.OutboxMessage, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	for _, message := range messages {
+		notification, err := json.Marshal(message.Notification)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			`INSERT INTO outbox (`+outboxColumns+`, alert_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			message.ID,
+			message.Channel,
+			string(notification),
+			message.Attempts,
+			message.NextAttemptAt.UnixNano(),
+			message.LastError,
+			message.CreatedAt.UnixNano(),
+			nullTime(message.DeadAt),
+			message.Notification.Alert.ID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *OutboxRepository) FindByID(id string) (*domain.OutboxMessage, error) {
+	row := r.db.QueryRow(`SELECT `+outboxColumns+` FROM outbox WHERE id = ?`, id)
+
+	message, err := scanOutboxMessage(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("outbox message with id: %s %w", id, domain.ErrNotFound)
+	}
+
+	return message, err
+}
+
+func (r *OutboxRepository) Due(now time.Time, limit int) ([]*domain.OutboxMessage, error) {
+	return r.queryMessages(
+		`SELECT `+outboxColumns+` FROM outbox m WHERE dead_at IS NULL AND next_attempt_at <= ?
+			AND NOT EXISTS (
+				SELECT 1 FROM outbox e WHERE e.alert_id = m.alert_id AND e.channel = m.channel AND e.dead_at IS NULL
+					AND (e.created_at < m.created_at OR (e.created_at = m.created_at AND e.id < m.id))
+			)
+			ORDER BY next_attempt_at, id LIMIT ?`,
+		now.UnixNano(),
+		limit,
+	)
+}
+
+func (r *OutboxRepository) DeadLetters() ([]*domain.OutboxMessage, error) {
+	return r.queryMessages(`SELECT ` + outboxColumns + ` FROM outbox WHERE dead_at IS NOT NULL ORDER BY dead_at, id`)
+}
+
+func (r *OutboxRepository) Update(message *domain.OutboxMessage) error {
+	res, err := r.db.Exec(
+		`UPDATE outbox SET attempts = ?, next_attempt_at = ?, last_error = ?, dead_at = ? WHERE id = ?`,
+		message.Attempts,
+		message.NextAttemptAt.UnixNano(),
+		message.LastError,
+		nullTime(message.DeadAt),
+		message.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res, fmt.Errorf("outbox message with id: %s %w", message.ID, domain.ErrNotFound))
+}
+
+func (r *OutboxRepository) Delete(id string) error {
+	res, err := r.db.Exec(`DELETE FROM outbox WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res, fmt.Errorf("outbox message with id: %s %w", id, domain.ErrNotFound))
+}
+
+func (r *OutboxRepository) queryMessages(query string, args ...any) ([]*domain.OutboxMessage, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := make([]*domain.OutboxMessage, 0)
+
+	for rows.Next() {
+		message, err := scanOutboxMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+func scanOutboxMessage(row scanner) (*domain.OutboxMessage, error) {
+	var (
+		message       domain.OutboxMessage
+		notification  string
+		nextAttemptAt int64
+		createdAt     int64
+		deadAt        sql.NullInt64
+	)
+
+	err := row.Scan(
+		&message.ID,
+		&message.Channel,
+		&notification,
+		&message.Attempts,
+		&nextAttemptAt,
+		&message.LastError,
+		&createdAt,
+		&deadAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(notification), &message.Notification); err != nil {
+		return nil, fmt.Errorf("decoding notification: %w", err)
+	}
+
+	message.NextAttemptAt = time.Unix(0, nextAttemptAt)
+	message.CreatedAt = time.Unix(0, createdAt)
+	message.DeadAt = timePtr(deadAt)
+
+	return &message, nil
+}
+
+// ========== [RESULT] ==========
+
+const resultColumns = `id, target_id, status, status_code, response_time, checked_at, error, timings, error_kind, in_maintenance`
+
+type ResultRepository struct {
+	db *sql.DB
+}
+
+func NewResultRepository(db *sql.DB) *ResultRepository {
+	return &ResultRepository{db: db}
+}
+
+func (r *ResultRepository) Save(result *domain.Result) error {
+	timings, err := nullJSON(result.Timings)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(
+		`INSERT INTO results (`+resultColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		result.ID,
+		result.TargetID,
+		result.Status,
+		result.StatusCode,
+		int64(result.ResponseTime),
+		result.CheckedAt.UnixNano(),
+		nullError(result.Error),
+		timings,
+		result.ErrorKind,
+		result.InMaintenance,
+	)
+
+	return err
+}
+
+func (r *ResultRepository) FindByTargetID(targetID string) ([]*domain.Result, error) {
+	rows, err := r.db.Query(
+		`SELECT `+resultColumns+` FROM results WHERE target_id = ? ORDER BY checked_at, id`,
+		targetID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]*domain.Result, 0)
+
+	for rows.Next() {
+		result, err := scanResult(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("result with targetID: %s %w", targetID, domain.ErrNotFound)
+	}
+
+	return results, nil
+}
+
+func (r *ResultRepository) GetLastByTargetID(targetID string) (*domain.Result, error) {
+	row := r.db.QueryRow(
+		`SELECT `+resultColumns+` FROM results WHERE target_id = ? ORDER BY checked_at DESC, id DESC LIMIT 1`,
+		targetID,
+	)
+
+	result, err := scanResult(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("last result with targetID %s %w", targetID, domain.ErrNotFound)
+	}
+
+	return result, err
+}
+
+func (r *ResultRepository) QueryByTargetID(targetID string, query domain.ResultQuery) (*domain.ResultPage, error) {
+	conditions := []string{"target_id = ?"}
+	args := []any{targetID}
+
+	if !query.From.IsZero() {
+		conditions = append(conditions, "checked_at >= ?")
+		args = append(args, query.From.UnixNano())
+	}
+
+	if !query.To.IsZero() {
+		conditions = append(conditions, "checked_at < ?")
+		args = append(args, query.To.UnixNano())
+	}
+
+	if query.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, query.Status)
+	}
+
+	if query.Cursor != "" {
+		cursor, err := domain.DecodeResultCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		conditions = append(conditions, "(checked_at < ? OR (checked_at = ? AND id < ?))")
+		args = append(args, cursor.CheckedAt.UnixNano(), cursor.CheckedAt.UnixNano(), cursor.ID)
+	}
+
+	limit := query.EffectiveLimit()
+	args = append(args, limit+1)
+
+	rows, err := r.db.Query(
+		`SELECT `+resultColumns+` FROM results WHERE `+strings.Join(conditions, " AND ")+
+			` ORDER BY checked_at DESC, id DESC LIMIT ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &domain.ResultPage{Results: make([]*domain.Result, 0)}
+
+	for rows.Next() {
+		result, err := scanResult(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		page.Results = append(page.Results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Results) > limit {
+		page.Results = page.Results[:limit]
+		page.NextCursor = domain.NewResultCursor(page.Results[limit-1]).Encode()
+	}
+
+	return page, nil
+}
+
+func (r *ResultRepository) DeleteBefore(targetID string, before time.Time) (int, error) {
+	res, err := r.db.Exec(`DELETE FROM results WHERE target_id = ? AND checked_at < ?`, targetID, before.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func scanResult(row scanner) (*domain.Result, error) {
+	var (
+		result       domain.Result
+		responseTime int64
+		checkedAt    int64
+		errMsg       sql.NullString
+		timings      sql.NullString
+	)
+
+	err := row.Scan(
+		&result.ID,
+		&result.TargetID,
+		&result.Status,
+		&result.StatusCode,
+		&responseTime,
+		&checkedAt,
+		&errMsg,
+		&timings,
+		&result.ErrorKind,
+		&result.InMaintenance,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result.ResponseTime = time.Duration(responseTime)
+	result.CheckedAt = time.Unix(0, checkedAt)
+	if errMsg.Valid {
+		result.Error = errors.New(errMsg.String)
+	}
+
+	if timings.Valid {
+		if err := json.Unmarshal([]byte(timings.String), &result.Timings); err != nil {
+			return nil, fmt.Errorf("decoding timings of result %s: %w", result.ID, err)
+		}
+	}
+
+	return &result, nil
+}
+
+// ========== [ROLLUP] ==========
+
+const rollupColumns = `target_id, resolution, bucket_start, total_checks, status_counts,
+	min_response_time, avg_response_time, max_response_time,
+	p50_response_time, p95_response_time, p99_response_time, uptime_percent, phase_timings`
+
+type RollupRepository struct {
+	db *sql.DB
+}
+
+func NewRollupRepository(db *sql.DB) *RollupRepository {
+	return &RollupRepository{db: db}
+}
+
+func (r *RollupRepository) Save(rollup *domain.Rollup) error {
+	statusCounts, err := json.Marshal(rollup.StatusCounts)
+	if err != nil {
+		return err
+	}
+
+	phaseTimings, err := json.Marshal(rollup.PhaseTimings)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(
+		`INSERT INTO rollups (`+rollupColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (target_id, resolution, bucket_start) DO UPDATE SET
+			total_checks = excluded.total_checks,
+			status_counts = excluded.status_counts,
+			min_response_time = excluded.min_response_time,
+			avg_response_time = excluded.avg_response_time,
+			max_response_time = excluded.max_response_time,
+			p50_response_time = excluded.p50_response_time,
+			p95_response_time = excluded.p95_response_time,
+			p99_response_time = excluded.p99_response_time,
+			uptime_percent = excluded.uptime_percent,
+			phase_timings = excluded.phase_timings`,
+		rollup.TargetID,
+		string(rollup.Resolution),
+		rollup.BucketStart.UnixNano(),
+		rollup.TotalChecks,
+		string(statusCounts),
+		int64(rollup.MinResponseTime),
+		int64(rollup.AvgResponseTime),
+		int64(rollup.MaxResponseTime),
+		int64(rollup.P50ResponseTime),
+		int64(rollup.P95ResponseTime),
+		int64(rollup.P99ResponseTime),
+		rollup.UptimePercent,
+		string(phaseTimings),
+	)
+
+	return err
+}
+
+func (r *RollupRepository) FindByTargetID(targetID string, resolution domain.RollupResolution, from, to time.Time) ([]*domain.Rollup, error) {
+	rows, err := r.db.Query(
+		`SELECT `+rollupColumns+` FROM rollups
+		WHERE target_id = ? AND resolution = ? AND bucket_start >= ? AND bucket_start < ?
+		ORDER BY bucket_start`,
+		targetID,
+		string(resolution),
+		from.UnixNano(),
+		to.UnixNano(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rollups := make([]*domain.Rollup, 0)
+
+	for rows.Next() {
+		rollup, err := scanRollup(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		rollups = append(rollups, rollup)
+	}
+
+	return rollups, rows.Err()
+}
+
+func (r *RollupRepository) DeleteBefore(targetID string, resolution domain.RollupResolution, before time.Time) (int, error) {
+	res, err := r.db.Exec(
+		`DELETE FROM rollups WHERE target_id = ? AND resolution = ? AND bucket_start < ?`,
+		targetID,
+		string(resolution),
+		before.UnixNano(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func scanRollup(row scanner) (*domain.Rollup, error) {
+	var (
+		rollup       domain.Rollup
+		bucketStart  int64
+		statusCounts string
+		phaseTimings string
+		durations    [6]int64
+	)
+
+	err := row.Scan(
+		&rollup.TargetID,
+		&rollup.Resolution,
+		&bucketStart,
+		&rollup.TotalChecks,
+		&statusCounts,
+		&durations[0],
+		&durations[1],
+		&durations[2],
+		&durations[3],
+		&durations[4],
+		&durations[5],
+		&rollup.UptimePercent,
+		&phaseTimings,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(phaseTimings), &rollup.PhaseTimings); err != nil {
+		return nil, fmt.Errorf("decoding phase timings: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(statusCounts), &rollup.StatusCounts); err != nil {
+		return nil, fmt.Errorf("decoding status counts: %w", err)
+	}
+
+	rollup.BucketStart = time.Unix(0, bucketStart)
+	rollup.MinResponseTime = time.Duration(durations[0])
+	rollup.AvgResponseTime = time.Duration(durations[1])
+	rollup.MaxResponseTime = time.Duration(durations[2])
+	rollup.P50ResponseTime = time.Duration(durations[3])
+	rollup.P95ResponseTime = time.Duration(durations[4])
+	rollup.P99ResponseTime = time.Duration(durations[5])
+
+	return &rollup, nil
+}
+
+// ========== [HELPERS] ==========
+
+func expectAffected(res sql.Result, notFound error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return notFound
+	}
+
+	return nil
+}
+
+func nullTime(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+
+	return sql.NullInt64{Int64: t.UnixNano(), Valid: true}
+}
+
+func timePtr(v sql.NullInt64) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+
+	t := time.Unix(0, v.Int64)
+	return &t
+}
+
+func nullError(err error) sql.NullString {
+	if err == nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: err.Error(), Valid: true}
+}
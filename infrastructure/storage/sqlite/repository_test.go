@@ -0,0 +1,103 @@
+package sqlite
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+	"github.com/karoljaro/go-uptime-monitor/infrastructure/storage/storagetest"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := Open(filepath.Join(t.TempDir(), "uptime.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestTargetRepository(t *testing.T) {
+	storagetest.RunTargetRepositoryTests(t, func(t *testing.T) domain.TargetRepository {
+		return NewTargetRepository(openTestDB(t))
+	})
+}
+
+func TestResultRepository(t *testing.T) {
+	storagetest.RunResultRepositoryTests(t, func(t *testing.T) domain.ResultRepository {
+		return NewResultRepository(openTestDB(t))
+	})
+}
+
+func TestResultRepository_Query(t *testing.T) {
+	storagetest.RunResultQueryTests(t, func(t *testing.T) domain.ResultRepository {
+		return NewResultRepository(openTestDB(t))
+	})
+}
+
+func TestStateChangeRepository(t *testing.T) {
+	storagetest.RunStateChangeRepositoryTests(t, func(t *testing.T) domain.StateChangeRepository {
+		return NewStateChangeRepository(openTestDB(t))
+	})
+}
+
+func TestAlertRepository(t *testing.T) {
+	storagetest.RunAlertRepositoryTests(t, func(t *testing.T) domain.AlertRepository {
+		return NewAlertRepository(openTestDB(t))
+	})
+}
+
+func TestMaintenanceWindowRepository(t *testing.T) {
+	storagetest.RunMaintenanceWindowRepositoryTests(t, func(t *testing.T) domain.MaintenanceWindowRepository {
+		return NewMaintenanceWindowRepository(openTestDB(t))
+	})
+}
+
+func TestEscalationPolicyRepository(t *testing.T) {
+	storagetest.RunEscalationPolicyRepositoryTests(t, func(t *testing.T) domain.EscalationPolicyRepository {
+		return NewEscalationPolicyRepository(openTestDB(t))
+	})
+}
+
+func TestOutboxRepository(t *testing.T) {
+	storagetest.RunOutboxRepositoryTests(t, func(t *testing.T) (domain.OutboxRepository, domain.AlertRepository) {
+		db := openTestDB(t)
+		return NewOutboxRepository(db), NewAlertRepository(db)
+	})
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uptime.db")
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	db.Close()
+
+	db, err = Open(path)
+	if err != nil {
+		t.Fatalf("failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		t.Fatalf("failed to read schema version: %v", err)
+	}
+
+	if version != len(migrations) {
+		t.Errorf("expected schema version %d, got %d", len(migrations), version)
+	}
+}
+
+func TestRollupRepository(t *testing.T) {
+	storagetest.RunRollupRepositoryTests(t, func(t *testing.T) domain.RollupRepository {
+		return NewRollupRepository(openTestDB(t))
+	})
+}
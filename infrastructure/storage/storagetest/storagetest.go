@@ -0,0 +1,1739 @@
+// Package storagetest holds the behavioural tests every implementation of the
+// domain repository interfaces has to pass.
+package storagetest
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+// sameInstant compares timestamps at microsecond precision, the finest
+// resolution some databases (e.g. PostgreSQL) store.
+func sameInstant(a, b time.Time) bool {
+	d := a.Sub(b)
+	return d > -time.Microsecond && d < time.Microsecond
+}
+
+func sameInstantPtr(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return sameInstant(*a, *b)
+}
+
+// ======================[TARGET]======================
+
+func RunTargetRepositoryTests(t *testing.T, newRepo func(t *testing.T) domain.TargetRepository) {
+	t.Run("SaveAndFindByID", func(t *testing.T) {
+		repo := newRepo(t)
+		target := domain.NewTarget("target-1", "https://example.com", "My API", 30*time.Second)
+		target.IsActive = false
+		target.HTTP = &domain.HTTPCheck{
+			Method:         "POST",
+			Headers:        map[string]string{"Authorization": "Bearer secret"},
+			Body:           `{"ping":true}`,
+			AcceptedStatus: []domain.StatusRange{{Min: 200, Max: 204}, {Min: 401, Max: 401}},
+			Assertions: []domain.Assertion{
+				{Type: domain.AssertionJSONPath, Path: "$.status", Value: "ok"},
+				{Type: domain.AssertionMaxSize, Size: 4096},
+			},
+		}
+		target.Policy = &domain.CheckPolicy{Retries: 2, RetryBackoff: 500 * time.Millisecond, FailureThreshold: 3, RecoveryThreshold: 2}
+		target.Latency = &domain.LatencyThreshold{Max: 2 * time.Second, P95: 800 * time.Millisecond, P95Window: 20}
+		target.State = domain.StatePaused
+		target.StateChangedAt = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		target.AlertEmails = []string{"ops@example.com", "oncall@example.com"}
+		target.EscalationPolicyID = "policy-1"
+		target.Tags = []string{"prod", "api"}
+
+		if err := repo.Save(target); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, err := repo.FindByID(target.ID)
+		if err != nil {
+			t.Fatalf("expected to find target, got error: %v", err)
+		}
+
+		assertTargetEqual(t, target, found)
+	})
+
+	t.Run("FindByIDNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+
+		found, err := repo.FindByID("nonExistent")
+
+		if found != nil {
+			t.Errorf("expected nil, got %v", found)
+		}
+
+		if !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("GetAll", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if found, err := repo.GetAll(); err != nil || len(found) != 0 {
+			t.Fatalf("expected empty list, got %d targets and error %v", len(found), err)
+		}
+
+		for _, id := range []string{"1", "2", "3"} {
+			repo.Save(domain.NewTarget(id, "https://example.com/"+id, "name-"+id, 30*time.Second))
+		}
+
+		found, err := repo.GetAll()
+		if err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+
+		if len(found) != 3 {
+			t.Errorf("expected 3 targets, got %d", len(found))
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := newRepo(t)
+		target := domain.NewTarget("target-1", "https://example.com", "My API", 30*time.Second)
+		repo.Save(target)
+
+		if err := repo.Delete(target.ID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if _, err := repo.FindByID(target.ID); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound after delete, got %v", err)
+		}
+
+		if err := repo.Delete(target.ID); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repo := newRepo(t)
+		repo.Save(domain.NewTarget("target-1", "https://example.com", "My API", 30*time.Second))
+
+		updated := domain.NewTarget("target-1", "https://example.com/posts", "Renamed", 10*time.Second)
+		updated.IsActive = false
+		updated.Transition(domain.StateDown, domain.StatusServerError, updated.CreatedAt.Add(time.Minute))
+
+		if err := repo.Update(updated); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, err := repo.FindByID("target-1")
+		if err != nil {
+			t.Fatalf("expected to find target, got error: %v", err)
+		}
+
+		assertTargetEqual(t, updated, found)
+
+		missing := domain.NewTarget("nonExistent", "https://example.com", "x", time.Second)
+		if err := repo.Update(missing); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("ChangeState", func(t *testing.T) {
+		repo := newRepo(t)
+		target := domain.NewTarget("target-1", "https://example.com", "My API", 30*time.Second)
+		repo.Save(target)
+
+		at := target.CreatedAt.Add(time.Minute)
+		down, _ := target.Transition(domain.StateDown, domain.StatusServerError, at)
+		if changed, err := repo.ChangeState(down); err != nil || !changed {
+			t.Fatalf("expected the state to change, got %t and error %v", changed, err)
+		}
+
+		paused, _ := target.Pause(at.Add(time.Minute))
+		if changed, err := repo.ChangeState(paused); err != nil || !changed {
+			t.Fatalf("expected the target to be paused, got %t and error %v", changed, err)
+		}
+
+		found, _ := repo.FindByID("target-1")
+		assertTargetEqual(t, target, found)
+
+		resumed, _ := target.Resume(at.Add(2 * time.Minute))
+		if changed, err := repo.ChangeState(resumed); err != nil || !changed {
+			t.Fatalf("expected the target to be resumed, got %t and error %v", changed, err)
+		}
+
+		found, _ = repo.FindByID("target-1")
+		assertTargetEqual(t, target, found)
+	})
+
+	t.Run("ChangeStateLeavesActivityAlone", func(t *testing.T) {
+		repo := newRepo(t)
+		target := domain.NewTarget("target-1", "https://example.com", "My API", 30*time.Second)
+		target.IsActive = false
+		repo.Save(target)
+
+		up, _ := target.Transition(domain.StateUp, domain.StatusOK, target.CreatedAt.Add(time.Minute))
+		target.IsActive = true
+		repo.ChangeState(up)
+
+		found, _ := repo.FindByID("target-1")
+		if found.IsActive || found.State != domain.StateUp {
+			t.Errorf("expected an inactive UP target, got active %t in state %s", found.IsActive, found.State)
+		}
+	})
+
+	t.Run("ChangeStateFromStaleState", func(t *testing.T) {
+		repo := newRepo(t)
+		target := domain.NewTarget("target-1", "https://example.com", "My API", 30*time.Second)
+		repo.Save(target)
+
+		checked := *target
+		down, _ := checked.Transition(domain.StateDown, domain.StatusServerError, target.CreatedAt.Add(time.Minute))
+
+		paused, _ := target.Pause(target.CreatedAt.Add(30 * time.Second))
+		repo.ChangeState(paused)
+
+		changed, err := repo.ChangeState(down)
+		if err != nil || changed {
+			t.Fatalf("expected the stale change to be dropped, got %t and error %v", changed, err)
+		}
+
+		found, _ := repo.FindByID("target-1")
+		if found.State != domain.StatePaused || found.IsActive {
+			t.Errorf("expected the target to stay paused, got active %t in state %s", found.IsActive, found.State)
+		}
+
+		missing := &domain.StateChange{TargetID: "nonExistent", From: domain.StateUnknown, To: domain.StateUp}
+		if changed, err := repo.ChangeState(missing); err != nil || changed {
+			t.Errorf("expected no change of a missing target, got %t and error %v", changed, err)
+		}
+	})
+
+	t.Run("SetEscalationPolicy", func(t *testing.T) {
+		repo := newRepo(t)
+		target := domain.NewTarget("target-1", "https://example.com", "My API", 30*time.Second)
+		repo.Save(target)
+
+		if err := repo.SetEscalationPolicy("target-1", "policy-1"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		target.EscalationPolicyID = "policy-1"
+		found, _ := repo.FindByID("target-1")
+		assertTargetEqual(t, target, found)
+
+		if err := repo.SetEscalationPolicy("target-1", ""); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, _ = repo.FindByID("target-1")
+		if found.EscalationPolicyID != "" {
+			t.Errorf("expected no escalation policy, got %q", found.EscalationPolicyID)
+		}
+
+		if err := repo.SetEscalationPolicy("nonExistent", "policy-1"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func assertTargetEqual(t *testing.T, want, got *domain.Target) {
+	t.Helper()
+
+	if got.ID != want.ID {
+		t.Errorf("expected ID %s, got %s", want.ID, got.ID)
+	}
+	if got.URL != want.URL {
+		t.Errorf("expected URL %s, got %s", want.URL, got.URL)
+	}
+	if got.Name != want.Name {
+		t.Errorf("expected name %s, got %s", want.Name, got.Name)
+	}
+	if got.Interval != want.Interval {
+		t.Errorf("expected interval %s, got %s", want.Interval, got.Interval)
+	}
+	if got.IsActive != want.IsActive {
+		t.Errorf("expected IsActive %t, got %t", want.IsActive, got.IsActive)
+	}
+	if !sameInstant(got.CreatedAt, want.CreatedAt) {
+		t.Errorf("expected CreatedAt %v, got %v", want.CreatedAt, got.CreatedAt)
+	}
+	if !reflect.DeepEqual(got.HTTP, want.HTTP) {
+		t.Errorf("expected HTTP check %+v, got %+v", want.HTTP, got.HTTP)
+	}
+	if !reflect.DeepEqual(got.Policy, want.Policy) {
+		t.Errorf("expected check policy %+v, got %+v", want.Policy, got.Policy)
+	}
+	if !reflect.DeepEqual(got.Latency, want.Latency) {
+		t.Errorf("expected latency threshold %+v, got %+v", want.Latency, got.Latency)
+	}
+	if got.State != want.State || !sameInstant(got.StateChangedAt, want.StateChangedAt) {
+		t.Errorf("expected state %s since %v, got %s since %v", want.State, want.StateChangedAt, got.State, got.StateChangedAt)
+	}
+	if !reflect.DeepEqual(got.AlertEmails, want.AlertEmails) {
+		t.Errorf("expected alert emails %v, got %v", want.AlertEmails, got.AlertEmails)
+	}
+	if got.EscalationPolicyID != want.EscalationPolicyID {
+		t.Errorf("expected escalation policy %q, got %q", want.EscalationPolicyID, got.EscalationPolicyID)
+	}
+	if !reflect.DeepEqual(got.Tags, want.Tags) {
+		t.Errorf("expected tags %v, got %v", want.Tags, got.Tags)
+	}
+}
+
+// ======================[STATE CHANGE]======================
+
+func RunStateChangeRepositoryTests(t *testing.T, newRepo func(t *testing.T) domain.StateChangeRepository) {
+	t.Run("SaveAndFindByTargetID", func(t *testing.T) {
+		repo := newRepo(t)
+		base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+		changes := []*domain.StateChange{
+			{ID: "change-2", TargetID: "target-1", From: domain.StateUp, To: domain.StateDown, Reason: domain.StatusServerError, ChangedAt: base.Add(time.Hour)},
+			{ID: "change-1", TargetID: "target-1", From: domain.StateUnknown, To: domain.StateUp, Reason: domain.StatusOK, ChangedAt: base},
+			{ID: "change-3", TargetID: "target-2", From: domain.StateUnknown, To: domain.StatePaused, Reason: "paused", ChangedAt: base},
+		}
+
+		for _, change := range changes {
+			if err := repo.Save(change); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		found, err := repo.FindByTargetID("target-1")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(found) != 2 {
+			t.Fatalf("expected 2 state changes, got %d", len(found))
+		}
+
+		for i, want := range []*domain.StateChange{changes[1], changes[0]} {
+			got := found[i]
+			if got.ID != want.ID || got.TargetID != want.TargetID || got.From != want.From || got.To != want.To || got.Reason != want.Reason {
+				t.Errorf("expected %+v at %d, got %+v", want, i, got)
+			}
+			if !sameInstant(got.ChangedAt, want.ChangedAt) {
+				t.Errorf("expected ChangedAt %v, got %v", want.ChangedAt, got.ChangedAt)
+			}
+		}
+	})
+
+	t.Run("FindByTargetIDEmpty", func(t *testing.T) {
+		repo := newRepo(t)
+
+		found, err := repo.FindByTargetID("nonExistent")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(found) != 0 {
+			t.Errorf("expected no state changes, got %d", len(found))
+		}
+	})
+}
+
+// ======================[RESULT]======================
+
+func RunResultRepositoryTests(t *testing.T, newRepo func(t *testing.T) domain.ResultRepository) {
+	t.Run("SaveAndGetLast", func(t *testing.T) {
+		repo := newRepo(t)
+		result := domain.NewResult("result-1", "target-1", "SERVER_ERROR", 500, 15*time.Millisecond)
+		result.Error = errors.New("internal server error")
+		result.Timings = &domain.Timings{DNS: time.Millisecond, Connect: 2 * time.Millisecond, Server: 10 * time.Millisecond, Transfer: 2 * time.Millisecond}
+
+		if err := repo.Save(result); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, err := repo.GetLastByTargetID(result.TargetID)
+		if err != nil {
+			t.Fatalf("expected to find result, got error: %v", err)
+		}
+
+		assertResultEqual(t, result, found)
+	})
+
+	t.Run("NilErrorRoundTrip", func(t *testing.T) {
+		repo := newRepo(t)
+		repo.Save(domain.NewResult("result-1", "target-1", "OK", 200, time.Millisecond))
+
+		found, err := repo.GetLastByTargetID("target-1")
+		if err != nil {
+			t.Fatalf("expected to find result, got error: %v", err)
+		}
+
+		if found.Error != nil {
+			t.Errorf("expected nil error, got %v", found.Error)
+		}
+
+		if found.Timings != nil {
+			t.Errorf("expected no timings, got %+v", found.Timings)
+		}
+	})
+
+	t.Run("ErrorKindRoundTrip", func(t *testing.T) {
+		repo := newRepo(t)
+		result := domain.NewResult("result-1", "target-1", domain.StatusError, 0, 5*time.Second)
+		result.Error = errors.New("dial tcp: i/o timeout")
+		result.ErrorKind = domain.ErrorKindTimeout
+
+		if err := repo.Save(result); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, err := repo.GetLastByTargetID("target-1")
+		if err != nil {
+			t.Fatalf("expected to find result, got error: %v", err)
+		}
+
+		assertResultEqual(t, result, found)
+	})
+
+	t.Run("InMaintenanceRoundTrip", func(t *testing.T) {
+		repo := newRepo(t)
+		result := domain.NewResult("result-1", "target-1", domain.StatusServerError, 503, 5*time.Millisecond)
+		result.InMaintenance = true
+
+		if err := repo.Save(result); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, err := repo.GetLastByTargetID("target-1")
+		if err != nil {
+			t.Fatalf("expected to find result, got error: %v", err)
+		}
+
+		assertResultEqual(t, result, found)
+	})
+
+	t.Run("FindByTargetID", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if found, _ := repo.FindByTargetID("t-3"); len(found) > 0 {
+			t.Error("expected empty list")
+		}
+
+		saveResults(repo, []resultFixture{
+			{"1", "t-1", "OK", 200, 120 * time.Millisecond},
+			{"2", "t-2", "TIMEOUT", 504, 3 * time.Second},
+			{"3", "t-3", "NOT_FOUND", 404, 45 * time.Millisecond},
+			{"4", "t-3", "ERROR", 500, 830 * time.Millisecond},
+			{"5", "t-3", "OK", 200, 10 * time.Millisecond},
+		})
+
+		found, err := repo.FindByTargetID("t-3")
+		if err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+
+		if len(found) != 3 {
+			t.Fatalf("expected 3 results, got %d", len(found))
+		}
+
+		for i, id := range []string{"3", "4", "5"} {
+			if found[i].ID != id {
+				t.Errorf("expected result %s at position %d, got %s", id, i, found[i].ID)
+			}
+		}
+
+		if _, err := repo.FindByTargetID("nonExistent"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("GetLastByTargetID", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if _, err := repo.GetLastByTargetID("t-3"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+
+		saveResults(repo, []resultFixture{
+			{"1", "t-3", "OK", 200, 120 * time.Millisecond},
+			{"2", "t-2", "TIMEOUT", 504, 3 * time.Second},
+			{"3", "t-3", "NOT_FOUND", 404, 45 * time.Millisecond},
+		})
+
+		found, err := repo.GetLastByTargetID("t-3")
+		if err != nil {
+			t.Fatalf("expected result, got %v", err)
+		}
+
+		if found.ID != "3" {
+			t.Errorf("expected ID 3, got %s", found.ID)
+		}
+	})
+}
+
+func RunResultQueryTests(t *testing.T, newRepo func(t *testing.T) domain.ResultRepository) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	seed := func(t *testing.T) domain.ResultRepository {
+		repo := newRepo(t)
+
+		// Saved out of order on purpose; two results share a timestamp.
+		for _, f := range []struct {
+			id     string
+			offset time.Duration
+			status string
+		}{
+			{"r-3", 3 * time.Minute, domain.StatusServerError},
+			{"r-1", 1 * time.Minute, domain.StatusOK},
+			{"r-5", 5 * time.Minute, domain.StatusOK},
+			{"r-2", 2 * time.Minute, domain.StatusOK},
+			{"r-4a", 4 * time.Minute, domain.StatusOK},
+			{"r-4b", 4 * time.Minute, domain.StatusServerError},
+		} {
+			result := domain.NewResult(f.id, "target-1", f.status, 200, time.Millisecond)
+			result.CheckedAt = base.Add(f.offset)
+			repo.Save(result)
+		}
+
+		other := domain.NewResult("other", "target-2", domain.StatusOK, 200, time.Millisecond)
+		other.CheckedAt = base.Add(3 * time.Minute)
+		repo.Save(other)
+
+		return repo
+	}
+
+	t.Run("NewestFirst", func(t *testing.T) {
+		repo := seed(t)
+
+		page, err := repo.QueryByTargetID("target-1", domain.ResultQuery{})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		assertResultIDs(t, page.Results, "r-5", "r-4b", "r-4a", "r-3", "r-2", "r-1")
+
+		if page.NextCursor != "" {
+			t.Errorf("expected no next cursor, got %q", page.NextCursor)
+		}
+	})
+
+	t.Run("TimeRange", func(t *testing.T) {
+		repo := seed(t)
+
+		page, err := repo.QueryByTargetID("target-1", domain.ResultQuery{
+			From: base.Add(2 * time.Minute),
+			To:   base.Add(4 * time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		assertResultIDs(t, page.Results, "r-3", "r-2")
+	})
+
+	t.Run("StatusFilter", func(t *testing.T) {
+		repo := seed(t)
+
+		page, err := repo.QueryByTargetID("target-1", domain.ResultQuery{Status: domain.StatusServerError})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		assertResultIDs(t, page.Results, "r-4b", "r-3")
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		repo := seed(t)
+
+		var ids []string
+		query := domain.ResultQuery{Limit: 2}
+
+		for pages := 0; ; pages++ {
+			if pages > 3 {
+				t.Fatal("expected pagination to finish after 3 pages")
+			}
+
+			page, err := repo.QueryByTargetID("target-1", query)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if len(page.Results) > 2 {
+				t.Fatalf("expected at most 2 results per page, got %d", len(page.Results))
+			}
+
+			ids = append(ids, resultIDs(page.Results)...)
+
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+
+		expected := []string{"r-5", "r-4b", "r-4a", "r-3", "r-2", "r-1"}
+		if len(ids) != len(expected) {
+			t.Fatalf("expected %v, got %v", expected, ids)
+		}
+		for i := range expected {
+			if ids[i] != expected[i] {
+				t.Fatalf("expected %v, got %v", expected, ids)
+			}
+		}
+	})
+
+	t.Run("PaginationWithFilter", func(t *testing.T) {
+		repo := seed(t)
+
+		query := domain.ResultQuery{Status: domain.StatusOK, From: base.Add(2 * time.Minute), Limit: 2}
+
+		first, err := repo.QueryByTargetID("target-1", query)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		assertResultIDs(t, first.Results, "r-5", "r-4a")
+
+		if first.NextCursor == "" {
+			t.Fatal("expected a next cursor")
+		}
+
+		query.Cursor = first.NextCursor
+		second, err := repo.QueryByTargetID("target-1", query)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		assertResultIDs(t, second.Results, "r-2")
+
+		if second.NextCursor != "" {
+			t.Errorf("expected last page, got cursor %q", second.NextCursor)
+		}
+	})
+
+	t.Run("UnknownTarget", func(t *testing.T) {
+		repo := seed(t)
+
+		page, err := repo.QueryByTargetID("nonExistent", domain.ResultQuery{})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(page.Results) != 0 {
+			t.Errorf("expected empty page, got %d results", len(page.Results))
+		}
+	})
+
+	t.Run("InvalidCursor", func(t *testing.T) {
+		repo := seed(t)
+
+		_, err := repo.QueryByTargetID("target-1", domain.ResultQuery{Cursor: "not a cursor"})
+		if !errors.Is(err, domain.ErrInvalidCursor) {
+			t.Errorf("expected ErrInvalidCursor, got %v", err)
+		}
+	})
+
+	t.Run("DeleteBefore", func(t *testing.T) {
+		repo := seed(t)
+
+		deleted, err := repo.DeleteBefore("target-1", base.Add(4*time.Minute))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if deleted != 3 {
+			t.Errorf("expected 3 results deleted, got %d", deleted)
+		}
+
+		page, _ := repo.QueryByTargetID("target-1", domain.ResultQuery{})
+		assertResultIDs(t, page.Results, "r-5", "r-4b", "r-4a")
+
+		if other, _ := repo.QueryByTargetID("target-2", domain.ResultQuery{}); len(other.Results) != 1 {
+			t.Errorf("expected other targets to be untouched, got %d results", len(other.Results))
+		}
+	})
+
+	t.Run("DeleteBeforeEverything", func(t *testing.T) {
+		repo := seed(t)
+
+		if deleted, err := repo.DeleteBefore("target-1", base.Add(time.Hour)); err != nil || deleted != 6 {
+			t.Fatalf("expected 6 results deleted, got %d and error %v", deleted, err)
+		}
+
+		if _, err := repo.FindByTargetID("target-1"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+
+		if deleted, err := repo.DeleteBefore("nonExistent", base.Add(time.Hour)); err != nil || deleted != 0 {
+			t.Errorf("expected nothing deleted, got %d and error %v", deleted, err)
+		}
+	})
+}
+
+func resultIDs(results []*domain.Result) []string {
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.ID)
+	}
+
+	return ids
+}
+
+func assertResultIDs(t *testing.T, results []*domain.Result, expected ...string) {
+	t.Helper()
+
+	ids := resultIDs(results)
+	if len(ids) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, ids)
+	}
+
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, ids)
+		}
+	}
+}
+
+type resultFixture struct {
+	id           string
+	targetID     string
+	status       string
+	statusCode   int
+	responseTime time.Duration
+}
+
+func saveResults(repo domain.ResultRepository, fixtures []resultFixture) {
+	base := time.Now().Add(-time.Hour)
+
+	for i, f := range fixtures {
+		result := domain.NewResult(f.id, f.targetID, f.status, f.statusCode, f.responseTime)
+		result.CheckedAt = base.Add(time.Duration(i) * time.Second)
+		repo.Save(result)
+	}
+}
+
+func assertResultEqual(t *testing.T, want, got *domain.Result) {
+	t.Helper()
+
+	if got.ID != want.ID {
+		t.Errorf("expected ID %s, got %s", want.ID, got.ID)
+	}
+	if got.TargetID != want.TargetID {
+		t.Errorf("expected targetID %s, got %s", want.TargetID, got.TargetID)
+	}
+	if got.Status != want.Status {
+		t.Errorf("expected status %s, got %s", want.Status, got.Status)
+	}
+	if got.StatusCode != want.StatusCode {
+		t.Errorf("expected status code %d, got %d", want.StatusCode, got.StatusCode)
+	}
+	if got.ResponseTime != want.ResponseTime {
+		t.Errorf("expected response time %s, got %s", want.ResponseTime, got.ResponseTime)
+	}
+	if !sameInstant(got.CheckedAt, want.CheckedAt) {
+		t.Errorf("expected CheckedAt %v, got %v", want.CheckedAt, got.CheckedAt)
+	}
+	if (got.Error == nil) != (want.Error == nil) {
+		t.Fatalf("expected error %v, got %v", want.Error, got.Error)
+	}
+	if want.Error != nil && got.Error.Error() != want.Error.Error() {
+		t.Errorf("expected error %q, got %q", want.Error, got.Error)
+	}
+	if !reflect.DeepEqual(got.Timings, want.Timings) {
+		t.Errorf("expected timings %+v, got %+v", want.Timings, got.Timings)
+	}
+	if got.ErrorKind != want.ErrorKind {
+		t.Errorf("expected error kind %q, got %q", want.ErrorKind, got.ErrorKind)
+	}
+	if got.InMaintenance != want.InMaintenance {
+		t.Errorf("expected InMaintenance %v, got %v", want.InMaintenance, got.InMaintenance)
+	}
+}
+
+// ======================[ALERT]======================
+
+func RunAlertRepositoryTests(t *testing.T, newRepo func(t *testing.T) domain.AlertRepository) {
+	t.Run("SaveAndFindByTargetID", func(t *testing.T) {
+		repo := newRepo(t)
+		alert := domain.NewAlert("alert-1", "target-1", "SERVER_ERROR", "Internal Server Error")
+
+		if err := repo.Save(alert); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, err := repo.FindByTargetID(alert.TargetID)
+		if err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+
+		if len(found) != 1 {
+			t.Fatalf("expected 1 alert, got %d", len(found))
+		}
+
+		assertAlertEqual(t, alert, found[0])
+	})
+
+	t.Run("FindByID", func(t *testing.T) {
+		repo := newRepo(t)
+		alert := domain.NewAlert("alert-1", "target-1", "SERVER_ERROR", "Internal Server Error")
+		repo.Save(alert)
+
+		found, err := repo.FindByID("alert-1")
+		if err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+
+		assertAlertEqual(t, alert, found)
+
+		if _, err := repo.FindByID("nonExistent"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("FindByTargetID", func(t *testing.T) {
+		repo := newRepo(t)
+
+		saveAlerts(repo, []alertFixture{
+			{"alert-1", "target-1", "Error", "Internal Server Error"},
+			{"alert-2", "target-1", "Warning", "High memory usage"},
+			{"alert-3", "target-2", "Info", "Service started"},
+			{"alert-4", "target-3", "Error", "Database connection failed"},
+		})
+
+		found, err := repo.FindByTargetID("target-1")
+		if err != nil {
+			t.Fatalf("expected alerts, got %v", err)
+		}
+
+		if len(found) != 2 || found[0].ID != "alert-1" || found[1].ID != "alert-2" {
+			t.Errorf("expected alerts alert-1 and alert-2 in order, got %v", alertIDs(found))
+		}
+
+		if _, err := repo.FindByTargetID("nonExistent"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("GetUnresolvedByTargetID", func(t *testing.T) {
+		repo := newRepo(t)
+
+		saveAlerts(repo, []alertFixture{
+			{"alert-1", "target-1", "Error", "Internal Server Error"},
+			{"alert-2", "target-1", "Warning", "High memory usage"},
+			{"alert-3", "target-1", "Info", "Service started"},
+			{"alert-4", "target-1", "Error", "Database connection failed"},
+		})
+
+		found, err := repo.GetUnresolvedByTargetID("target-1")
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if len(found) != 4 {
+			t.Errorf("expected 4 alerts, got %d", len(found))
+		}
+
+		if nonExist, err := repo.GetUnresolvedByTargetID("nonExist"); err != nil || len(nonExist) != 0 {
+			t.Errorf("expected empty list, got %d alerts and error %v", len(nonExist), err)
+		}
+
+		for _, alert := range found[:2] {
+			alert.Resolve()
+			repo.Update(alert)
+		}
+
+		unresolved, err := repo.GetUnresolvedByTargetID("target-1")
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if len(unresolved) != 2 {
+			t.Errorf("expected 2, got %d", len(unresolved))
+		}
+	})
+
+	t.Run("GetUnacknowledged", func(t *testing.T) {
+		repo := newRepo(t)
+
+		saveAlerts(repo, []alertFixture{
+			{"alert-1", "target-2", "Error", "Internal Server Error"},
+			{"alert-2", "target-1", "Warning", "High memory usage"},
+			{"alert-3", "target-1", "Error", "Database connection failed"},
+			{"alert-4", "target-3", "Error", "Connection refused"},
+		})
+
+		resolved, _ := repo.FindByID("alert-3")
+		resolved.Resolve()
+		repo.Update(resolved)
+
+		acknowledged, _ := repo.FindByID("alert-4")
+		acknowledged.Acknowledge("alice", time.Now())
+		repo.Update(acknowledged)
+
+		snoozed, _ := repo.FindByID("alert-2")
+		snoozed.Snooze(time.Now().Add(time.Hour))
+		repo.Update(snoozed)
+
+		found, err := repo.GetUnacknowledged()
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if len(found) != 2 || found[0].ID != "alert-1" || found[1].ID != "alert-2" {
+			t.Errorf("expected alerts alert-1 and alert-2 of all targets in order, got %v", alertIDs(found))
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repo := newRepo(t)
+		repo.Save(domain.NewAlert("alert-1", "target-1", "Error", "Internal Server Error"))
+
+		updated := domain.NewAlert("alert-1", "target-1", "Warn", "Origin not set")
+		updated.Acknowledge("alice", time.Now().Add(-time.Minute))
+		updated.Snooze(time.Now().Add(time.Hour))
+		updated.Assign("bob")
+		updated.NotifiedAt = time.Now().Add(-30 * time.Second)
+		updated.EscalationStep = 2
+		updated.Resolve()
+
+		if err := repo.Update(updated); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		found, err := repo.FindByTargetID("target-1")
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		assertAlertEqual(t, updated, found[0])
+
+		missing := domain.NewAlert("nonExistent", "target-1", "Error", "x")
+		if err := repo.Update(missing); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("AcknowledgeSnoozeAndAssign", func(t *testing.T) {
+		repo := newRepo(t)
+		repo.Save(domain.NewAlert("alert-1", "target-1", "Error", "Internal Server Error"))
+
+		at := time.Now().Add(-time.Minute)
+		until := time.Now().Add(time.Hour)
+		if err := repo.Acknowledge("alert-1", "alice", at); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+		if err := repo.Snooze("alert-1", until); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+		if err := repo.Assign("alert-1", "bob"); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		want := domain.NewAlert("alert-1", "target-1", "Error", "Internal Server Error")
+		want.Acknowledge("alice", at)
+		want.Snooze(until)
+		want.Assign("bob")
+
+		found, _ := repo.FindByID("alert-1")
+		want.CreatedAt, want.NotifiedAt = found.CreatedAt, found.NotifiedAt
+		assertAlertEqual(t, want, found)
+
+		if err := repo.Acknowledge("nonExistent", "alice", at); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("ResolvedAlertsAreNotChanged", func(t *testing.T) {
+		repo := newRepo(t)
+		repo.Save(domain.NewAlert("alert-1", "target-1", "Error", "Internal Server Error"))
+
+		resolved, _ := repo.FindByID("alert-1")
+		resolved.Resolve()
+		repo.Update(resolved)
+
+		if err := repo.Acknowledge("alert-1", "alice", time.Now()); !errors.Is(err, domain.ErrAlertResolved) {
+			t.Errorf("expected ErrAlertResolved acknowledging, got %v", err)
+		}
+		if err := repo.Snooze("alert-1", time.Now().Add(time.Hour)); !errors.Is(err, domain.ErrAlertResolved) {
+			t.Errorf("expected ErrAlertResolved snoozing, got %v", err)
+		}
+		if err := repo.Assign("alert-1", "bob"); !errors.Is(err, domain.ErrAlertResolved) {
+			t.Errorf("expected ErrAlertResolved assigning, got %v", err)
+		}
+
+		found, _ := repo.FindByID("alert-1")
+		assertAlertEqual(t, resolved, found)
+	})
+
+	t.Run("RemindAndResolve", func(t *testing.T) {
+		repo := newRepo(t)
+		alert := domain.NewAlert("alert-1", "target-1", "Error", "Internal Server Error")
+		alert.Assign("bob")
+		repo.Save(alert)
+
+		at := time.Now().Add(time.Minute)
+		if reminded, err := repo.Remind("alert-1", at); err != nil || !reminded {
+			t.Fatalf("expected a reminder, got %t and error %v", reminded, err)
+		}
+
+		alert.NotifiedAt = at
+		found, _ := repo.FindByID("alert-1")
+		assertAlertEqual(t, alert, found)
+
+		resolvedAt := at.Add(time.Minute)
+		if resolved, err := repo.Resolve("alert-1", resolvedAt); err != nil || !resolved {
+			t.Fatalf("expected the alert to be resolved, got %t and error %v", resolved, err)
+		}
+
+		alert.IsResolved = true
+		alert.ResolvedAt = &resolvedAt
+		found, _ = repo.FindByID("alert-1")
+		assertAlertEqual(t, alert, found)
+
+		if resolved, err := repo.Resolve("alert-1", resolvedAt.Add(time.Minute)); err != nil || resolved {
+			t.Errorf("expected a resolved alert to stay as it is, got %t and error %v", resolved, err)
+		}
+		if reminded, err := repo.Remind("alert-1", resolvedAt.Add(time.Minute)); err != nil || reminded {
+			t.Errorf("expected no reminder about a resolved alert, got %t and error %v", reminded, err)
+		}
+		if resolved, err := repo.Resolve("nonExistent", resolvedAt); err != nil || resolved {
+			t.Errorf("expected a missing alert not to be resolved, got %t and error %v", resolved, err)
+		}
+	})
+
+	t.Run("RemindSkipsSilencedAlerts", func(t *testing.T) {
+		repo := newRepo(t)
+		repo.Save(domain.NewAlert("alert-1", "target-1", "Error", "Internal Server Error"))
+		repo.Save(domain.NewAlert("alert-2", "target-1", "Error", "Internal Server Error"))
+
+		now := time.Now()
+		repo.Acknowledge("alert-1", "alice", now)
+		repo.Snooze("alert-2", now.Add(time.Hour))
+
+		for _, id := range []string{"alert-1", "alert-2"} {
+			if reminded, err := repo.Remind(id, now.Add(time.Minute)); err != nil || reminded {
+				t.Errorf("expected no reminder about %s, got %t and error %v", id, reminded, err)
+			}
+		}
+
+		if reminded, _ := repo.Remind("alert-2", now.Add(time.Hour)); !reminded {
+			t.Error("expected a reminder once the snooze is over")
+		}
+	})
+
+	t.Run("ReturnsCopies", func(t *testing.T) {
+		repo := newRepo(t)
+		repo.Save(domain.NewAlert("alert-1", "target-1", "Error", "Internal Server Error"))
+
+		read, _ := repo.FindByID("alert-1")
+		read.Resolve()
+
+		if found, _ := repo.FindByID("alert-1"); found.IsResolved {
+			t.Error("expected the alert to change only when updated")
+		}
+	})
+}
+
+type alertFixture struct {
+	id        string
+	targetID  string
+	alertType string
+	message   string
+}
+
+func saveAlerts(repo domain.AlertRepository, fixtures []alertFixture) {
+	base := time.Now().Add(-time.Hour)
+
+	for i, f := range fixtures {
+		alert := domain.NewAlert(f.id, f.targetID, f.alertType, f.message)
+		alert.CreatedAt = base.Add(time.Duration(i) * time.Second)
+		repo.Save(alert)
+	}
+}
+
+func alertIDs(alerts []*domain.Alert) []string {
+	ids := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		ids = append(ids, alert.ID)
+	}
+
+	return ids
+}
+
+func assertAlertEqual(t *testing.T, want, got *domain.Alert) {
+	t.Helper()
+
+	if got.ID != want.ID {
+		t.Errorf("expected ID %s, got %s", want.ID, got.ID)
+	}
+	if got.TargetID != want.TargetID {
+		t.Errorf("expected TargetID %s, got %s", want.TargetID, got.TargetID)
+	}
+	if got.Type != want.Type {
+		t.Errorf("expected Type %s, got %s", want.Type, got.Type)
+	}
+	if got.Message != want.Message {
+		t.Errorf("expected Message %s, got %s", want.Message, got.Message)
+	}
+	if !sameInstant(got.CreatedAt, want.CreatedAt) {
+		t.Errorf("expected CreatedAt %v, got %v", want.CreatedAt, got.CreatedAt)
+	}
+	if got.IsResolved != want.IsResolved {
+		t.Errorf("expected IsResolved %t, got %t", want.IsResolved, got.IsResolved)
+	}
+	if (got.ResolvedAt == nil) != (want.ResolvedAt == nil) {
+		t.Fatalf("expected ResolvedAt %v, got %v", want.ResolvedAt, got.ResolvedAt)
+	}
+	if want.ResolvedAt != nil && !sameInstant(*got.ResolvedAt, *want.ResolvedAt) {
+		t.Errorf("expected ResolvedAt %v, got %v", *want.ResolvedAt, *got.ResolvedAt)
+	}
+	if got.AcknowledgedBy != want.AcknowledgedBy || got.Assignee != want.Assignee {
+		t.Errorf("expected acknowledged by %q and assigned to %q, got %q and %q", want.AcknowledgedBy, want.Assignee, got.AcknowledgedBy, got.Assignee)
+	}
+	if !sameInstantPtr(got.AcknowledgedAt, want.AcknowledgedAt) {
+		t.Errorf("expected AcknowledgedAt %v, got %v", want.AcknowledgedAt, got.AcknowledgedAt)
+	}
+	if !sameInstantPtr(got.SnoozedUntil, want.SnoozedUntil) {
+		t.Errorf("expected SnoozedUntil %v, got %v", want.SnoozedUntil, got.SnoozedUntil)
+	}
+	if !sameInstant(got.NotifiedAt, want.NotifiedAt) {
+		t.Errorf("expected NotifiedAt %v, got %v", want.NotifiedAt, got.NotifiedAt)
+	}
+	if got.EscalationStep != want.EscalationStep {
+		t.Errorf("expected EscalationStep %d, got %d", want.EscalationStep, got.EscalationStep)
+	}
+}
+
+// ======================[ESCALATION POLICY]======================
+
+func RunEscalationPolicyRepositoryTests(t *testing.T, newRepo func(t *testing.T) domain.EscalationPolicyRepository) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	newPolicy := func(id string, createdAt time.Time) *domain.EscalationPolicy {
+		policy := domain.NewEscalationPolicy(id, "On call "+id, []domain.EscalationStep{
+			{Delay: 0, Channels: []string{"chat"}},
+			{Delay: 15 * time.Minute, Channels: []string{"email", "incident"}},
+		})
+		policy.CreatedAt = createdAt
+
+		return policy
+	}
+
+	t.Run("SaveAndFindByID", func(t *testing.T) {
+		repo := newRepo(t)
+		policy := newPolicy("policy-1", base)
+
+		if err := repo.Save(policy); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, err := repo.FindByID("policy-1")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if found.ID != policy.ID || found.Name != policy.Name || !sameInstant(found.CreatedAt, policy.CreatedAt) {
+			t.Errorf("expected %+v, got %+v", policy, found)
+		}
+
+		if !reflect.DeepEqual(found.Steps, policy.Steps) {
+			t.Errorf("expected steps %+v, got %+v", policy.Steps, found.Steps)
+		}
+
+		if _, err := repo.FindByID("nonExistent"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("GetAll", func(t *testing.T) {
+		repo := newRepo(t)
+		repo.Save(newPolicy("policy-2", base.Add(time.Minute)))
+		repo.Save(newPolicy("policy-1", base))
+
+		found, err := repo.GetAll()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(found) != 2 || found[0].ID != "policy-1" || found[1].ID != "policy-2" {
+			t.Errorf("expected policy-1 and policy-2 in order, got %+v", found)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := newRepo(t)
+		repo.Save(newPolicy("policy-1", base))
+
+		if err := repo.Delete("policy-1"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if _, err := repo.FindByID("policy-1"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected the policy to be gone, got %v", err)
+		}
+
+		if err := repo.Delete("policy-1"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+// ======================[MAINTENANCE WINDOW]======================
+
+func RunMaintenanceWindowRepositoryTests(t *testing.T, newRepo func(t *testing.T) domain.MaintenanceWindowRepository) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	newWindow := func(id string, createdAt time.Time) *domain.MaintenanceWindow {
+		window := domain.NewMaintenanceWindow(id, "Deploy "+id, base.Add(24*time.Hour), 90*time.Minute)
+		window.CreatedAt = createdAt
+
+		return window
+	}
+
+	t.Run("SaveAndFindByID", func(t *testing.T) {
+		repo := newRepo(t)
+		window := newWindow("window-1", base)
+		window.Schedule = "30 22 * * 2"
+		window.Location = "Europe/Warsaw"
+		window.TargetIDs = []string{"target-1", "target-2"}
+		window.Tags = []string{"api"}
+
+		if err := repo.Save(window); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, err := repo.FindByID("window-1")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if found.ID != window.ID || found.Name != window.Name || !sameInstant(found.Start, window.Start) ||
+			found.Duration != window.Duration || !sameInstant(found.CreatedAt, window.CreatedAt) {
+			t.Errorf("expected %+v, got %+v", window, found)
+		}
+
+		if found.Schedule != window.Schedule || found.Location != window.Location {
+			t.Errorf("expected schedule %q in %q, got %q in %q", window.Schedule, window.Location, found.Schedule, found.Location)
+		}
+
+		if !reflect.DeepEqual(found.TargetIDs, window.TargetIDs) || !reflect.DeepEqual(found.Tags, window.Tags) {
+			t.Errorf("expected targets %v and tags %v, got %v and %v", window.TargetIDs, window.Tags, found.TargetIDs, found.Tags)
+		}
+
+		if _, err := repo.FindByID("nonExistent"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("GetAll", func(t *testing.T) {
+		repo := newRepo(t)
+		repo.Save(newWindow("window-2", base.Add(time.Minute)))
+		repo.Save(newWindow("window-1", base))
+
+		found, err := repo.GetAll()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(found) != 2 || found[0].ID != "window-1" || found[1].ID != "window-2" {
+			t.Errorf("expected window-1 and window-2 in order, got %+v", found)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := newRepo(t)
+		repo.Save(newWindow("window-1", base))
+
+		if err := repo.Delete("window-1"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if _, err := repo.FindByID("window-1"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected the window to be gone, got %v", err)
+		}
+
+		if err := repo.Delete("window-1"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+// ======================[OUTBOX]======================
+
+// inOwnChannels sends every message to a channel of its own, so none waits
+// for another.
+func inOwnChannels(messages []*domain.OutboxMessage) {
+	for _, message := range messages {
+		message.Channel = message.ID
+	}
+}
+
+// RunOutboxRepositoryTests takes the outbox and the alert repository it saves
+// alerts to.
+func RunOutboxRepositoryTests(t *testing.T, newRepos func(t *testing.T) (domain.OutboxRepository, domain.AlertRepository)) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	target := domain.Target{ID: "target-1", URL: "https://example.com", Name: "Example", State: domain.StateDown}
+
+	newMessages := func(alert *domain.Alert, event string, ids ...string) []*domain.OutboxMessage {
+		messages := make([]*domain.OutboxMessage, 0, len(ids))
+		for i, id := range ids {
+			n := domain.Notification{Event: event, Alert: *alert, Target: target}
+			messages = append(messages, domain.NewOutboxMessage(id, "webhook", n, base.Add(time.Duration(i)*time.Second)))
+		}
+
+		return messages
+	}
+
+	t.Run("SaveAlertEnqueues", func(t *testing.T) {
+		outbox, alerts := newRepos(t)
+		alert := domain.NewAlert("alert-1", "target-1", domain.StatusServerError, "down")
+		messages := newMessages(alert, domain.NotificationOpened, "message-1")
+
+		if err := outbox.SaveAlert(alert, messages); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, err := alerts.FindByTargetID("target-1")
+		if err != nil || len(found) != 1 {
+			t.Fatalf("expected the saved alert, got %d alerts and error %v", len(found), err)
+		}
+		assertAlertEqual(t, alert, found[0])
+
+		message, err := outbox.FindByID("message-1")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		assertOutboxMessageEqual(t, messages[0], message)
+
+		if _, err := outbox.FindByID("nonExistent"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("ResolveAlertEnqueues", func(t *testing.T) {
+		outbox, alerts := newRepos(t)
+		alert := domain.NewAlert("alert-1", "target-1", domain.StatusServerError, "down")
+		outbox.SaveAlert(alert, nil)
+
+		alert.Resolve()
+		resolved, err := outbox.ResolveAlert("alert-1", *alert.ResolvedAt, newMessages(alert, domain.NotificationResolved, "message-1"))
+		if err != nil || !resolved {
+			t.Fatalf("expected the alert to be resolved, got %t and error %v", resolved, err)
+		}
+
+		unresolved, _ := alerts.GetUnresolvedByTargetID("target-1")
+		if len(unresolved) != 0 {
+			t.Errorf("expected the alert to be resolved, got %d unresolved", len(unresolved))
+		}
+
+		message, err := outbox.FindByID("message-1")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if message.Notification.Event != domain.NotificationResolved || !message.Notification.Alert.IsResolved {
+			t.Errorf("expected a resolved notification, got %+v", message.Notification)
+		}
+	})
+
+	t.Run("AdvanceEscalation", func(t *testing.T) {
+		outbox, alerts := newRepos(t)
+		alert := domain.NewAlert("alert-1", "target-1", domain.StatusServerError, "down")
+		outbox.SaveAlert(alert, nil)
+
+		advanced, err := outbox.AdvanceEscalation("alert-1", 0, 2, newMessages(alert, domain.NotificationEscalated, "message-1"))
+		if err != nil || !advanced {
+			t.Fatalf("expected the alert to advance, got %t and error %v", advanced, err)
+		}
+
+		if found, _ := alerts.FindByID("alert-1"); found.EscalationStep != 2 {
+			t.Errorf("expected step 2, got %d", found.EscalationStep)
+		}
+		if _, err := outbox.FindByID("message-1"); err != nil {
+			t.Errorf("expected the message to be queued, got %v", err)
+		}
+
+		// Another pass read the alert at step 0 too.
+		if advanced, err := outbox.AdvanceEscalation("alert-1", 0, 1, newMessages(alert, domain.NotificationEscalated, "message-2")); err != nil || advanced {
+			t.Errorf("expected a stale step not to advance, got %t and error %v", advanced, err)
+		}
+		if _, err := outbox.FindByID("message-2"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected nothing to be queued, got %v", err)
+		}
+	})
+
+	t.Run("AdvanceEscalationOfResolvedOrAcknowledgedAlert", func(t *testing.T) {
+		outbox, alerts := newRepos(t)
+		resolved := domain.NewAlert("alert-1", "target-1", domain.StatusServerError, "down")
+		acknowledged := domain.NewAlert("alert-2", "target-1", domain.StatusServerError, "down")
+		outbox.SaveAlert(resolved, nil)
+		outbox.SaveAlert(acknowledged, nil)
+
+		// Resolved and acknowledged after the escalation pass read them.
+		resolved.Resolve()
+		alerts.Update(resolved)
+		alerts.Acknowledge("alert-2", "alice", base)
+
+		for _, id := range []string{"alert-1", "alert-2", "nonExistent"} {
+			messageID := "message-" + id
+			if advanced, err := outbox.AdvanceEscalation(id, 0, 1, newMessages(resolved, domain.NotificationEscalated, messageID)); err != nil || advanced {
+				t.Errorf("%s: expected no escalation, got %t and error %v", id, advanced, err)
+			}
+			if _, err := outbox.FindByID(messageID); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("%s: expected nothing to be queued, got %v", id, err)
+			}
+		}
+
+		found, _ := alerts.FindByID("alert-1")
+		assertAlertEqual(t, resolved, found)
+	})
+
+	t.Run("RemindAlertEnqueues", func(t *testing.T) {
+		outbox, alerts := newRepos(t)
+		alert := domain.NewAlert("alert-1", "target-1", domain.StatusServerError, "down")
+		outbox.SaveAlert(alert, nil)
+
+		reminded, err := outbox.RemindAlert("alert-1", base, newMessages(alert, domain.NotificationRepeated, "message-1"))
+		if err != nil || !reminded {
+			t.Fatalf("expected a reminder, got %t and error %v", reminded, err)
+		}
+
+		if found, _ := alerts.FindByID("alert-1"); !sameInstant(found.NotifiedAt, base) {
+			t.Errorf("expected the alert to be notified at %v, got %v", base, found.NotifiedAt)
+		}
+
+		if _, err := outbox.FindByID("message-1"); err != nil {
+			t.Errorf("expected the reminder to be enqueued, got %v", err)
+		}
+	})
+
+	t.Run("UnchangedAlertEnqueuesNothing", func(t *testing.T) {
+		outbox, alerts := newRepos(t)
+		alert := domain.NewAlert("alert-1", "target-1", domain.StatusServerError, "down")
+		outbox.SaveAlert(alert, nil)
+		alerts.Acknowledge("alert-1", "alice", base)
+
+		if reminded, err := outbox.RemindAlert("alert-1", base, newMessages(alert, domain.NotificationRepeated, "message-1")); err != nil || reminded {
+			t.Errorf("expected no reminder about an acknowledged alert, got %t and error %v", reminded, err)
+		}
+
+		alerts.Resolve("alert-1", base)
+		if resolved, err := outbox.ResolveAlert("alert-1", base, newMessages(alert, domain.NotificationResolved, "message-2")); err != nil || resolved {
+			t.Errorf("expected a resolved alert to stay as it is, got %t and error %v", resolved, err)
+		}
+
+		if resolved, err := outbox.ResolveAlert("nonExistent", base, newMessages(alert, domain.NotificationResolved, "message-3")); err != nil || resolved {
+			t.Errorf("expected a missing alert not to be resolved, got %t and error %v", resolved, err)
+		}
+
+		for _, id := range []string{"message-1", "message-2", "message-3"} {
+			if _, err := outbox.FindByID(id); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("expected %s not to be enqueued, got %v", id, err)
+			}
+		}
+	})
+
+	t.Run("Due", func(t *testing.T) {
+		outbox, _ := newRepos(t)
+		alert := domain.NewAlert("alert-1", "target-1", domain.StatusServerError, "down")
+		messages := newMessages(alert, domain.NotificationOpened, "message-1", "message-2", "message-3", "message-4")
+		inOwnChannels(messages)
+		messages[0].NextAttemptAt = base.Add(time.Minute)
+		messages[3].Fail(errors.New("gone"), base, time.Time{})
+		outbox.SaveAlert(alert, messages)
+
+		due, err := outbox.Due(base.Add(5*time.Second), 10)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if ids := outboxMessageIDs(due); !reflect.DeepEqual(ids, []string{"message-2", "message-3"}) {
+			t.Errorf("expected message-2 and message-3, got %v", ids)
+		}
+
+		due, _ = outbox.Due(base.Add(time.Hour), 2)
+		if ids := outboxMessageIDs(due); !reflect.DeepEqual(ids, []string{"message-2", "message-3"}) {
+			t.Errorf("expected the limit to keep the earliest messages, got %v", ids)
+		}
+	})
+
+	t.Run("DueInOrder", func(t *testing.T) {
+		outbox, _ := newRepos(t)
+		alert := domain.NewAlert("alert-1", "target-1", domain.StatusServerError, "down")
+		opened := newMessages(alert, domain.NotificationOpened, "message-1")
+		opened[0].Fail(errors.New("timeout"), base, base.Add(time.Minute))
+		outbox.SaveAlert(alert, opened)
+
+		other := domain.NewAlert("alert-2", "target-1", domain.StatusServerError, "down")
+		outbox.SaveAlert(other, newMessages(other, domain.NotificationOpened, "message-2"))
+
+		resolved := newMessages(alert, domain.NotificationResolved, "message-3", "message-4")
+		resolved[0].CreatedAt, resolved[0].NextAttemptAt = base.Add(time.Second), base.Add(time.Second)
+		resolved[1].Channel = "email"
+		outbox.ResolveAlert("alert-1", base.Add(time.Second), resolved)
+
+		due, err := outbox.Due(base.Add(5*time.Second), 10)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if ids := outboxMessageIDs(due); !reflect.DeepEqual(ids, []string{"message-2", "message-4"}) {
+			t.Errorf("expected message-3 to wait for message-1, got %v", ids)
+		}
+
+		opened[0].Fail(errors.New("timeout"), base.Add(time.Minute), time.Time{})
+		outbox.Update(opened[0])
+
+		due, _ = outbox.Due(base.Add(5*time.Second), 10)
+		if ids := outboxMessageIDs(due); !reflect.DeepEqual(ids, []string{"message-2", "message-3", "message-4"}) {
+			t.Errorf("expected dead letters not to hold messages back, got %v", ids)
+		}
+	})
+
+	t.Run("DeadLettersAndReplay", func(t *testing.T) {
+		outbox, _ := newRepos(t)
+		alert := domain.NewAlert("alert-1", "target-1", domain.StatusServerError, "down")
+		messages := newMessages(alert, domain.NotificationOpened, "message-1", "message-2")
+		inOwnChannels(messages)
+		outbox.SaveAlert(alert, messages)
+
+		messages[1].Fail(errors.New("500 Internal Server Error"), base.Add(time.Minute), time.Time{})
+		if err := outbox.Update(messages[1]); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		dead, err := outbox.DeadLetters()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(dead) != 1 {
+			t.Fatalf("expected 1 dead letter, got %d", len(dead))
+		}
+		assertOutboxMessageEqual(t, messages[1], dead[0])
+
+		messages[1].Replay(base.Add(time.Hour))
+		outbox.Update(messages[1])
+
+		dead, _ = outbox.DeadLetters()
+		if len(dead) != 0 {
+			t.Errorf("expected no dead letters after replay, got %d", len(dead))
+		}
+
+		due, _ := outbox.Due(base.Add(time.Hour), 10)
+		if ids := outboxMessageIDs(due); !reflect.DeepEqual(ids, []string{"message-1", "message-2"}) {
+			t.Errorf("expected the replayed message to be due, got %v", ids)
+		}
+
+		missing := domain.NewOutboxMessage("nonExistent", "webhook", domain.Notification{}, base)
+		if err := outbox.Update(missing); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		outbox, _ := newRepos(t)
+		alert := domain.NewAlert("alert-1", "target-1", domain.StatusServerError, "down")
+		outbox.SaveAlert(alert, newMessages(alert, domain.NotificationOpened, "message-1"))
+
+		if err := outbox.Delete("message-1"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if _, err := outbox.FindByID("message-1"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+
+		if err := outbox.Delete("message-1"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func outboxMessageIDs(messages []*domain.OutboxMessage) []string {
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+
+	return ids
+}
+
+func assertOutboxMessageEqual(t *testing.T, want, got *domain.OutboxMessage) {
+	t.Helper()
+
+	if got.ID != want.ID || got.Channel != want.Channel || got.Attempts != want.Attempts || got.LastError != want.LastError {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if !sameInstant(got.NextAttemptAt, want.NextAttemptAt) {
+		t.Errorf("expected NextAttemptAt %v, got %v", want.NextAttemptAt, got.NextAttemptAt)
+	}
+	if !sameInstant(got.CreatedAt, want.CreatedAt) {
+		t.Errorf("expected CreatedAt %v, got %v", want.CreatedAt, got.CreatedAt)
+	}
+	if (got.DeadAt == nil) != (want.DeadAt == nil) {
+		t.Fatalf("expected DeadAt %v, got %v", want.DeadAt, got.DeadAt)
+	}
+	if want.DeadAt != nil && !sameInstant(*got.DeadAt, *want.DeadAt) {
+		t.Errorf("expected DeadAt %v, got %v", *want.DeadAt, *got.DeadAt)
+	}
+
+	n, m := want.Notification, got.Notification
+	if m.Event != n.Event || m.Target.ID != n.Target.ID || m.Target.Name != n.Target.Name || m.Target.State != n.Target.State {
+		t.Errorf("expected notification %+v, got %+v", n, m)
+	}
+	assertAlertEqual(t, &n.Alert, &m.Alert)
+}
+
+// ======================[ROLLUP]======================
+
+func RunRollupRepositoryTests(t *testing.T, newRepo func(t *testing.T) domain.RollupRepository) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	hourly := func(offset time.Duration, statuses ...string) *domain.Rollup {
+		results := make([]*domain.Result, 0, len(statuses))
+		for i, status := range statuses {
+			result := domain.NewResult("", "target-1", status, 200, time.Duration(i+1)*10*time.Millisecond)
+			result.Timings = &domain.Timings{Connect: time.Duration(i+1) * time.Millisecond, Server: result.ResponseTime / 2}
+			results = append(results, result)
+		}
+
+		return domain.NewRollup("target-1", domain.RollupHourly, base.Add(offset), results)
+	}
+
+	t.Run("SaveAndFind", func(t *testing.T) {
+		repo := newRepo(t)
+		rollup := hourly(time.Hour, domain.StatusOK, domain.StatusOK, domain.StatusServerError)
+
+		if err := repo.Save(rollup); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, err := repo.FindByTargetID("target-1", domain.RollupHourly, base, base.Add(24*time.Hour))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(found) != 1 {
+			t.Fatalf("expected 1 rollup, got %d", len(found))
+		}
+
+		assertRollupEqual(t, rollup, found[0])
+	})
+
+	t.Run("SaveReplacesBucket", func(t *testing.T) {
+		repo := newRepo(t)
+
+		repo.Save(hourly(0, domain.StatusOK))
+		replacement := hourly(0, domain.StatusOK, domain.StatusServerError)
+
+		if err := repo.Save(replacement); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, _ := repo.FindByTargetID("target-1", domain.RollupHourly, base, base.Add(time.Hour))
+
+		if len(found) != 1 {
+			t.Fatalf("expected the bucket to be replaced, got %d rollups", len(found))
+		}
+
+		assertRollupEqual(t, replacement, found[0])
+	})
+
+	t.Run("FindRangeAndResolution", func(t *testing.T) {
+		repo := newRepo(t)
+
+		repo.Save(hourly(2*time.Hour, domain.StatusOK))
+		repo.Save(hourly(0, domain.StatusOK))
+		repo.Save(hourly(time.Hour, domain.StatusOK))
+		repo.Save(domain.MergeRollups("target-1", domain.RollupDaily, base, nil))
+		repo.Save(domain.NewRollup("target-2", domain.RollupHourly, base.Add(time.Hour), nil))
+
+		found, err := repo.FindByTargetID("target-1", domain.RollupHourly, base.Add(time.Hour), base.Add(3*time.Hour))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(found) != 2 || !found[0].BucketStart.Equal(base.Add(time.Hour)) || !found[1].BucketStart.Equal(base.Add(2*time.Hour)) {
+			t.Fatalf("expected the 01:00 and 02:00 buckets oldest first, got %d rollups", len(found))
+		}
+
+		daily, _ := repo.FindByTargetID("target-1", domain.RollupDaily, base, base.Add(24*time.Hour))
+		if len(daily) != 1 || daily[0].Resolution != domain.RollupDaily {
+			t.Errorf("expected 1 daily rollup, got %d", len(daily))
+		}
+
+		none, err := repo.FindByTargetID("nonExistent", domain.RollupHourly, base, base.Add(24*time.Hour))
+		if err != nil || len(none) != 0 {
+			t.Errorf("expected empty list, got %d rollups and error %v", len(none), err)
+		}
+	})
+
+	t.Run("DeleteBefore", func(t *testing.T) {
+		repo := newRepo(t)
+
+		repo.Save(hourly(0, domain.StatusOK))
+		repo.Save(hourly(time.Hour, domain.StatusOK))
+		repo.Save(hourly(2*time.Hour, domain.StatusOK))
+		repo.Save(domain.MergeRollups("target-1", domain.RollupDaily, base, nil))
+
+		deleted, err := repo.DeleteBefore("target-1", domain.RollupHourly, base.Add(2*time.Hour))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if deleted != 2 {
+			t.Errorf("expected 2 rollups deleted, got %d", deleted)
+		}
+
+		found, _ := repo.FindByTargetID("target-1", domain.RollupHourly, base, base.Add(24*time.Hour))
+		if len(found) != 1 {
+			t.Errorf("expected 1 hourly rollup left, got %d", len(found))
+		}
+
+		if daily, _ := repo.FindByTargetID("target-1", domain.RollupDaily, base, base.Add(24*time.Hour)); len(daily) != 1 {
+			t.Errorf("expected daily rollups to be untouched, got %d", len(daily))
+		}
+	})
+}
+
+func assertRollupEqual(t *testing.T, want, got *domain.Rollup) {
+	t.Helper()
+
+	if got.TargetID != want.TargetID || got.Resolution != want.Resolution {
+		t.Errorf("expected rollup %s/%s, got %s/%s", want.TargetID, want.Resolution, got.TargetID, got.Resolution)
+	}
+
+	if !got.BucketStart.Equal(want.BucketStart) {
+		t.Errorf("expected bucket %s, got %s", want.BucketStart, got.BucketStart)
+	}
+
+	if got.TotalChecks != want.TotalChecks || got.UptimePercent != want.UptimePercent {
+		t.Errorf("expected %d checks at %f%%, got %d at %f%%", want.TotalChecks, want.UptimePercent, got.TotalChecks, got.UptimePercent)
+	}
+
+	if len(got.StatusCounts) != len(want.StatusCounts) {
+		t.Errorf("expected status counts %v, got %v", want.StatusCounts, got.StatusCounts)
+	}
+
+	for status, n := range want.StatusCounts {
+		if got.StatusCounts[status] != n {
+			t.Errorf("expected status counts %v, got %v", want.StatusCounts, got.StatusCounts)
+		}
+	}
+
+	if got.MinResponseTime != want.MinResponseTime ||
+		got.AvgResponseTime != want.AvgResponseTime ||
+		got.MaxResponseTime != want.MaxResponseTime ||
+		got.P50ResponseTime != want.P50ResponseTime ||
+		got.P95ResponseTime != want.P95ResponseTime ||
+		got.P99ResponseTime != want.P99ResponseTime {
+		t.Errorf("expected response times %+v, got %+v", want, got)
+	}
+
+	if len(got.PhaseTimings) != len(want.PhaseTimings) {
+		t.Errorf("expected phase timings %+v, got %+v", want.PhaseTimings, got.PhaseTimings)
+	}
+
+	for phase, summary := range want.PhaseTimings {
+		if got.PhaseTimings[phase] != summary {
+			t.Errorf("expected %s timings %+v, got %+v", phase, summary, got.PhaseTimings[phase])
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package tcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+const maxBannerSize = 4096
+
+// Checker probes tcp://host:port targets. The response time is the time it
+// takes to connect. Two optional query parameters turn it into a simple
+// conversation: send is written once connected and expect must appear in what
+// the server sends back, e.g. tcp://mail.example.com:25?expect=220.
+type Checker struct {
+	dialer  net.Dialer
+	timeout time.Duration
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		dialer:  net.Dialer{Timeout: timeout},
+		timeout: timeout,
+	}
+}
+
+func (c *Checker) Check(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+	u, err := url.Parse(target.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Port() == "" {
+		return nil, fmt.Errorf("tcp target %s has no port", target.URL)
+	}
+
+	query := u.Query()
+	send, expect := query.Get("send"), query.Get("expect")
+
+	start := time.Now()
+
+	conn, err := c.dialer.DialContext(ctx, "tcp", u.Host)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	res := &domain.CheckResponse{ResponseTime: time.Since(start)}
+
+	if send == "" && expect == "" {
+		return res, nil
+	}
+
+	if d, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(d)
+	} else if c.timeout > 0 {
+		conn.SetDeadline(time.Now().Add(c.timeout))
+	}
+
+	if send != "" {
+		if _, err := conn.Write([]byte(send)); err != nil {
+			return nil, err
+		}
+	}
+
+	if expect != "" {
+		if banner, err := readUntil(conn, []byte(expect)); err != nil {
+			res.Error = fmt.Errorf("expected %q in banner, got %q: %w", expect, banner, err)
+		}
+	}
+
+	return res, nil
+}
+
+// readUntil reads until want shows up, the peer stops sending or
+// maxBannerSize bytes have been read.
+func readUntil(conn net.Conn, want []byte) ([]byte, error) {
+	buf := make([]byte, 0, 512)
+	chunk := make([]byte, 512)
+
+	for len(buf) < maxBannerSize {
+		n, err := conn.Read(chunk)
+		buf = append(buf, chunk[:n]...)
+
+		if bytes.Contains(buf, want) {
+			return buf, nil
+		}
+
+		if err != nil {
+			return buf, err
+		}
+	}
+
+	return buf, errors.New("banner too long")
+}
@@ -0,0 +1,133 @@
+package tcp
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+// listen starts a TCP server that hands every connection to handle.
+func listen(t *testing.T, handle func(conn net.Conn)) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func newTarget(url string) *domain.Target {
+	return domain.NewTarget("target-1", url, "", time.Minute)
+}
+
+func TestChecker_Connect(t *testing.T) {
+	addr := listen(t, func(conn net.Conn) {})
+
+	resp, err := NewChecker(time.Second).Check(context.Background(), newTarget("tcp://"+addr))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if resp.Error != nil {
+		t.Errorf("expected a healthy response, got %v", resp.Error)
+	}
+
+	if resp.ResponseTime == 0 {
+		t.Error("connect time should be measured")
+	}
+}
+
+func TestChecker_ConnectionRefused(t *testing.T) {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	ln.Close()
+
+	if _, err := NewChecker(time.Second).Check(context.Background(), newTarget("tcp://"+addr)); err == nil {
+		t.Error("expected connection error, got nil")
+	}
+}
+
+func TestChecker_Banner(t *testing.T) {
+	addr := listen(t, func(conn net.Conn) {
+		conn.Write([]byte("220 mail.example.com ESMTP ready\r\n"))
+	})
+
+	resp, err := NewChecker(time.Second).Check(context.Background(), newTarget("tcp://"+addr+"?expect=220"))
+
+	if err != nil || resp.Error != nil {
+		t.Fatalf("expected banner to match, got %v / %v", err, resp.Error)
+	}
+
+	resp, err = NewChecker(time.Second).Check(context.Background(), newTarget("tcp://"+addr+"?expect=554"))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if resp.Error == nil {
+		t.Error("expected banner mismatch to be reported")
+	}
+}
+
+func TestChecker_SendAndExpect(t *testing.T) {
+	addr := listen(t, func(conn net.Conn) {
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		if line == "PING\r\n" {
+			conn.Write([]byte("+PONG\r\n"))
+		}
+	})
+
+	resp, err := NewChecker(time.Second).Check(context.Background(), newTarget("tcp://"+addr+"?send=PING%0D%0A&expect=%2BPONG"))
+
+	if err != nil || resp.Error != nil {
+		t.Fatalf("expected PONG, got %v / %v", err, resp.Error)
+	}
+}
+
+func TestChecker_BannerTimeout(t *testing.T) {
+	addr := listen(t, func(conn net.Conn) {
+		time.Sleep(500 * time.Millisecond)
+	})
+
+	resp, err := NewChecker(100*time.Millisecond).Check(context.Background(), newTarget("tcp://"+addr+"?expect=220"))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if resp.Error == nil {
+		t.Error("expected a silent server to fail the banner check")
+	}
+}
+
+func TestChecker_MissingPort(t *testing.T) {
+	if _, err := NewChecker(time.Second).Check(context.Background(), newTarget("tcp://example.com")); err == nil {
+		t.Error("expected an error for a target without port")
+	}
+}
@@ -0,0 +1,160 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+const defaultTimeout = 10 * time.Second
+
+type Option func(*Notifier)
+
+// WithHeaders adds headers to every request, e.g. Authorization.
+func WithHeaders(headers map[string]string) Option {
+	return func(n *Notifier) {
+		for name, value := range headers {
+			n.headers[name] = value
+		}
+	}
+}
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(n *Notifier) {
+		n.client = client
+	}
+}
+
+// Notifier POSTs a JSON document to a URL for every notification. By default
+// the body is the Payload; a template renders the Payload into a body of its
+// own instead, with a json function to quote values.
+type Notifier struct {
+	url      string
+	headers  map[string]string
+	template *template.Template
+	client   *http.Client
+}
+
+// NewNotifier fails when payloadTemplate, if not empty, doesn't parse.
+func NewNotifier(url, payloadTemplate string, opts ...Option) (*Notifier, error) {
+	n := &Notifier{
+		url:     url,
+		headers: make(map[string]string),
+		client:  &http.Client{Timeout: defaultTimeout},
+	}
+
+	if payloadTemplate != "" {
+		tmpl, err := template.New("payload").Funcs(template.FuncMap{"json": toJSON}).Parse(payloadTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("parsing webhook payload template: %w", err)
+		}
+		n.template = tmpl
+	}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	return n, nil
+}
+
+// Payload is the default body of a webhook and the data of its template.
+type Payload struct {
+	Event  string        `json:"event"`
+	Alert  AlertPayload  `json:"alert"`
+	Target TargetPayload `json:"target"`
+}
+
+type AlertPayload struct {
+	ID         string     `json:"id"`
+	Type       string     `json:"type"`
+	Message    string     `json:"message"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+type TargetPayload struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	State string `json:"state"`
+}
+
+func NewPayload(n domain.Notification) Payload {
+	return Payload{
+		Event: n.Event,
+		Alert: AlertPayload{
+			ID:         n.Alert.ID,
+			Type:       n.Alert.Type,
+			Message:    n.Alert.Message,
+			CreatedAt:  n.Alert.CreatedAt,
+			ResolvedAt: n.Alert.ResolvedAt,
+		},
+		Target: TargetPayload{
+			ID:    n.Target.ID,
+			Name:  n.Target.Name,
+			URL:   n.Target.URL,
+			State: n.Target.State,
+		},
+	}
+}
+
+func (n *Notifier) Notify(ctx context.Context, notification domain.Notification) error {
+	body, err := n.render(NewPayload(notification))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range n.headers {
+		req.Header.Set(name, value)
+	}
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook %s answered %s", n.url, res.Status)
+	}
+
+	return nil
+}
+
+func (n *Notifier) render(payload Payload) ([]byte, error) {
+	if n.template == nil {
+		return json.Marshal(payload)
+	}
+
+	var buf bytes.Buffer
+	if err := n.template.Execute(&buf, payload); err != nil {
+		return nil, fmt.Errorf("rendering webhook payload: %w", err)
+	}
+
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("webhook payload template produced invalid JSON: %s", buf.String())
+	}
+
+	return buf.Bytes(), nil
+}
+
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+type request struct {
+	header http.Header
+	body   []byte
+}
+
+// receive starts a webhook receiver that answers with status and passes on
+// the requests it got.
+func receive(t *testing.T, status int) (string, <-chan request) {
+	t.Helper()
+
+	requests := make(chan request, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- request{header: r.Header, body: body}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv.URL, requests
+}
+
+func newNotification() domain.Notification {
+	target := domain.NewTarget("target-1", "https://example.com", "Example", time.Minute)
+	target.State = domain.StateDown
+
+	return domain.Notification{
+		Event:  domain.NotificationOpened,
+		Alert:  *domain.NewAlert("alert-1", target.ID, domain.StatusServerError, "Target https://example.com is SERVER_ERROR"),
+		Target: *target,
+	}
+}
+
+func TestNotifier_DefaultPayload(t *testing.T) {
+	url, requests := receive(t, http.StatusNoContent)
+
+	notifier, err := NewNotifier(url, "", WithHeaders(map[string]string{"Authorization": "Bearer secret"}))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := notifier.Notify(context.Background(), newNotification()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	req := <-requests
+
+	if req.header.Get("Content-Type") != "application/json" || req.header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("expected JSON with the configured headers, got %v", req.header)
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("expected a JSON payload, got %s", req.body)
+	}
+
+	if payload.Event != domain.NotificationOpened || payload.Alert.ID != "alert-1" || payload.Alert.Type != domain.StatusServerError {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+
+	if payload.Target.URL != "https://example.com" || payload.Target.State != domain.StateDown {
+		t.Errorf("expected the target in the payload, got %+v", payload.Target)
+	}
+}
+
+func TestNotifier_Template(t *testing.T) {
+	url, requests := receive(t, http.StatusOK)
+
+	notifier, err := NewNotifier(url, `{"text": {{json (printf "[%s] %s" .Event .Alert.Message)}}}`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := notifier.Notify(context.Background(), newNotification()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var body map[string]string
+	if err := json.Unmarshal((<-requests).body, &body); err != nil {
+		t.Fatalf("expected a JSON payload, got %v", err)
+	}
+
+	if want := "[opened] Target https://example.com is SERVER_ERROR"; body["text"] != want {
+		t.Errorf("expected %q, got %q", want, body["text"])
+	}
+}
+
+func TestNotifier_Errors(t *testing.T) {
+	if _, err := NewNotifier("http://example.com", "{{"); err == nil {
+		t.Error("expected an unparsable template to be rejected")
+	}
+
+	url, _ := receive(t, http.StatusInternalServerError)
+	notifier, _ := NewNotifier(url, "")
+
+	if err := notifier.Notify(context.Background(), newNotification()); err == nil {
+		t.Error("expected a failing receiver to be reported")
+	}
+
+	notifier, _ = NewNotifier(url, `{"text": {{.Alert.Message}}}`)
+
+	if err := notifier.Notify(context.Background(), newNotification()); err == nil {
+		t.Error("expected a template rendering invalid JSON to be reported")
+	}
+}
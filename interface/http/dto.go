@@ -0,0 +1,507 @@
+package http
+
+import (
+	"strings"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+	"github.com/karoljaro/go-uptime-monitor/usecase"
+)
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type targetRequest struct {
+	URL      string            `json:"url"`
+	Name     string            `json:"name"`
+	Interval int               `json:"interval"`
+	IsActive *bool             `json:"is_active,omitempty"`
+	HTTP     *httpCheckRequest `json:"http,omitempty"`
+	Policy   *checkPolicyDTO   `json:"policy,omitempty"`
+
+	Latency *latencyThresholdDTO `json:"latency,omitempty"`
+
+	AlertEmails []string `json:"alert_emails,omitempty"`
+
+	EscalationPolicyID string `json:"escalation_policy_id,omitempty"`
+
+	Tags []string `json:"tags,omitempty"`
+}
+
+type checkPolicyDTO struct {
+	Retries           int   `json:"retries"`
+	RetryBackoffMs    int64 `json:"retry_backoff_ms,omitempty"`
+	FailureThreshold  int   `json:"failure_threshold,omitempty"`
+	RecoveryThreshold int   `json:"recovery_threshold,omitempty"`
+}
+
+func (p *checkPolicyDTO) toDomain() (*domain.CheckPolicy, error) {
+	policy := &domain.CheckPolicy{
+		Retries:           p.Retries,
+		RetryBackoff:      time.Duration(p.RetryBackoffMs) * time.Millisecond,
+		FailureThreshold:  p.FailureThreshold,
+		RecoveryThreshold: p.RecoveryThreshold,
+	}
+
+	return policy, policy.Validate()
+}
+
+func newCheckPolicyDTO(p *domain.CheckPolicy) *checkPolicyDTO {
+	return &checkPolicyDTO{
+		Retries:           p.Retries,
+		RetryBackoffMs:    p.RetryBackoff.Milliseconds(),
+		FailureThreshold:  p.FailureThreshold,
+		RecoveryThreshold: p.RecoveryThreshold,
+	}
+}
+
+// latencyThresholdDTO degrades a target when a check takes longer than
+// max_ms or when the p95 of the last p95_window checks is above p95_ms.
+type latencyThresholdDTO struct {
+	MaxMs     int64 `json:"max_ms,omitempty"`
+	P95Ms     int64 `json:"p95_ms,omitempty"`
+	P95Window int   `json:"p95_window,omitempty"`
+}
+
+func (l *latencyThresholdDTO) toDomain() (*domain.LatencyThreshold, error) {
+	threshold := &domain.LatencyThreshold{
+		Max:       time.Duration(l.MaxMs) * time.Millisecond,
+		P95:       time.Duration(l.P95Ms) * time.Millisecond,
+		P95Window: l.P95Window,
+	}
+
+	return threshold, threshold.Validate()
+}
+
+func newLatencyThresholdDTO(l *domain.LatencyThreshold) *latencyThresholdDTO {
+	return &latencyThresholdDTO{
+		MaxMs:     l.Max.Milliseconds(),
+		P95Ms:     l.P95.Milliseconds(),
+		P95Window: l.P95Window,
+	}
+}
+
+// httpCheckRequest takes accepted status codes as "204" or "200-299"; the
+// method defaults to GET and redirects are followed unless disabled.
+type httpCheckRequest struct {
+	Method          string            `json:"method"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Body            string            `json:"body,omitempty"`
+	AcceptedStatus  []string          `json:"accepted_status,omitempty"`
+	FollowRedirects *bool             `json:"follow_redirects,omitempty"`
+	Assertions      []assertionDTO    `json:"assertions,omitempty"`
+}
+
+// assertionDTO is a body assertion: contains, not_contains and regex take a
+// value, json_path a path and the expected value, max_size a size in bytes.
+type assertionDTO struct {
+	Type  string `json:"type"`
+	Path  string `json:"path,omitempty"`
+	Value string `json:"value,omitempty"`
+	Size  int64  `json:"size,omitempty"`
+}
+
+func (r *httpCheckRequest) toDomain() (*domain.HTTPCheck, error) {
+	check := domain.DefaultHTTPCheck()
+
+	if r.Method != "" {
+		check.Method = strings.ToUpper(r.Method)
+	}
+
+	if r.FollowRedirects != nil {
+		check.FollowRedirects = *r.FollowRedirects
+	}
+
+	check.Headers = r.Headers
+	check.Body = r.Body
+
+	for _, a := range r.Assertions {
+		check.Assertions = append(check.Assertions, domain.Assertion(a))
+	}
+
+	for _, s := range r.AcceptedStatus {
+		statusRange, err := domain.ParseStatusRange(s)
+		if err != nil {
+			return nil, err
+		}
+
+		check.AcceptedStatus = append(check.AcceptedStatus, statusRange)
+	}
+
+	return check, check.Validate()
+}
+
+type targetResponse struct {
+	ID        string             `json:"id"`
+	URL       string             `json:"url"`
+	Name      string             `json:"name"`
+	Interval  int                `json:"interval"`
+	IsActive  bool               `json:"is_active"`
+	CreatedAt time.Time          `json:"created_at"`
+	HTTP      *httpCheckResponse `json:"http,omitempty"`
+	Policy    *checkPolicyDTO    `json:"policy,omitempty"`
+
+	State          string    `json:"state"`
+	StateChangedAt time.Time `json:"state_changed_at"`
+
+	Latency *latencyThresholdDTO `json:"latency,omitempty"`
+
+	AlertEmails []string `json:"alert_emails,omitempty"`
+
+	EscalationPolicyID string `json:"escalation_policy_id,omitempty"`
+
+	Tags []string `json:"tags,omitempty"`
+}
+
+type httpCheckResponse struct {
+	Method          string            `json:"method"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Body            string            `json:"body,omitempty"`
+	AcceptedStatus  []string          `json:"accepted_status"`
+	FollowRedirects bool              `json:"follow_redirects"`
+	Assertions      []assertionDTO    `json:"assertions,omitempty"`
+}
+
+func newTargetResponse(t *domain.Target) targetResponse {
+	res := targetResponse{
+		ID:        t.ID,
+		URL:       t.URL,
+		Name:      t.Name,
+		Interval:  int(t.Interval / time.Second),
+		IsActive:  t.IsActive,
+		CreatedAt: t.CreatedAt,
+
+		State:          t.State,
+		StateChangedAt: t.StateChangedAt,
+
+		AlertEmails: t.AlertEmails,
+
+		EscalationPolicyID: t.EscalationPolicyID,
+
+		Tags: t.Tags,
+	}
+
+	if t.HTTP != nil {
+		accepted := t.HTTP.AcceptedStatus
+		if len(accepted) == 0 {
+			accepted = domain.DefaultAcceptedStatus
+		}
+
+		res.HTTP = &httpCheckResponse{
+			Method:          t.HTTP.Method,
+			Headers:         t.HTTP.Headers,
+			Body:            t.HTTP.Body,
+			FollowRedirects: t.HTTP.FollowRedirects,
+		}
+
+		for _, r := range accepted {
+			res.HTTP.AcceptedStatus = append(res.HTTP.AcceptedStatus, r.String())
+		}
+
+		for _, a := range t.HTTP.Assertions {
+			res.HTTP.Assertions = append(res.HTTP.Assertions, assertionDTO(a))
+		}
+	}
+
+	if t.Policy != nil {
+		res.Policy = newCheckPolicyDTO(t.Policy)
+	}
+
+	if t.Latency != nil {
+		res.Latency = newLatencyThresholdDTO(t.Latency)
+	}
+
+	return res
+}
+
+type stateChangeResponse struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Reason    string    `json:"reason"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+func newStateChangeResponse(c *domain.StateChange) stateChangeResponse {
+	return stateChangeResponse{
+		From:      c.From,
+		To:        c.To,
+		Reason:    c.Reason,
+		ChangedAt: c.ChangedAt,
+	}
+}
+
+type resultResponse struct {
+	ID             string    `json:"id"`
+	TargetID       string    `json:"target_id"`
+	Status         string    `json:"status"`
+	StatusCode     int       `json:"status_code"`
+	ResponseTimeMs int64     `json:"response_time_ms"`
+	CheckedAt      time.Time `json:"checked_at"`
+	Error          string    `json:"error,omitempty"`
+	ErrorKind      string    `json:"error_kind,omitempty"`
+	InMaintenance  bool      `json:"in_maintenance,omitempty"`
+
+	// TimingsMs is the phase breakdown of HTTP checks in fractional
+	// milliseconds, keyed by phase.
+	TimingsMs map[string]float64 `json:"timings_ms,omitempty"`
+}
+
+func newResultResponse(r *domain.Result) resultResponse {
+	res := resultResponse{
+		ID:             r.ID,
+		TargetID:       r.TargetID,
+		Status:         r.Status,
+		StatusCode:     r.StatusCode,
+		ResponseTimeMs: r.ResponseTime.Milliseconds(),
+		CheckedAt:      r.CheckedAt,
+		ErrorKind:      r.ErrorKind,
+		InMaintenance:  r.InMaintenance,
+	}
+
+	if r.Error != nil {
+		res.Error = r.Error.Error()
+	}
+
+	if r.Timings != nil {
+		res.TimingsMs = make(map[string]float64, len(domain.TimingPhases))
+		for _, phase := range domain.TimingPhases {
+			res.TimingsMs[phase] = float64(r.Timings.Phase(phase)) / float64(time.Millisecond)
+		}
+	}
+
+	return res
+}
+
+type alertResponse struct {
+	ID         string     `json:"id"`
+	TargetID   string     `json:"target_id"`
+	Type       string     `json:"type"`
+	Message    string     `json:"message"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	IsResolved bool       `json:"is_resolved"`
+
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	SnoozedUntil   *time.Time `json:"snoozed_until,omitempty"`
+	Assignee       string     `json:"assignee,omitempty"`
+}
+
+func newAlertResponse(a *domain.Alert) alertResponse {
+	return alertResponse{
+		ID:         a.ID,
+		TargetID:   a.TargetID,
+		Type:       a.Type,
+		Message:    a.Message,
+		CreatedAt:  a.CreatedAt,
+		ResolvedAt: a.ResolvedAt,
+		IsResolved: a.IsResolved,
+
+		AcknowledgedBy: a.AcknowledgedBy,
+		AcknowledgedAt: a.AcknowledgedAt,
+		SnoozedUntil:   a.SnoozedUntil,
+		Assignee:       a.Assignee,
+	}
+}
+
+// escalationStepDTO notifies channels delay_minutes after the step before it.
+type escalationStepDTO struct {
+	DelayMinutes int      `json:"delay_minutes"`
+	Channels     []string `json:"channels"`
+}
+
+type escalationPolicyRequest struct {
+	Name  string              `json:"name"`
+	Steps []escalationStepDTO `json:"steps"`
+}
+
+func (r *escalationPolicyRequest) toDomain(id string) *domain.EscalationPolicy {
+	steps := make([]domain.EscalationStep, 0, len(r.Steps))
+	for _, step := range r.Steps {
+		steps = append(steps, domain.EscalationStep{
+			Delay:    time.Duration(step.DelayMinutes) * time.Minute,
+			Channels: step.Channels,
+		})
+	}
+
+	return domain.NewEscalationPolicy(id, r.Name, steps)
+}
+
+type escalationPolicyResponse struct {
+	ID        string              `json:"id"`
+	Name      string              `json:"name"`
+	Steps     []escalationStepDTO `json:"steps"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+func newEscalationPolicyResponse(p *domain.EscalationPolicy) escalationPolicyResponse {
+	steps := make([]escalationStepDTO, 0, len(p.Steps))
+	for _, step := range p.Steps {
+		steps = append(steps, escalationStepDTO{
+			DelayMinutes: int(step.Delay / time.Minute),
+			Channels:     step.Channels,
+		})
+	}
+
+	return escalationPolicyResponse{
+		ID:        p.ID,
+		Name:      p.Name,
+		Steps:     steps,
+		CreatedAt: p.CreatedAt,
+	}
+}
+
+// maintenanceWindowRequest describes a one-off window from start, or a
+// recurring one when schedule is set; start then defaults to now.
+type maintenanceWindowRequest struct {
+	Name            string    `json:"name"`
+	Start           time.Time `json:"start"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Schedule        string    `json:"schedule,omitempty"`
+	Timezone        string    `json:"timezone,omitempty"`
+	TargetIDs       []string  `json:"target_ids,omitempty"`
+	Tags            []string  `json:"tags,omitempty"`
+}
+
+func (r *maintenanceWindowRequest) toDomain(id string, now time.Time) *domain.MaintenanceWindow {
+	start := r.Start
+	if start.IsZero() && r.Schedule != "" {
+		start = now
+	}
+
+	window := domain.NewMaintenanceWindow(id, r.Name, start, time.Duration(r.DurationMinutes)*time.Minute)
+	window.Schedule = r.Schedule
+	window.Location = r.Timezone
+	window.TargetIDs = r.TargetIDs
+	window.Tags = r.Tags
+
+	return window
+}
+
+type maintenanceWindowResponse struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	Start           time.Time `json:"start"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Schedule        string    `json:"schedule,omitempty"`
+	Timezone        string    `json:"timezone,omitempty"`
+	TargetIDs       []string  `json:"target_ids,omitempty"`
+	Tags            []string  `json:"tags,omitempty"`
+	Active          bool      `json:"active"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func newMaintenanceWindowResponse(w *domain.MaintenanceWindow, now time.Time) maintenanceWindowResponse {
+	return maintenanceWindowResponse{
+		ID:              w.ID,
+		Name:            w.Name,
+		Start:           w.Start,
+		DurationMinutes: int(w.Duration / time.Minute),
+		Schedule:        w.Schedule,
+		Timezone:        w.Location,
+		TargetIDs:       w.TargetIDs,
+		Tags:            w.Tags,
+		Active:          w.IsActive(now),
+		CreatedAt:       w.CreatedAt,
+	}
+}
+
+type targetEscalationRequest struct {
+	EscalationPolicyID string `json:"escalation_policy_id"`
+}
+
+type acknowledgeRequest struct {
+	By string `json:"by"`
+}
+
+// snoozeRequest silences an alert until a time or for a number of minutes.
+type snoozeRequest struct {
+	Until   *time.Time `json:"until,omitempty"`
+	Minutes int        `json:"minutes,omitempty"`
+}
+
+type assignRequest struct {
+	Assignee string `json:"assignee"`
+}
+
+type deadLetterResponse struct {
+	ID        string        `json:"id"`
+	Channel   string        `json:"channel"`
+	Event     string        `json:"event"`
+	Alert     alertResponse `json:"alert"`
+	Attempts  int           `json:"attempts"`
+	LastError string        `json:"last_error"`
+	CreatedAt time.Time     `json:"created_at"`
+	DeadAt    time.Time     `json:"dead_at"`
+}
+
+func newDeadLetterResponse(m *domain.OutboxMessage) deadLetterResponse {
+	return deadLetterResponse{
+		ID:        m.ID,
+		Channel:   m.Channel,
+		Event:     m.Notification.Event,
+		Alert:     newAlertResponse(&m.Notification.Alert),
+		Attempts:  m.Attempts,
+		LastError: m.LastError,
+		CreatedAt: m.CreatedAt,
+		DeadAt:    *m.DeadAt,
+	}
+}
+
+type responseTimeResponse struct {
+	MeanMs int64 `json:"mean_ms"`
+	P50Ms  int64 `json:"p50_ms"`
+	P95Ms  int64 `json:"p95_ms"`
+	P99Ms  int64 `json:"p99_ms"`
+}
+
+type statsResponse struct {
+	TargetID        string               `json:"target_id"`
+	From            time.Time            `json:"from"`
+	To              time.Time            `json:"to"`
+	TotalChecks     int                  `json:"total_checks"`
+	StatusCounts    map[string]int       `json:"status_counts"`
+	UptimePercent   float64              `json:"uptime_percent"`
+	ResponseTime    responseTimeResponse `json:"response_time"`
+	Incidents       int                  `json:"incidents"`
+	DowntimeSeconds float64              `json:"downtime_seconds"`
+	MTTRSeconds     float64              `json:"mttr_seconds"`
+	MTBFSeconds     float64              `json:"mtbf_seconds"`
+
+	Phases map[string]responseTimeResponse `json:"phases,omitempty"`
+}
+
+func newStatsResponse(s *usecase.Stats) statsResponse {
+	res := statsResponse{
+		TargetID:        s.TargetID,
+		From:            s.From,
+		To:              s.To,
+		TotalChecks:     s.TotalChecks,
+		StatusCounts:    s.StatusCounts,
+		UptimePercent:   s.UptimePercent,
+		ResponseTime:    newResponseTimeResponse(s.ResponseTime),
+		Incidents:       s.Incidents,
+		DowntimeSeconds: s.Downtime.Seconds(),
+		MTTRSeconds:     s.MTTR.Seconds(),
+		MTBFSeconds:     s.MTBF.Seconds(),
+	}
+
+	if len(s.Phases) > 0 {
+		res.Phases = make(map[string]responseTimeResponse, len(s.Phases))
+		for phase, stats := range s.Phases {
+			res.Phases[phase] = newResponseTimeResponse(stats)
+		}
+	}
+
+	return res
+}
+
+func newResponseTimeResponse(s usecase.ResponseTimeStats) responseTimeResponse {
+	return responseTimeResponse{
+		MeanMs: s.Mean.Milliseconds(),
+		P50Ms:  s.P50.Milliseconds(),
+		P95Ms:  s.P95.Milliseconds(),
+		P99Ms:  s.P99.Milliseconds(),
+	}
+}
@@ -0,0 +1,872 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+	"github.com/karoljaro/go-uptime-monitor/usecase"
+)
+
+const (
+	defaultStatsWindow = 24 * time.Hour
+	nextCursorHeader   = "X-Next-Cursor"
+)
+
+type TargetReloader interface {
+	Reload() error
+}
+
+type HandlerOption func(*Handler)
+
+func WithTargetReloader(r TargetReloader) HandlerOption {
+	return func(h *Handler) {
+		h.reloader = r
+	}
+}
+
+// WithStateChangeRepository serves the targets' state history on
+// GET /targets/{id}/states.
+func WithStateChangeRepository(repo domain.StateChangeRepository) HandlerOption {
+	return func(h *Handler) {
+		h.stateRepo = repo
+	}
+}
+
+// WithOutbox serves the notifications that could not be delivered on
+// GET /notifications/dead-letters and replays them on
+// POST /notifications/dead-letters/{id}/replay.
+func WithOutbox(outbox *usecase.OutboxUseCase) HandlerOption {
+	return func(h *Handler) {
+		h.outbox = outbox
+	}
+}
+
+// WithEscalationPolicies serves escalation policies on /escalation-policies
+// and lets targets be attached to them. With channels, steps may only name
+// those.
+func WithEscalationPolicies(repo domain.EscalationPolicyRepository, channels ...string) HandlerOption {
+	return func(h *Handler) {
+		h.policyRepo = repo
+		h.channels = channels
+	}
+}
+
+// WithMaintenanceWindows serves maintenance windows on /maintenance-windows.
+func WithMaintenanceWindows(repo domain.MaintenanceWindowRepository) HandlerOption {
+	return func(h *Handler) {
+		h.maintenanceRepo = repo
+	}
+}
+
+type Handler struct {
+	monitor     usecase.TargetChecker
+	stats       *usecase.StatsUseCase
+	targetRepo  domain.TargetRepository
+	resultRepo  domain.ResultRepository
+	alertRepo   domain.AlertRepository
+	stateRepo   domain.StateChangeRepository
+	idGenerator domain.IDGenerator
+	reloader    TargetReloader
+	outbox      *usecase.OutboxUseCase
+
+	policyRepo domain.EscalationPolicyRepository
+	channels   []string
+
+	maintenanceRepo domain.MaintenanceWindowRepository
+}
+
+func NewHandler(
+	monitor usecase.TargetChecker,
+	stats *usecase.StatsUseCase,
+	targetRepo domain.TargetRepository,
+	resultRepo domain.ResultRepository,
+	alertRepo domain.AlertRepository,
+	idGenerator domain.IDGenerator,
+	opts ...HandlerOption,
+) *Handler {
+	h := &Handler{
+		monitor:     monitor,
+		stats:       stats,
+		targetRepo:  targetRepo,
+		resultRepo:  resultRepo,
+		alertRepo:   alertRepo,
+		idGenerator: idGenerator,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+func (h *Handler) Routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /ping", h.ping)
+	mux.HandleFunc("POST /targets", h.createTarget)
+	mux.HandleFunc("GET /targets", h.listTargets)
+	mux.HandleFunc("GET /targets/{id}", h.getTarget)
+	mux.HandleFunc("POST /targets/{id}/check", h.checkTarget)
+	mux.HandleFunc("POST /targets/{id}/pause", h.pauseTarget)
+	mux.HandleFunc("POST /targets/{id}/resume", h.resumeTarget)
+	if h.stateRepo != nil {
+		mux.HandleFunc("GET /targets/{id}/states", h.listStateChanges)
+	}
+	mux.HandleFunc("GET /results/{id}", h.listResults)
+	mux.HandleFunc("GET /alerts", h.listAlerts)
+	mux.HandleFunc("POST /alerts/{id}/acknowledge", h.acknowledgeAlert)
+	mux.HandleFunc("POST /alerts/{id}/snooze", h.snoozeAlert)
+	mux.HandleFunc("POST /alerts/{id}/assign", h.assignAlert)
+	mux.HandleFunc("GET /stats/{id}", h.getStats)
+	if h.policyRepo != nil {
+		mux.HandleFunc("POST /escalation-policies", h.createEscalationPolicy)
+		mux.HandleFunc("GET /escalation-policies", h.listEscalationPolicies)
+		mux.HandleFunc("GET /escalation-policies/{id}", h.getEscalationPolicy)
+		mux.HandleFunc("DELETE /escalation-policies/{id}", h.deleteEscalationPolicy)
+		mux.HandleFunc("PUT /targets/{id}/escalation-policy", h.setTargetEscalationPolicy)
+	}
+	if h.maintenanceRepo != nil {
+		mux.HandleFunc("POST /maintenance-windows", h.createMaintenanceWindow)
+		mux.HandleFunc("GET /maintenance-windows", h.listMaintenanceWindows)
+		mux.HandleFunc("GET /maintenance-windows/{id}", h.getMaintenanceWindow)
+		mux.HandleFunc("DELETE /maintenance-windows/{id}", h.deleteMaintenanceWindow)
+	}
+	if h.outbox != nil {
+		mux.HandleFunc("GET /notifications/dead-letters", h.listDeadLetters)
+		mux.HandleFunc("POST /notifications/dead-letters/{id}/replay", h.replayDeadLetter)
+	}
+
+	return mux
+}
+
+func (h *Handler) ping(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *Handler) createTarget(w http.ResponseWriter, r *http.Request) {
+	var req targetRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	target := domain.NewTarget(
+		h.idGenerator.Generate(),
+		req.URL,
+		req.Name,
+		time.Duration(req.Interval)*time.Second,
+	)
+
+	var paused *domain.StateChange
+	if req.IsActive != nil && !*req.IsActive {
+		paused, _ = target.Pause(target.CreatedAt)
+	}
+
+	if !target.IsValid() {
+		writeError(w, http.StatusBadRequest, "url and a positive interval are required")
+		return
+	}
+
+	if req.HTTP != nil {
+		if target.Type() != domain.TargetTypeHTTP {
+			writeError(w, http.StatusBadRequest, "http check settings only apply to http and https targets")
+			return
+		}
+
+		check, err := req.HTTP.toDomain()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		target.HTTP = check
+	}
+
+	if req.Policy != nil {
+		policy, err := req.Policy.toDomain()
+		if err == nil {
+			err = policy.ValidateInterval(target.Interval)
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		target.Policy = policy
+	}
+
+	if req.Latency != nil {
+		latency, err := req.Latency.toDomain()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		target.Latency = latency
+	}
+
+	target.AlertEmails = req.AlertEmails
+	if err := target.ValidateAlertEmails(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.EscalationPolicyID != "" {
+		if err := h.escalationPolicyExists(req.EscalationPolicyID); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		target.EscalationPolicyID = req.EscalationPolicyID
+	}
+
+	target.Tags = req.Tags
+
+	if err := h.targetRepo.Save(target); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if paused != nil && h.stateRepo != nil {
+		paused.ID = h.idGenerator.Generate()
+		h.stateRepo.Save(paused)
+	}
+
+	h.reload()
+
+	writeJSON(w, http.StatusCreated, newTargetResponse(target))
+}
+
+func (h *Handler) listTargets(w http.ResponseWriter, r *http.Request) {
+	targets, err := h.targetRepo.GetAll()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	res := make([]targetResponse, 0, len(targets))
+	for _, target := range targets {
+		res = append(res, newTargetResponse(target))
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *Handler) getTarget(w http.ResponseWriter, r *http.Request) {
+	target, err := h.targetRepo.FindByID(r.PathValue("id"))
+	if err != nil {
+		writeRepoError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newTargetResponse(target))
+}
+
+func (h *Handler) checkTarget(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if !h.targetExists(w, id) {
+		return
+	}
+
+	if err := h.monitor.CheckTarget(r.Context(), id); err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	result, err := h.resultRepo.GetLastByTargetID(id)
+	if err != nil {
+		writeRepoError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newResultResponse(result))
+}
+
+func (h *Handler) pauseTarget(w http.ResponseWriter, r *http.Request) {
+	h.changeTargetState(w, r, func(target *domain.Target, at time.Time) (*domain.StateChange, error) {
+		return target.Pause(at)
+	})
+}
+
+// resumeTarget restarts checks of a paused target; other targets are left as
+// they are.
+func (h *Handler) resumeTarget(w http.ResponseWriter, r *http.Request) {
+	h.changeTargetState(w, r, func(target *domain.Target, at time.Time) (*domain.StateChange, error) {
+		if target.State != domain.StatePaused {
+			return nil, nil
+		}
+
+		return target.Resume(at)
+	})
+}
+
+// changeTargetState applies change to the target, saving its state change
+// and rescheduling checks when the state moved. It answers with a conflict
+// when the state moved on, for example after a check, since the target was
+// read.
+func (h *Handler) changeTargetState(w http.ResponseWriter, r *http.Request, change func(target *domain.Target, at time.Time) (*domain.StateChange, error)) {
+	target, err := h.targetRepo.FindByID(r.PathValue("id"))
+	if err != nil {
+		writeRepoError(w, err)
+		return
+	}
+
+	changed, err := change(target, time.Now())
+	if err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	if changed != nil {
+		saved, err := h.targetRepo.ChangeState(changed)
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		if !saved {
+			writeError(w, http.StatusConflict, "target state changed, try again")
+			return
+		}
+
+		if h.stateRepo != nil {
+			changed.ID = h.idGenerator.Generate()
+			h.stateRepo.Save(changed)
+		}
+
+		h.reload()
+	}
+
+	writeJSON(w, http.StatusOK, newTargetResponse(target))
+}
+
+func (h *Handler) listStateChanges(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if !h.targetExists(w, id) {
+		return
+	}
+
+	changes, err := h.stateRepo.FindByTargetID(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	res := make([]stateChangeResponse, 0, len(changes))
+	for _, change := range changes {
+		res = append(res, newStateChangeResponse(change))
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *Handler) listResults(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if !h.targetExists(w, id) {
+		return
+	}
+
+	query, err := parseResultQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.resultRepo.QueryByTargetID(id, query)
+	if errors.Is(err, domain.ErrInvalidCursor) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	res := make([]resultResponse, 0, len(page.Results))
+	for _, result := range page.Results {
+		res = append(res, newResultResponse(result))
+	}
+
+	if page.NextCursor != "" {
+		w.Header().Set(nextCursorHeader, page.NextCursor)
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// listAlerts serves the open alerts nobody acknowledged yet, or all open
+// alerts with ?include_acknowledged=true.
+func (h *Handler) listAlerts(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("include_acknowledged") == "true" {
+		h.listUnresolvedAlerts(w)
+		return
+	}
+
+	alerts, err := h.alertRepo.GetUnacknowledged()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	res := make([]alertResponse, 0, len(alerts))
+	for _, alert := range alerts {
+		res = append(res, newAlertResponse(alert))
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *Handler) listUnresolvedAlerts(w http.ResponseWriter) {
+	targets, err := h.targetRepo.GetAll()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	res := make([]alertResponse, 0)
+	for _, target := range targets {
+		alerts, err := h.alertRepo.GetUnresolvedByTargetID(target.ID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		for _, alert := range alerts {
+			res = append(res, newAlertResponse(alert))
+		}
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *Handler) acknowledgeAlert(w http.ResponseWriter, r *http.Request) {
+	var req acknowledgeRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.By == "" {
+		writeError(w, http.StatusBadRequest, "by is required")
+		return
+	}
+
+	h.updateAlert(w, r.PathValue("id"), func(id string) error {
+		return h.alertRepo.Acknowledge(id, req.By, time.Now())
+	})
+}
+
+func (h *Handler) snoozeAlert(w http.ResponseWriter, r *http.Request) {
+	var req snoozeRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	until := time.Now().Add(time.Duration(req.Minutes) * time.Minute)
+	switch {
+	case (req.Until == nil) == (req.Minutes == 0):
+		writeError(w, http.StatusBadRequest, "either until or minutes is required")
+		return
+	case req.Minutes < 0:
+		writeError(w, http.StatusBadRequest, "minutes must be positive")
+		return
+	case req.Until != nil:
+		until = *req.Until
+	}
+
+	h.updateAlert(w, r.PathValue("id"), func(id string) error {
+		return h.alertRepo.Snooze(id, until)
+	})
+}
+
+func (h *Handler) assignAlert(w http.ResponseWriter, r *http.Request) {
+	var req assignRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.Assignee == "" {
+		writeError(w, http.StatusBadRequest, "assignee is required")
+		return
+	}
+
+	h.updateAlert(w, r.PathValue("id"), func(id string) error {
+		return h.alertRepo.Assign(id, req.Assignee)
+	})
+}
+
+// updateAlert applies change to the alert and answers with the result.
+// Resolved alerts can't be changed.
+func (h *Handler) updateAlert(w http.ResponseWriter, id string, change func(id string) error) {
+	if err := change(id); err != nil {
+		if errors.Is(err, domain.ErrAlertResolved) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+
+		writeRepoError(w, err)
+		return
+	}
+
+	alert, err := h.alertRepo.FindByID(id)
+	if err != nil {
+		writeRepoError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAlertResponse(alert))
+}
+
+func (h *Handler) getStats(w http.ResponseWriter, r *http.Request) {
+	to := time.Now()
+	from := to.Add(-defaultStatsWindow)
+
+	query := r.URL.Query()
+
+	if v := query.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "from must be an RFC 3339 timestamp")
+			return
+		}
+		from = t
+	}
+
+	if v := query.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "to must be an RFC 3339 timestamp")
+			return
+		}
+		to = t
+	}
+
+	if !from.Before(to) {
+		writeError(w, http.StatusBadRequest, "from must be before to")
+		return
+	}
+
+	stats, err := h.stats.GetStats(r.PathValue("id"), from, to)
+	if err != nil {
+		writeRepoError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newStatsResponse(stats))
+}
+
+func (h *Handler) createEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	var req escalationPolicyRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	policy := req.toDomain(h.idGenerator.Generate())
+	if err := policy.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(h.channels) > 0 {
+		for _, step := range policy.Steps {
+			for _, channel := range step.Channels {
+				if !slices.Contains(h.channels, channel) {
+					writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown channel %q, configured are %v", channel, h.channels))
+					return
+				}
+			}
+		}
+	}
+
+	if err := h.policyRepo.Save(policy); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newEscalationPolicyResponse(policy))
+}
+
+func (h *Handler) listEscalationPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.policyRepo.GetAll()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	res := make([]escalationPolicyResponse, 0, len(policies))
+	for _, policy := range policies {
+		res = append(res, newEscalationPolicyResponse(policy))
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *Handler) getEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	policy, err := h.policyRepo.FindByID(r.PathValue("id"))
+	if err != nil {
+		writeRepoError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newEscalationPolicyResponse(policy))
+}
+
+// deleteEscalationPolicy refuses to delete a policy that targets are still
+// attached to.
+func (h *Handler) deleteEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	targets, err := h.targetRepo.GetAll()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	for _, target := range targets {
+		if target.EscalationPolicyID == id {
+			writeError(w, http.StatusConflict, fmt.Sprintf("escalation policy %s is attached to target %s", id, target.ID))
+			return
+		}
+	}
+
+	if err := h.policyRepo.Delete(id); err != nil {
+		writeRepoError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setTargetEscalationPolicy attaches the target to a policy, or detaches it
+// with an empty escalation_policy_id.
+func (h *Handler) setTargetEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	var req targetEscalationRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id := r.PathValue("id")
+	if _, err := h.targetRepo.FindByID(id); err != nil {
+		writeRepoError(w, err)
+		return
+	}
+
+	if req.EscalationPolicyID != "" {
+		if err := h.escalationPolicyExists(req.EscalationPolicyID); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if err := h.targetRepo.SetEscalationPolicy(id, req.EscalationPolicyID); err != nil {
+		writeRepoError(w, err)
+		return
+	}
+
+	target, err := h.targetRepo.FindByID(id)
+	if err != nil {
+		writeRepoError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newTargetResponse(target))
+}
+
+func (h *Handler) escalationPolicyExists(id string) error {
+	if h.policyRepo == nil {
+		return errors.New("escalation policies are not enabled")
+	}
+
+	if _, err := h.policyRepo.FindByID(id); err != nil {
+		return fmt.Errorf("escalation policy %s: %w", id, err)
+	}
+
+	return nil
+}
+
+func (h *Handler) createMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	var req maintenanceWindowRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	now := time.Now()
+
+	window := req.toDomain(h.idGenerator.Generate(), now)
+	if err := window.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	for _, id := range window.TargetIDs {
+		if _, err := h.targetRepo.FindByID(id); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if err := h.maintenanceRepo.Save(window); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newMaintenanceWindowResponse(window, now))
+}
+
+func (h *Handler) listMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	windows, err := h.maintenanceRepo.GetAll()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	now := time.Now()
+
+	res := make([]maintenanceWindowResponse, 0, len(windows))
+	for _, window := range windows {
+		res = append(res, newMaintenanceWindowResponse(window, now))
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *Handler) getMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	window, err := h.maintenanceRepo.FindByID(r.PathValue("id"))
+	if err != nil {
+		writeRepoError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newMaintenanceWindowResponse(window, time.Now()))
+}
+
+func (h *Handler) deleteMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	if err := h.maintenanceRepo.Delete(r.PathValue("id")); err != nil {
+		writeRepoError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) listDeadLetters(w http.ResponseWriter, r *http.Request) {
+	messages, err := h.outbox.DeadLetters()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	res := make([]deadLetterResponse, 0, len(messages))
+	for _, message := range messages {
+		res = append(res, newDeadLetterResponse(message))
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *Handler) replayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if _, err := h.outbox.Replay(r.PathValue("id")); err != nil {
+		writeRepoError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func parseResultQuery(r *http.Request) (domain.ResultQuery, error) {
+	values := r.URL.Query()
+
+	query := domain.ResultQuery{
+		Status: values.Get("status"),
+		Cursor: values.Get("cursor"),
+	}
+
+	if v := values.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return query, errors.New("from must be an RFC 3339 timestamp")
+		}
+		query.From = t
+	}
+
+	if v := values.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return query, errors.New("to must be an RFC 3339 timestamp")
+		}
+		query.To = t
+	}
+
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return query, errors.New("from must be before to")
+	}
+
+	if v := values.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > domain.MaxResultQueryLimit {
+			return query, fmt.Errorf("limit must be between 1 and %d", domain.MaxResultQueryLimit)
+		}
+		query.Limit = n
+	}
+
+	return query, nil
+}
+
+func (h *Handler) targetExists(w http.ResponseWriter, id string) bool {
+	if _, err := h.targetRepo.FindByID(id); err != nil {
+		writeRepoError(w, err)
+		return false
+	}
+
+	return true
+}
+
+func (h *Handler) reload() {
+	if h.reloader != nil {
+		h.reloader.Reload()
+	}
+}
+
+func decodeJSON(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		return errors.New("invalid JSON body: " + err.Error())
+	}
+
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, errorResponse{Error: msg})
+}
+
+func writeRepoError(w http.ResponseWriter, err error) {
+	if errors.Is(err, domain.ErrNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
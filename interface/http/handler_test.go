@@ -0,0 +1,928 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+	"github.com/karoljaro/go-uptime-monitor/infrastructure/clock"
+	"github.com/karoljaro/go-uptime-monitor/infrastructure/storage"
+	"github.com/karoljaro/go-uptime-monitor/usecase"
+)
+
+// ----- > MOCK DEF < -----
+
+type MockTargetChecker struct {
+	CheckTargetFunc func(ctx context.Context, targetID string) error
+}
+
+func (m *MockTargetChecker) CheckTarget(ctx context.Context, targetID string) error {
+	if m.CheckTargetFunc != nil {
+		return m.CheckTargetFunc(ctx, targetID)
+	}
+
+	return nil
+}
+
+type MockIDGenerator struct {
+	n int
+}
+
+func (m *MockIDGenerator) Generate() string {
+	m.n++
+	return fmt.Sprintf("id-%d", m.n)
+}
+
+type MockReloader struct {
+	Calls int
+}
+
+func (m *MockReloader) Reload() error {
+	m.Calls++
+	return nil
+}
+
+// ----- > Helpers < -----
+
+type testServer struct {
+	targetRepo *storage.MemoryTargetRepository
+	resultRepo *storage.MemoryResultRepository
+	alertRepo  *storage.MemoryAlertRepository
+	stateRepo  *storage.MemoryStateChangeRepository
+	outboxRepo *storage.MemoryOutboxRepository
+	policyRepo *storage.MemoryEscalationPolicyRepository
+	windowRepo *storage.MemoryMaintenanceWindowRepository
+	checker    *MockTargetChecker
+	reloader   *MockReloader
+	handler    http.Handler
+}
+
+func newTestServer() *testServer {
+	s := &testServer{
+		targetRepo: storage.NewMemoryTargetRepository(),
+		resultRepo: storage.NewMemoryResultRepository(),
+		alertRepo:  storage.NewMemoryAlertRepository(),
+		stateRepo:  storage.NewMemoryStateChangeRepository(),
+		policyRepo: storage.NewMemoryEscalationPolicyRepository(),
+		windowRepo: storage.NewMemoryMaintenanceWindowRepository(),
+		checker:    &MockTargetChecker{},
+		reloader:   &MockReloader{},
+	}
+	s.outboxRepo = storage.NewMemoryOutboxRepository(s.alertRepo)
+
+	s.handler = NewHandler(
+		s.checker,
+		usecase.NewStatsUseCase(s.targetRepo, s.resultRepo, s.alertRepo),
+		s.targetRepo,
+		s.resultRepo,
+		s.alertRepo,
+		&MockIDGenerator{},
+		WithTargetReloader(s.reloader),
+		WithStateChangeRepository(s.stateRepo),
+		WithOutbox(usecase.NewOutboxUseCase(s.outboxRepo, nil, clock.NewSystemClock())),
+		WithEscalationPolicies(s.policyRepo, "chat", "email"),
+		WithMaintenanceWindows(s.windowRepo),
+	).Routes()
+
+	return s
+}
+
+func (s *testServer) do(method, path string, body any) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+
+	req := httptest.NewRequest(method, path, &buf)
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+
+	var v T
+	if err := json.NewDecoder(rec.Body).Decode(&v); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	return v
+}
+
+// ----- > Test cases < -----
+
+func TestHandler_Ping(t *testing.T) {
+	s := newTestServer()
+
+	rec := s.do("GET", "/ping", nil)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected JSON content type, got %s", ct)
+	}
+}
+
+func TestHandler_CreateTarget(t *testing.T) {
+	s := newTestServer()
+
+	rec := s.do("POST", "/targets", map[string]any{"url": "https://api.github.com", "interval": 10})
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	res := decode[targetResponse](t, rec)
+
+	if res.ID != "id-1" {
+		t.Errorf("expected ID id-1, got %s", res.ID)
+	}
+
+	if res.Interval != 10 {
+		t.Errorf("expected interval 10, got %d", res.Interval)
+	}
+
+	if res.State != domain.StateUnknown {
+		t.Errorf("expected a new target to be UNKNOWN, got %s", res.State)
+	}
+
+	saved, err := s.targetRepo.FindByID(res.ID)
+	if err != nil {
+		t.Fatalf("expected target to be saved, got %v", err)
+	}
+
+	if saved.Interval != 10*time.Second {
+		t.Errorf("expected interval 10s, got %s", saved.Interval)
+	}
+
+	if s.reloader.Calls != 1 {
+		t.Errorf("expected scheduler reload, got %d calls", s.reloader.Calls)
+	}
+}
+
+func TestHandler_CreateTarget_HTTPCheck(t *testing.T) {
+	s := newTestServer()
+
+	rec := s.do("POST", "/targets", map[string]any{
+		"url":      "https://api.example.com/health",
+		"interval": 10,
+		"http": map[string]any{
+			"method":           "post",
+			"headers":          map[string]string{"Authorization": "Bearer secret"},
+			"body":             `{"deep":true}`,
+			"accepted_status":  []string{"200-204", "401"},
+			"follow_redirects": false,
+			"assertions": []map[string]any{
+				{"type": "json_path", "path": "$.status", "value": "ok"},
+				{"type": "max_size", "size": 4096},
+			},
+		},
+	})
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	res := decode[targetResponse](t, rec)
+
+	if res.HTTP == nil || res.HTTP.Method != "POST" || len(res.HTTP.AcceptedStatus) != 2 || res.HTTP.AcceptedStatus[1] != "401" {
+		t.Errorf("expected the HTTP check in the response, got %+v", res.HTTP)
+	}
+
+	saved, _ := s.targetRepo.FindByID(res.ID)
+
+	if saved.HTTP == nil || saved.HTTP.FollowRedirects || saved.HTTP.Headers["Authorization"] != "Bearer secret" || !saved.HTTP.Accepts(401) {
+		t.Errorf("expected the HTTP check to be saved, got %+v", saved.HTTP)
+	}
+
+	if len(saved.HTTP.Assertions) != 2 || saved.HTTP.Assertions[0].Path != "$.status" || saved.HTTP.Assertions[1].Size != 4096 {
+		t.Errorf("expected the assertions to be saved, got %+v", saved.HTTP.Assertions)
+	}
+}
+
+func TestHandler_CreateTarget_Policy(t *testing.T) {
+	s := newTestServer()
+
+	rec := s.do("POST", "/targets", map[string]any{
+		"url":      "tcp://db.example.com:5432",
+		"interval": 30,
+		"policy":   map[string]any{"retries": 2, "retry_backoff_ms": 500, "failure_threshold": 3, "recovery_threshold": 2},
+	})
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	res := decode[targetResponse](t, rec)
+	saved, _ := s.targetRepo.FindByID(res.ID)
+
+	want := domain.CheckPolicy{Retries: 2, RetryBackoff: 500 * time.Millisecond, FailureThreshold: 3, RecoveryThreshold: 2}
+	if saved.Policy == nil || *saved.Policy != want {
+		t.Errorf("expected policy %+v, got %+v", want, saved.Policy)
+	}
+
+	if res.Policy == nil || res.Policy.RetryBackoffMs != 500 {
+		t.Errorf("expected the policy in the response, got %+v", res.Policy)
+	}
+}
+
+func TestHandler_CreateTarget_Latency(t *testing.T) {
+	s := newTestServer()
+
+	rec := s.do("POST", "/targets", map[string]any{
+		"url":      "https://api.example.com",
+		"interval": 30,
+		"latency":  map[string]any{"max_ms": 2000, "p95_ms": 800, "p95_window": 20},
+	})
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	res := decode[targetResponse](t, rec)
+	saved, _ := s.targetRepo.FindByID(res.ID)
+
+	want := domain.LatencyThreshold{Max: 2 * time.Second, P95: 800 * time.Millisecond, P95Window: 20}
+	if saved.Latency == nil || *saved.Latency != want {
+		t.Errorf("expected latency threshold %+v, got %+v", want, saved.Latency)
+	}
+
+	if res.Latency == nil || res.Latency.P95Window != 20 {
+		t.Errorf("expected the latency threshold in the response, got %+v", res.Latency)
+	}
+}
+
+func TestHandler_CreateTarget_AlertEmails(t *testing.T) {
+	s := newTestServer()
+
+	rec := s.do("POST", "/targets", map[string]any{
+		"url":          "https://api.example.com",
+		"interval":     30,
+		"alert_emails": []string{"ops@example.com"},
+	})
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	res := decode[targetResponse](t, rec)
+	saved, _ := s.targetRepo.FindByID(res.ID)
+
+	if len(saved.AlertEmails) != 1 || saved.AlertEmails[0] != "ops@example.com" || len(res.AlertEmails) != 1 {
+		t.Errorf("expected the alert emails to be saved and returned, got %v and %v", saved.AlertEmails, res.AlertEmails)
+	}
+}
+
+func TestHandler_CreateTarget_Invalid(t *testing.T) {
+	s := newTestServer()
+
+	cases := []struct {
+		name string
+		body any
+	}{
+		{"missing url", map[string]any{"interval": 10}},
+		{"zero interval", map[string]any{"url": "https://example.com"}},
+		{"unknown field", map[string]any{"url": "https://example.com", "interval": 10, "foo": 1}},
+		{"not an object", "nope"},
+		{"bad status range", map[string]any{"url": "https://example.com", "interval": 10, "http": map[string]any{"accepted_status": []string{"2xx"}}}},
+		{"bad assertion", map[string]any{"url": "https://example.com", "interval": 10, "http": map[string]any{"assertions": []map[string]any{{"type": "regex", "value": "("}}}}},
+		{"negative retries", map[string]any{"url": "https://example.com", "interval": 10, "policy": map[string]any{"retries": -1}}},
+		{"retries longer than interval", map[string]any{"url": "https://example.com", "interval": 10, "policy": map[string]any{"retries": 3, "retry_backoff_ms": 2000}}},
+		{"p95 without window", map[string]any{"url": "https://example.com", "interval": 10, "latency": map[string]any{"p95_ms": 500}}},
+		{"bad alert email", map[string]any{"url": "https://example.com", "interval": 10, "alert_emails": []string{"ops"}}},
+		{"http check on tcp target", map[string]any{"url": "tcp://example.com:25", "interval": 10, "http": map[string]any{"method": "GET"}}},
+	}
+
+	for _, tc := range cases {
+		rec := s.do("POST", "/targets", tc.body)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", tc.name, rec.Code)
+		}
+	}
+
+	if targets, _ := s.targetRepo.GetAll(); len(targets) != 0 {
+		t.Errorf("expected no targets saved, got %d", len(targets))
+	}
+}
+
+func TestHandler_ListTargets(t *testing.T) {
+	s := newTestServer()
+
+	s.targetRepo.Save(domain.NewTarget("t-1", "https://example.com/1", "one", 10*time.Second))
+	s.targetRepo.Save(domain.NewTarget("t-2", "https://example.com/2", "two", 10*time.Second))
+
+	rec := s.do("GET", "/targets", nil)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	if res := decode[[]targetResponse](t, rec); len(res) != 2 {
+		t.Errorf("expected 2 targets, got %d", len(res))
+	}
+}
+
+func TestHandler_GetTarget(t *testing.T) {
+	s := newTestServer()
+
+	s.targetRepo.Save(domain.NewTarget("t-1", "https://example.com/1", "one", 10*time.Second))
+
+	rec := s.do("GET", "/targets/t-1", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	if res := decode[targetResponse](t, rec); res.ID != "t-1" || res.Name != "one" {
+		t.Errorf("unexpected target %+v", res)
+	}
+
+	if rec := s.do("GET", "/targets/nonExistent", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandler_ListResults(t *testing.T) {
+	s := newTestServer()
+
+	s.targetRepo.Save(domain.NewTarget("t-1", "https://example.com", "one", 10*time.Second))
+
+	rec := s.do("GET", "/results/t-1", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	if res := decode[[]resultResponse](t, rec); len(res) != 0 {
+		t.Errorf("expected no results, got %d", len(res))
+	}
+
+	s.resultRepo.Save(domain.NewResult("r-1", "t-1", "OK", 200, 120*time.Millisecond))
+
+	rec = s.do("GET", "/results/t-1", nil)
+	res := decode[[]resultResponse](t, rec)
+
+	if len(res) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(res))
+	}
+
+	if res[0].ResponseTimeMs != 120 {
+		t.Errorf("expected response time 120ms, got %d", res[0].ResponseTimeMs)
+	}
+}
+
+func TestHandler_ListResults_Paginated(t *testing.T) {
+	s := newTestServer()
+
+	s.targetRepo.Save(domain.NewTarget("t-1", "https://example.com", "one", 10*time.Second))
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, status := range []string{domain.StatusOK, domain.StatusServerError, domain.StatusOK, domain.StatusOK} {
+		result := domain.NewResult(fmt.Sprint(i), "t-1", status, 200, time.Millisecond)
+		result.CheckedAt = base.Add(time.Duration(i) * time.Minute)
+		s.resultRepo.Save(result)
+	}
+
+	rec := s.do("GET", "/results/t-1?limit=2", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	cursor := rec.Header().Get(nextCursorHeader)
+	if cursor == "" {
+		t.Fatal("expected a next cursor header")
+	}
+
+	if res := decode[[]resultResponse](t, rec); len(res) != 2 || res[0].ID != "3" || res[1].ID != "2" {
+		t.Fatalf("expected newest results 3 and 2, got %+v", res)
+	}
+
+	rec = s.do("GET", "/results/t-1?limit=2&cursor="+cursor, nil)
+	if res := decode[[]resultResponse](t, rec); len(res) != 2 || res[0].ID != "1" || res[1].ID != "0" {
+		t.Fatalf("expected results 1 and 0, got %+v", res)
+	}
+
+	if next := rec.Header().Get(nextCursorHeader); next != "" {
+		t.Errorf("expected no cursor on the last page, got %q", next)
+	}
+
+	rec = s.do("GET", "/results/t-1?status=SERVER_ERROR&from=2025-01-01T00:00:30Z&to=2025-01-01T00:02:00Z", nil)
+	if res := decode[[]resultResponse](t, rec); len(res) != 1 || res[0].ID != "1" {
+		t.Errorf("expected only result 1, got %+v", res)
+	}
+}
+
+func TestHandler_ListResults_BadRequest(t *testing.T) {
+	s := newTestServer()
+
+	s.targetRepo.Save(domain.NewTarget("t-1", "https://example.com", "one", 10*time.Second))
+
+	for _, query := range []string{
+		"?from=yesterday",
+		"?to=tomorrow",
+		"?from=2025-01-02T00:00:00Z&to=2025-01-01T00:00:00Z",
+		"?limit=0",
+		"?limit=abc",
+		"?cursor=%21%21",
+	} {
+		if rec := s.do("GET", "/results/t-1"+query, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestHandler_ListResults_UnknownTarget(t *testing.T) {
+	s := newTestServer()
+
+	rec := s.do("GET", "/results/nonExistent", nil)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandler_ListStateChanges(t *testing.T) {
+	s := newTestServer()
+
+	rec := s.do("POST", "/targets", map[string]any{"url": "https://example.com", "interval": 10, "is_active": false})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	created := decode[targetResponse](t, rec)
+	if created.State != domain.StatePaused || created.IsActive {
+		t.Errorf("expected an inactive target to be PAUSED, got %s (active %t)", created.State, created.IsActive)
+	}
+
+	target, _ := s.targetRepo.FindByID(created.ID)
+	change, _ := target.Resume(time.Now())
+	change.ID = "change-2"
+	s.stateRepo.Save(change)
+
+	rec = s.do("GET", "/targets/"+created.ID+"/states", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	res := decode[[]stateChangeResponse](t, rec)
+	if len(res) != 2 {
+		t.Fatalf("expected 2 state changes, got %d", len(res))
+	}
+
+	if res[0].From != domain.StateUnknown || res[0].To != domain.StatePaused || res[1].To != domain.StateUnknown {
+		t.Errorf("unexpected state history %+v", res)
+	}
+
+	if rec := s.do("GET", "/targets/nonExistent/states", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandler_PauseAndResume(t *testing.T) {
+	s := newTestServer()
+	s.targetRepo.Save(domain.NewTarget("t-1", "https://example.com", "one", 10*time.Second))
+
+	rec := s.do("POST", "/targets/t-1/pause", nil)
+	if target := decode[targetResponse](t, rec); rec.Code != http.StatusOK || target.State != domain.StatePaused || target.IsActive {
+		t.Fatalf("expected the target to be paused, got %d %+v", rec.Code, target)
+	}
+
+	if s.reloader.Calls != 1 {
+		t.Errorf("expected the scheduler to be reloaded, got %d reloads", s.reloader.Calls)
+	}
+
+	if target, _ := s.targetRepo.FindByID("t-1"); target.State != domain.StatePaused || target.IsActive {
+		t.Errorf("expected the paused target to be saved, got %+v", target)
+	}
+
+	if rec := s.do("POST", "/targets/t-1/pause", nil); rec.Code != http.StatusOK || s.reloader.Calls != 1 {
+		t.Errorf("expected pausing again to change nothing, got %d with %d reloads", rec.Code, s.reloader.Calls)
+	}
+
+	rec = s.do("POST", "/targets/t-1/resume", nil)
+	if target := decode[targetResponse](t, rec); rec.Code != http.StatusOK || target.State != domain.StateUnknown || !target.IsActive {
+		t.Fatalf("expected the target to be resumed, got %d %+v", rec.Code, target)
+	}
+
+	if rec := s.do("POST", "/targets/t-1/resume", nil); rec.Code != http.StatusOK || s.reloader.Calls != 2 {
+		t.Errorf("expected resuming an active target to change nothing, got %d with %d reloads", rec.Code, s.reloader.Calls)
+	}
+
+	changes, _ := s.stateRepo.FindByTargetID("t-1")
+	if len(changes) != 2 || changes[0].To != domain.StatePaused || changes[1].To != domain.StateUnknown {
+		t.Errorf("expected the pause and resume to be recorded, got %+v", changes)
+	}
+
+	for _, path := range []string{"/targets/nonExistent/pause", "/targets/nonExistent/resume"} {
+		if rec := s.do("POST", path, nil); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: expected status 404, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestHandler_DeadLetters(t *testing.T) {
+	s := newTestServer()
+
+	alert := domain.NewAlert("alert-1", "t-1", domain.StatusServerError, "down")
+	n := domain.Notification{Event: domain.NotificationOpened, Alert: *alert}
+	messages := []*domain.OutboxMessage{
+		domain.NewOutboxMessage("message-1", "webhook", n, time.Now()),
+		domain.NewOutboxMessage("message-2", "webhook", n, time.Now()),
+	}
+	messages[0].Fail(fmt.Errorf("500 Internal Server Error"), time.Now(), time.Time{})
+	s.outboxRepo.SaveAlert(alert, messages)
+
+	rec := s.do("GET", "/notifications/dead-letters", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	res := decode[[]deadLetterResponse](t, rec)
+	if len(res) != 1 {
+		t.Fatalf("expected 1 dead letter, got %d", len(res))
+	}
+
+	if res[0].ID != "message-1" || res[0].Channel != "webhook" || res[0].Alert.ID != "alert-1" || res[0].Attempts != 1 || res[0].LastError != "500 Internal Server Error" {
+		t.Errorf("unexpected dead letter %+v", res[0])
+	}
+
+	if rec := s.do("POST", "/notifications/dead-letters/message-1/replay", nil); rec.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if res := decode[[]deadLetterResponse](t, s.do("GET", "/notifications/dead-letters", nil)); len(res) != 0 {
+		t.Errorf("expected no dead letters after replay, got %d", len(res))
+	}
+
+	for _, id := range []string{"message-1", "message-2", "nonExistent"} {
+		if rec := s.do("POST", "/notifications/dead-letters/"+id+"/replay", nil); rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 replaying %s, got %d", id, rec.Code)
+		}
+	}
+}
+
+func TestHandler_ListAlerts(t *testing.T) {
+	s := newTestServer()
+
+	s.targetRepo.Save(domain.NewTarget("t-1", "https://example.com/1", "one", 10*time.Second))
+	s.targetRepo.Save(domain.NewTarget("t-2", "https://example.com/2", "two", 10*time.Second))
+
+	resolved := domain.NewAlert("a-1", "t-1", "SERVER_ERROR", "down")
+	resolved.Resolve()
+	s.alertRepo.Save(resolved)
+	s.alertRepo.Save(domain.NewAlert("a-2", "t-2", "SERVER_ERROR", "down"))
+
+	rec := s.do("GET", "/alerts", nil)
+	res := decode[[]alertResponse](t, rec)
+
+	if len(res) != 1 {
+		t.Fatalf("expected 1 active alert, got %d", len(res))
+	}
+
+	if res[0].ID != "a-2" {
+		t.Errorf("expected alert a-2, got %s", res[0].ID)
+	}
+}
+
+func TestHandler_ListAlerts_Acknowledged(t *testing.T) {
+	s := newTestServer()
+
+	s.targetRepo.Save(domain.NewTarget("t-1", "https://example.com/1", "one", 10*time.Second))
+
+	acknowledged := domain.NewAlert("a-1", "t-1", "SERVER_ERROR", "down")
+	acknowledged.Acknowledge("alice", time.Now())
+	s.alertRepo.Save(acknowledged)
+	s.alertRepo.Save(domain.NewAlert("a-2", "t-1", "CERT_EXPIRY", "expiring"))
+
+	if res := decode[[]alertResponse](t, s.do("GET", "/alerts", nil)); len(res) != 1 || res[0].ID != "a-2" {
+		t.Errorf("expected only the unacknowledged alert a-2, got %+v", res)
+	}
+
+	res := decode[[]alertResponse](t, s.do("GET", "/alerts?include_acknowledged=true", nil))
+	if len(res) != 2 || res[0].AcknowledgedBy != "alice" {
+		t.Errorf("expected both open alerts, got %+v", res)
+	}
+}
+
+func TestHandler_AlertLifecycle(t *testing.T) {
+	s := newTestServer()
+	s.alertRepo.Save(domain.NewAlert("a-1", "t-1", "SERVER_ERROR", "down"))
+
+	rec := s.do("POST", "/alerts/a-1/assign", map[string]any{"assignee": "bob"})
+	if rec.Code != http.StatusOK || decode[alertResponse](t, rec).Assignee != "bob" {
+		t.Fatalf("expected the alert to be assigned to bob, got %d: %s", rec.Code, rec.Body)
+	}
+
+	until := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	rec = s.do("POST", "/alerts/a-1/snooze", map[string]any{"until": until})
+	if res := decode[alertResponse](t, rec); rec.Code != http.StatusOK || res.SnoozedUntil == nil || !res.SnoozedUntil.Equal(until) {
+		t.Fatalf("expected the alert to be snoozed until %v, got %d: %+v", until, rec.Code, res)
+	}
+
+	rec = s.do("POST", "/alerts/a-1/acknowledge", map[string]any{"by": "alice"})
+	if res := decode[alertResponse](t, rec); rec.Code != http.StatusOK || res.AcknowledgedBy != "alice" || res.AcknowledgedAt == nil {
+		t.Fatalf("expected the alert to be acknowledged by alice, got %d: %+v", rec.Code, res)
+	}
+
+	stored, _ := s.alertRepo.FindByID("a-1")
+	if !stored.IsAcknowledged() || stored.Assignee != "bob" {
+		t.Errorf("expected the changes to be saved, got %+v", stored)
+	}
+
+	stored.Resolve()
+	s.alertRepo.Update(stored)
+	if rec := s.do("POST", "/alerts/a-1/acknowledge", map[string]any{"by": "carol"}); rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 for a resolved alert, got %d", rec.Code)
+	}
+
+	if rec := s.do("POST", "/alerts/nope/acknowledge", map[string]any{"by": "alice"}); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandler_AlertLifecycle_Invalid(t *testing.T) {
+	s := newTestServer()
+	s.alertRepo.Save(domain.NewAlert("a-1", "t-1", "SERVER_ERROR", "down"))
+
+	cases := map[string]struct {
+		path string
+		body map[string]any
+	}{
+		"acknowledge without by":      {"/alerts/a-1/acknowledge", map[string]any{}},
+		"assign without assignee":     {"/alerts/a-1/assign", map[string]any{"assignee": ""}},
+		"snooze without duration":     {"/alerts/a-1/snooze", map[string]any{}},
+		"snooze with both":            {"/alerts/a-1/snooze", map[string]any{"minutes": 5, "until": time.Now()}},
+		"snooze for negative minutes": {"/alerts/a-1/snooze", map[string]any{"minutes": -5}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if rec := s.do("POST", tc.path, tc.body); rec.Code != http.StatusBadRequest {
+				t.Errorf("expected status 400, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_EscalationPolicies(t *testing.T) {
+	s := newTestServer()
+
+	rec := s.do("POST", "/escalation-policies", map[string]any{
+		"name": "on-call",
+		"steps": []map[string]any{
+			{"delay_minutes": 0, "channels": []string{"chat"}},
+			{"delay_minutes": 15, "channels": []string{"chat", "email"}},
+		},
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body)
+	}
+
+	policy := decode[escalationPolicyResponse](t, rec)
+	if policy.Name != "on-call" || len(policy.Steps) != 2 || policy.Steps[1].DelayMinutes != 15 {
+		t.Errorf("unexpected policy %+v", policy)
+	}
+
+	if got := decode[escalationPolicyResponse](t, s.do("GET", "/escalation-policies/"+policy.ID, nil)); got.ID != policy.ID {
+		t.Errorf("expected policy %s, got %s", policy.ID, got.ID)
+	}
+
+	if list := decode[[]escalationPolicyResponse](t, s.do("GET", "/escalation-policies", nil)); len(list) != 1 {
+		t.Errorf("expected 1 policy, got %d", len(list))
+	}
+
+	s.targetRepo.Save(domain.NewTarget("t-1", "https://example.com", "one", 10*time.Second))
+
+	rec = s.do("PUT", "/targets/t-1/escalation-policy", map[string]any{"escalation_policy_id": policy.ID})
+	if target := decode[targetResponse](t, rec); rec.Code != http.StatusOK || target.EscalationPolicyID != policy.ID {
+		t.Fatalf("expected the target to be attached, got %d %+v", rec.Code, target)
+	}
+
+	if rec := s.do("DELETE", "/escalation-policies/"+policy.ID, nil); rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 while attached, got %d", rec.Code)
+	}
+
+	rec = s.do("PUT", "/targets/t-1/escalation-policy", map[string]any{"escalation_policy_id": ""})
+	if target := decode[targetResponse](t, rec); target.EscalationPolicyID != "" {
+		t.Errorf("expected the target to be detached, got %+v", target)
+	}
+
+	if rec := s.do("DELETE", "/escalation-policies/"+policy.ID, nil); rec.Code != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", rec.Code)
+	}
+
+	if rec := s.do("GET", "/escalation-policies/"+policy.ID, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandler_EscalationPolicies_Invalid(t *testing.T) {
+	s := newTestServer()
+	s.targetRepo.Save(domain.NewTarget("t-1", "https://example.com", "one", 10*time.Second))
+
+	step := func(delay int, channels ...string) map[string]any {
+		return map[string]any{"delay_minutes": delay, "channels": channels}
+	}
+
+	cases := map[string]struct {
+		method, path string
+		body         map[string]any
+	}{
+		"policy without name":       {"POST", "/escalation-policies", map[string]any{"steps": []any{step(0, "chat")}}},
+		"policy without steps":      {"POST", "/escalation-policies", map[string]any{"name": "on-call"}},
+		"step without channels":     {"POST", "/escalation-policies", map[string]any{"name": "on-call", "steps": []any{step(0)}}},
+		"step with unknown channel": {"POST", "/escalation-policies", map[string]any{"name": "on-call", "steps": []any{step(0, "pager")}}},
+		"step with negative delay":  {"POST", "/escalation-policies", map[string]any{"name": "on-call", "steps": []any{step(-5, "chat")}}},
+		"attach unknown policy":     {"PUT", "/targets/t-1/escalation-policy", map[string]any{"escalation_policy_id": "missing"}},
+		"create target with unknown policy": {"POST", "/targets", map[string]any{
+			"url": "https://example.com", "name": "two", "interval": 30, "escalation_policy_id": "missing",
+		}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if rec := s.do(tc.method, tc.path, tc.body); rec.Code != http.StatusBadRequest {
+				t.Errorf("expected status 400, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_MaintenanceWindows(t *testing.T) {
+	s := newTestServer()
+
+	rec := s.do("POST", "/targets", map[string]any{"url": "https://api.example.com", "interval": 30, "tags": []string{"api"}})
+	if target := decode[targetResponse](t, rec); len(target.Tags) != 1 || target.Tags[0] != "api" {
+		t.Fatalf("expected the target to be tagged api, got %+v", target)
+	}
+
+	start := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+	rec = s.do("POST", "/maintenance-windows", map[string]any{
+		"name": "Deploy", "start": start, "duration_minutes": 30, "tags": []string{"api"},
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body)
+	}
+
+	window := decode[maintenanceWindowResponse](t, rec)
+	if window.Name != "Deploy" || !window.Start.Equal(start) || window.DurationMinutes != 30 || !window.Active {
+		t.Errorf("unexpected window %+v", window)
+	}
+
+	rec = s.do("POST", "/maintenance-windows", map[string]any{
+		"name": "Weekly", "duration_minutes": 60, "schedule": "0 22 * * 2", "timezone": "Europe/Warsaw", "target_ids": []string{"id-1"},
+	})
+	if recurring := decode[maintenanceWindowResponse](t, rec); rec.Code != http.StatusCreated || recurring.Start.IsZero() || recurring.Schedule != "0 22 * * 2" {
+		t.Fatalf("expected a recurring window starting now, got %d %+v", rec.Code, recurring)
+	}
+
+	if list := decode[[]maintenanceWindowResponse](t, s.do("GET", "/maintenance-windows", nil)); len(list) != 2 {
+		t.Errorf("expected 2 windows, got %d", len(list))
+	}
+
+	if got := decode[maintenanceWindowResponse](t, s.do("GET", "/maintenance-windows/"+window.ID, nil)); got.ID != window.ID {
+		t.Errorf("expected window %s, got %s", window.ID, got.ID)
+	}
+
+	if rec := s.do("DELETE", "/maintenance-windows/"+window.ID, nil); rec.Code != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", rec.Code)
+	}
+
+	if rec := s.do("GET", "/maintenance-windows/"+window.ID, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandler_MaintenanceWindows_Invalid(t *testing.T) {
+	s := newTestServer()
+	start := time.Now().Add(time.Hour)
+
+	cases := map[string]map[string]any{
+		"without name":     {"start": start, "duration_minutes": 30, "tags": []string{"api"}},
+		"without duration": {"name": "Deploy", "start": start, "tags": []string{"api"}},
+		"without scope":    {"name": "Deploy", "start": start, "duration_minutes": 30},
+		"without start":    {"name": "Deploy", "duration_minutes": 30, "tags": []string{"api"}},
+		"bad schedule":     {"name": "Deploy", "duration_minutes": 30, "tags": []string{"api"}, "schedule": "tuesdays"},
+		"bad timezone":     {"name": "Deploy", "duration_minutes": 30, "tags": []string{"api"}, "schedule": "@daily", "timezone": "Nowhere/Town"},
+		"unknown target":   {"name": "Deploy", "start": start, "duration_minutes": 30, "target_ids": []string{"missing"}},
+	}
+
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			if rec := s.do("POST", "/maintenance-windows", body); rec.Code != http.StatusBadRequest {
+				t.Errorf("expected status 400, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_CheckTarget(t *testing.T) {
+	s := newTestServer()
+
+	s.targetRepo.Save(domain.NewTarget("t-1", "https://example.com", "one", 10*time.Second))
+	s.checker.CheckTargetFunc = func(ctx context.Context, targetID string) error {
+		return s.resultRepo.Save(domain.NewResult("r-1", targetID, "OK", 200, time.Millisecond))
+	}
+
+	rec := s.do("POST", "/targets/t-1/check", nil)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	if res := decode[resultResponse](t, rec); res.ID != "r-1" {
+		t.Errorf("expected result r-1, got %s", res.ID)
+	}
+}
+
+func TestHandler_CheckTarget_Failure(t *testing.T) {
+	s := newTestServer()
+
+	s.targetRepo.Save(domain.NewTarget("t-1", "https://example.com", "one", 10*time.Second))
+	s.checker.CheckTargetFunc = func(ctx context.Context, targetID string) error {
+		return fmt.Errorf("connection refused")
+	}
+
+	rec := s.do("POST", "/targets/t-1/check", nil)
+
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("expected status 502, got %d", rec.Code)
+	}
+}
+
+func TestHandler_MethodNotAllowed(t *testing.T) {
+	s := newTestServer()
+
+	rec := s.do("DELETE", "/targets", nil)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", rec.Code)
+	}
+}
+
+func TestHandler_GetStats(t *testing.T) {
+	s := newTestServer()
+
+	s.targetRepo.Save(domain.NewTarget("t-1", "https://example.com", "one", 10*time.Second))
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, status := range []string{domain.StatusOK, domain.StatusOK, domain.StatusOK, domain.StatusServerError} {
+		result := domain.NewResult(fmt.Sprint(i), "t-1", status, 200, 100*time.Millisecond)
+		result.CheckedAt = base.Add(time.Duration(i) * time.Minute)
+		result.Timings = &domain.Timings{TLS: 30 * time.Millisecond, Server: 60 * time.Millisecond}
+		s.resultRepo.Save(result)
+	}
+
+	rec := s.do("GET", "/stats/t-1?from=2025-01-01T00:00:00Z&to=2025-01-01T01:00:00Z", nil)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	res := decode[statsResponse](t, rec)
+
+	if res.TotalChecks != 4 {
+		t.Errorf("expected 4 checks, got %d", res.TotalChecks)
+	}
+
+	if res.UptimePercent != 75 {
+		t.Errorf("expected uptime 75%%, got %f", res.UptimePercent)
+	}
+
+	if res.ResponseTime.P95Ms != 100 {
+		t.Errorf("expected p95 100ms, got %d", res.ResponseTime.P95Ms)
+	}
+
+	if res.Phases[domain.PhaseTLS].P95Ms != 30 || res.Phases[domain.PhaseServer].P95Ms != 60 {
+		t.Errorf("expected TLS and server phases, got %+v", res.Phases)
+	}
+}
+
+func TestHandler_GetStats_BadRequest(t *testing.T) {
+	s := newTestServer()
+
+	s.targetRepo.Save(domain.NewTarget("t-1", "https://example.com", "one", 10*time.Second))
+
+	for _, query := range []string{
+		"?from=yesterday",
+		"?to=tomorrow",
+		"?from=2025-01-02T00:00:00Z&to=2025-01-01T00:00:00Z",
+	} {
+		if rec := s.do("GET", "/stats/t-1"+query, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, rec.Code)
+		}
+	}
+
+	if rec := s.do("GET", "/stats/nonExistent", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+const defaultEscalationInterval = 30 * time.Second
+
+type EscalationOption func(*EscalationUseCase)
+
+// WithEscalationInterval sets how often open alerts are checked for due
+// escalation steps.
+func WithEscalationInterval(d time.Duration) EscalationOption {
+	return func(u *EscalationUseCase) {
+		if d > 0 {
+			u.interval = d
+		}
+	}
+}
+
+// WithEscalationErrorHandler is told about errors of escalation passes.
+func WithEscalationErrorHandler(fn func(err error)) EscalationOption {
+	return func(u *EscalationUseCase) {
+		u.onError = fn
+	}
+}
+
+// WithEscalationMaintenanceWindows holds back the escalation of alerts of
+// targets in an active maintenance window until it ends.
+func WithEscalationMaintenanceWindows(repo domain.MaintenanceWindowRepository) EscalationOption {
+	return func(u *EscalationUseCase) {
+		u.maintenanceRepo = repo
+	}
+}
+
+// EscalationUseCase advances open alerts nobody acknowledged through the
+// escalation policy of their target. When a step is due, a
+// domain.NotificationEscalated message is queued in the outbox for each of
+// its channels, together with the alert's new step. An alert advances at
+// most one step per pass, so steps that fell due while it was held back go
+// out an interval apart. Acknowledged and resolved alerts stop escalating;
+// snoozed ones wait for the snooze to end, those of targets in maintenance
+// for the maintenance to end and those of paused targets until they are
+// resumed.
+type EscalationUseCase struct {
+	targetRepo  domain.TargetRepository
+	policyRepo  domain.EscalationPolicyRepository
+	alertRepo   domain.AlertRepository
+	outbox      domain.OutboxRepository
+	idGenerator domain.IDGenerator
+	clock       domain.Clock
+	interval    time.Duration
+	onError     func(err error)
+
+	maintenanceRepo domain.MaintenanceWindowRepository
+}
+
+func NewEscalationUseCase(
+	targetRepo domain.TargetRepository,
+	policyRepo domain.EscalationPolicyRepository,
+	alertRepo domain.AlertRepository,
+	outbox domain.OutboxRepository,
+	idGenerator domain.IDGenerator,
+	clock domain.Clock,
+	opts ...EscalationOption,
+) *EscalationUseCase {
+	u := &EscalationUseCase{
+		targetRepo:  targetRepo,
+		policyRepo:  policyRepo,
+		alertRepo:   alertRepo,
+		outbox:      outbox,
+		idGenerator: idGenerator,
+		clock:       clock,
+		interval:    defaultEscalationInterval,
+	}
+
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	return u
+}
+
+// Run escalates due alerts right away and then once per interval until ctx
+// is cancelled.
+func (u *EscalationUseCase) Run(ctx context.Context) error {
+	for {
+		if err := u.EscalateDue(); err != nil && u.onError != nil {
+			u.onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-u.clock.After(u.interval):
+		}
+	}
+}
+
+// EscalateDue queues the notifications of the next escalation step of every
+// open, unacknowledged alert it is due for.
+func (u *EscalationUseCase) EscalateDue() error {
+	alerts, err := u.alertRepo.GetUnacknowledged()
+	if err != nil {
+		return err
+	}
+
+	now := u.clock.Now()
+
+	var errs []error
+	for _, alert := range alerts {
+		if alert.IsSnoozed(now) {
+			continue
+		}
+
+		if err := u.escalate(alert, now); err != nil {
+			errs = append(errs, fmt.Errorf("escalating alert %s: %w", alert.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// inMaintenance reports whether a maintenance window covering the target is
+// active. When the windows can't be loaded, escalations aren't held back.
+func (u *EscalationUseCase) inMaintenance(target *domain.Target, now time.Time) bool {
+	if u.maintenanceRepo == nil {
+		return false
+	}
+
+	windows, err := u.maintenanceRepo.GetAll()
+	if err != nil {
+		return false
+	}
+
+	return domain.InMaintenance(windows, target, now)
+}
+
+func (u *EscalationUseCase) escalate(alert *domain.Alert, now time.Time) error {
+	target, err := u.targetRepo.FindByID(alert.TargetID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if target.EscalationPolicyID == "" || target.State == domain.StatePaused || u.inMaintenance(target, now) {
+		return nil
+	}
+
+	policy, err := u.policyRepo.FindByID(target.EscalationPolicyID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if alert.EscalationStep >= len(policy.Steps) || now.Before(policy.DueAt(alert.EscalationStep, alert.CreatedAt)) {
+		return nil
+	}
+
+	step := policy.Steps[alert.EscalationStep]
+	escalated := *alert
+	escalated.EscalationStep++
+
+	n := domain.Notification{Event: domain.NotificationEscalated, Alert: escalated, Target: *target}
+	messages := make([]*domain.OutboxMessage, 0, len(step.Channels))
+	for _, channel := range step.Channels {
+		messages = append(messages, domain.NewOutboxMessage(u.idGenerator.Generate(), channel, n, now))
+	}
+
+	// The alert may have been resolved or acknowledged since it was read;
+	// then nothing is queued.
+	_, err = u.outbox.AdvanceEscalation(alert.ID, alert.EscalationStep, escalated.EscalationStep, messages)
+	return err
+}
@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+// ----- > Helpers < -----
+
+type escalationFixture struct {
+	clock   *MockClock
+	alerts  *MockAlertRepository
+	outbox  *MockOutboxRepository
+	usecase *EscalationUseCase
+}
+
+// newEscalationFixture escalates the alerts of target-1, which has the
+// policy, and target-2, which has none.
+func newEscalationFixture(steps ...domain.EscalationStep) *escalationFixture {
+	f := &escalationFixture{clock: newMockClock(), alerts: &MockAlertRepository{}}
+	f.outbox = &MockOutboxRepository{Alerts: f.alerts}
+
+	f.alerts.GetUnacknowledgedFunc = func() ([]*domain.Alert, error) {
+		var open []*domain.Alert
+		for _, alert := range f.alerts.SavedAlerts {
+			if !alert.IsResolved && !alert.IsAcknowledged() {
+				open = append(open, alert)
+			}
+		}
+		return open, nil
+	}
+
+	targets := map[string]*domain.Target{
+		"target-1": {ID: "target-1", URL: "https://example.com", EscalationPolicyID: "policy-1"},
+		"target-2": {ID: "target-2", URL: "https://example.org"},
+	}
+
+	ids := 0
+	f.usecase = NewEscalationUseCase(
+		&MockTargetRepository{FindByIDFunc: func(id string) (*domain.Target, error) { return targets[id], nil }},
+		&MockEscalationPolicyRepository{Policies: map[string]*domain.EscalationPolicy{
+			"policy-1": domain.NewEscalationPolicy("policy-1", "On call", steps),
+		}},
+		f.alerts,
+		f.outbox,
+		&MockIDGenerator{GenerateFunc: func() string {
+			ids++
+			return fmt.Sprintf("message-%d", ids)
+		}},
+		f.clock,
+	)
+
+	return f
+}
+
+func (f *escalationFixture) openAlert(id, targetID string) *domain.Alert {
+	alert := domain.NewAlert(id, targetID, domain.StatusServerError, "down")
+	alert.CreatedAt = f.clock.Now()
+	f.alerts.Save(alert)
+
+	return alert
+}
+
+// escalate advances the clock and returns the channels of the messages the
+// pass queued.
+func (f *escalationFixture) escalate(t *testing.T, advance time.Duration) []string {
+	t.Helper()
+
+	f.clock.Advance(advance)
+	queued := len(f.outbox.Messages)
+
+	if err := f.usecase.EscalateDue(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var channels []string
+	for _, message := range f.outbox.Messages[queued:] {
+		if message.Notification.Event != domain.NotificationEscalated {
+			t.Errorf("expected an escalation, got %s", message.Notification.Event)
+		}
+		channels = append(channels, message.Channel)
+	}
+
+	return channels
+}
+
+var threeSteps = []domain.EscalationStep{
+	{Delay: 0, Channels: []string{"chat"}},
+	{Delay: 10 * time.Minute, Channels: []string{"email", "webhook"}},
+	{Delay: 20 * time.Minute, Channels: []string{"incident"}},
+}
+
+// ----- > Tests < -----
+
+func TestEscalationUseCase_AdvancesUntilAcknowledged(t *testing.T) {
+	f := newEscalationFixture(threeSteps...)
+	alert := f.openAlert("alert-1", "target-1")
+
+	if got := f.escalate(t, 0); fmt.Sprint(got) != "[chat]" || alert.EscalationStep != 1 {
+		t.Fatalf("expected the first step right away, got %v at step %d", got, alert.EscalationStep)
+	}
+
+	if got := f.escalate(t, 5*time.Minute); len(got) != 0 {
+		t.Fatalf("expected nothing before the second step is due, got %v", got)
+	}
+
+	if got := f.escalate(t, 5*time.Minute); fmt.Sprint(got) != "[email webhook]" || alert.EscalationStep != 2 {
+		t.Fatalf("expected the second step after 10 minutes, got %v at step %d", got, alert.EscalationStep)
+	}
+
+	if n := f.outbox.Messages[1].Notification; n.Alert.EscalationStep != 2 || n.Target.ID != "target-1" {
+		t.Errorf("expected the notification to carry the step and target, got %+v", n)
+	}
+
+	alert.Acknowledge("alice", f.clock.Now())
+
+	if got := f.escalate(t, time.Hour); len(got) != 0 {
+		t.Errorf("expected an acknowledged alert to stop escalating, got %v", got)
+	}
+}
+
+func TestEscalationUseCase_CatchesUpAndStops(t *testing.T) {
+	f := newEscalationFixture(threeSteps...)
+	alert := f.openAlert("alert-1", "target-1")
+	f.openAlert("alert-2", "target-2")
+
+	for i, want := range []string{"[chat]", "[email webhook]", "[incident]"} {
+		advance := 30 * time.Second
+		if i == 0 {
+			advance = 45 * time.Minute
+		}
+
+		if got := f.escalate(t, advance); fmt.Sprint(got) != want || alert.EscalationStep != i+1 {
+			t.Fatalf("expected one overdue step per pass, got %v at step %d in pass %d", got, alert.EscalationStep, i+1)
+		}
+	}
+
+	if len(f.alerts.UpdatedAlerts) != 3 {
+		t.Errorf("expected an update of the alert per step, got %d", len(f.alerts.UpdatedAlerts))
+	}
+
+	if got := f.escalate(t, time.Hour); len(got) != 0 {
+		t.Errorf("expected the last step to end the escalation, got %v", got)
+	}
+}
+
+func TestEscalationUseCase_AlertResolvedWhileEscalating(t *testing.T) {
+	f := newEscalationFixture(threeSteps...)
+	alert := f.openAlert("alert-1", "target-1")
+
+	// The check resolves the alert right after the escalation pass read it.
+	f.alerts.GetUnacknowledgedFunc = func() ([]*domain.Alert, error) {
+		read := *alert
+		alert.Resolve()
+		return []*domain.Alert{&read}, nil
+	}
+
+	if got := f.escalate(t, 0); len(got) != 0 {
+		t.Errorf("expected nothing to be queued, got %v", got)
+	}
+
+	if !alert.IsResolved || alert.EscalationStep != 0 {
+		t.Errorf("expected the alert to stay resolved at step 0, got resolved %t at step %d", alert.IsResolved, alert.EscalationStep)
+	}
+}
+
+func TestEscalationUseCase_HoldsBackInMaintenance(t *testing.T) {
+	f := newEscalationFixture(threeSteps...)
+	window := domain.NewMaintenanceWindow("window-1", "Deploy", f.clock.Now(), 15*time.Minute)
+	window.TargetIDs = []string{"target-1"}
+	WithEscalationMaintenanceWindows(&MockMaintenanceWindowRepository{Windows: []*domain.MaintenanceWindow{window}})(f.usecase)
+
+	alert := f.openAlert("alert-1", "target-1")
+
+	if got := f.escalate(t, 10*time.Minute); len(got) != 0 || alert.EscalationStep != 0 {
+		t.Fatalf("expected no escalation in maintenance, got %v at step %d", got, alert.EscalationStep)
+	}
+
+	if got := f.escalate(t, 5*time.Minute); fmt.Sprint(got) != "[chat]" {
+		t.Errorf("expected the first step once the maintenance ended, got %v", got)
+	}
+
+	if got := f.escalate(t, 30*time.Second); fmt.Sprint(got) != "[email webhook]" {
+		t.Errorf("expected the overdue second step a pass later, got %v", got)
+	}
+}
+
+func TestEscalationUseCase_HoldsBackPausedTargets(t *testing.T) {
+	f := newEscalationFixture(threeSteps...)
+	alert := f.openAlert("alert-1", "target-1")
+
+	target, _ := f.usecase.targetRepo.FindByID("target-1")
+	target.State = domain.StatePaused
+
+	if got := f.escalate(t, time.Hour); len(got) != 0 || alert.EscalationStep != 0 {
+		t.Fatalf("expected no escalation of a paused target, got %v at step %d", got, alert.EscalationStep)
+	}
+
+	target.State = domain.StateUnknown
+	if got := f.escalate(t, 30*time.Second); fmt.Sprint(got) != "[chat]" {
+		t.Errorf("expected the escalation to go on once the target was resumed, got %v", got)
+	}
+}
+
+func TestEscalationUseCase_ResolvedAndSnoozedAlerts(t *testing.T) {
+	f := newEscalationFixture(threeSteps...)
+	resolved := f.openAlert("alert-1", "target-1")
+	resolved.Resolve()
+
+	snoozed := f.openAlert("alert-2", "target-1")
+	snoozed.Snooze(f.clock.Now().Add(20 * time.Minute))
+
+	if got := f.escalate(t, 15*time.Minute); len(got) != 0 {
+		t.Fatalf("expected resolved and snoozed alerts not to escalate, got %v", got)
+	}
+
+	if got := f.escalate(t, 10*time.Minute); fmt.Sprint(got) != "[chat]" {
+		t.Errorf("expected the snoozed alert to go on one step at a time once the snooze ended, got %v", got)
+	}
+}
@@ -2,33 +2,120 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/karoljaro/go-uptime-monitor/domain"
 )
 
+const defaultCertExpiryWarning = 14 * 24 * time.Hour
+
+type MonitorOption func(*MonitorUseCase)
+
+// WithChecker registers the checker used for targets of the given type,
+// e.g. domain.TargetTypeTCP.
+func WithChecker(targetType string, checker domain.Checker) MonitorOption {
+	return func(u *MonitorUseCase) {
+		u.checkers[targetType] = checker
+	}
+}
+
+// WithCertificateExpiryWarning sets how many days before its TLS certificate
+// expires a target gets a domain.AlertTypeCertificateExpiry alert.
+func WithCertificateExpiryWarning(days int) MonitorOption {
+	return func(u *MonitorUseCase) {
+		if days >= 0 {
+			u.certExpiryWarning = time.Duration(days) * 24 * time.Hour
+		}
+	}
+}
+
+// WithStateChangeRepository keeps the history of the targets' state changes.
+func WithStateChangeRepository(repo domain.StateChangeRepository) MonitorOption {
+	return func(u *MonitorUseCase) {
+		u.stateRepo = repo
+	}
+}
+
+// WithOutbox saves alerts through the outbox, queueing a message for every
+// channel each time an alert is opened or resolved; an OutboxUseCase
+// delivers them.
+func WithOutbox(repo domain.OutboxRepository, channels ...string) MonitorOption {
+	return func(u *MonitorUseCase) {
+		u.outbox = repo
+		u.channels = channels
+	}
+}
+
+// WithAlertReminder notifies again about an alert that is still open every
+// interval, unless it was acknowledged or is snoozed.
+func WithAlertReminder(every time.Duration) MonitorOption {
+	return func(u *MonitorUseCase) {
+		if every > 0 {
+			u.reminder = every
+		}
+	}
+}
+
+// WithMaintenanceWindows records the checks of targets in an active
+// maintenance window as in maintenance, without opening, resolving or
+// reminding of alerts.
+func WithMaintenanceWindows(repo domain.MaintenanceWindowRepository) MonitorOption {
+	return func(u *MonitorUseCase) {
+		u.maintenanceRepo = repo
+	}
+}
+
+func WithMonitorClock(clock domain.Clock) MonitorOption {
+	return func(u *MonitorUseCase) {
+		u.clock = clock
+	}
+}
+
 type MonitorUseCase struct {
-	targetRepo domain.TargetRepository
-	resultRepo domain.ResultRepository
-	alertRepo  domain.AlertRepository
-	httpClient domain.HTTPClient
-	idGenerator domain.IDGenerator
+	targetRepo        domain.TargetRepository
+	resultRepo        domain.ResultRepository
+	alertRepo         domain.AlertRepository
+	stateRepo         domain.StateChangeRepository
+	checkers          map[string]domain.Checker
+	idGenerator       domain.IDGenerator
+	clock             domain.Clock
+	certExpiryWarning time.Duration
+
+	outbox   domain.OutboxRepository
+	channels []string
+
+	reminder time.Duration
+
+	maintenanceRepo domain.MaintenanceWindowRepository
 }
 
+// NewMonitorUseCase checks http and https targets with httpChecker; checkers
+// for other target types are added with WithChecker.
 func NewMonitorUseCase(
 	targetRepo domain.TargetRepository,
 	resultRepo domain.ResultRepository,
 	alertRepo domain.AlertRepository,
-	httpClient domain.HTTPClient,
+	httpChecker domain.Checker,
 	idGenerator domain.IDGenerator,
+	opts ...MonitorOption,
 ) *MonitorUseCase {
-	return &MonitorUseCase{
-		targetRepo: targetRepo,
-		resultRepo: resultRepo,
-		alertRepo:  alertRepo,
-		httpClient: httpClient,
+	u := &MonitorUseCase{
+		targetRepo:  targetRepo,
+		resultRepo:  resultRepo,
+		alertRepo:   alertRepo,
+		checkers:    map[string]domain.Checker{domain.TargetTypeHTTP: httpChecker},
 		idGenerator: idGenerator,
+
+		certExpiryWarning: defaultCertExpiryWarning,
+	}
+
+	for _, opt := range opts {
+		opt(u)
 	}
+
+	return u
 }
 
 func (u *MonitorUseCase) CheckTarget(ctx context.Context, targetID string) error {
@@ -37,20 +124,16 @@ func (u *MonitorUseCase) CheckTarget(ctx context.Context, targetID string) error
 		return err
 	}
 
-	httpResp, err := u.httpClient.Check(ctx, target.URL)
-	if err != nil {
-		return err
+	checker, exists := u.checkers[target.Type()]
+	if !exists {
+		return fmt.Errorf("no checker for target %s of type %q", target.URL, target.Type())
 	}
 
-	var status string
-	if httpResp.Error != nil {
-		status = "ERROR"
-	} else if httpResp.StatusCode >= 200 && httpResp.StatusCode < 300 {
-		status = "OK"
-	} else if httpResp.StatusCode >= 500 {
-		status = "SERVER_ERROR"
-	} else {
-		status = "CLIENT_ERROR"
+	policy := target.EffectiveCheckPolicy()
+
+	resp, status, err := u.check(ctx, checker, target, policy)
+	if err != nil {
+		return err
 	}
 
 	genResultID := u.idGenerator.Generate()
@@ -59,37 +142,391 @@ func (u *MonitorUseCase) CheckTarget(ctx context.Context, targetID string) error
 		genResultID,
 		targetID,
 		status,
-		httpResp.StatusCode,
-		httpResp.ResponseTime,
+		resp.StatusCode,
+		resp.ResponseTime,
 	)
+	result.Error = resp.Error
+	result.Timings = resp.Timings
+	if status == domain.StatusError {
+		result.ErrorKind = domain.ClassifyError(resp.Error)
+	}
+	if resp.FailedAssertion != nil {
+		result.Error = resp.FailedAssertion
+	}
+	result.InMaintenance = u.inMaintenance(target)
 
-	prevResult, err := u.resultRepo.GetLastByTargetID(targetID)
-	if err != nil {
-		prevResult = nil
+	// The run of checks with the same outcome that this one extends.
+	run := 1 + u.previousRun(targetID, status == domain.StatusOK, max(policy.FailuresToAlert(), policy.SuccessesToRecover())-1)
+
+	var slow string
+	if status == domain.StatusOK && target.Latency != nil {
+		slow = target.Latency.Violation(u.recentResponseTimes(targetID, resp.ResponseTime, target.Latency.P95Window))
 	}
 
 	u.resultRepo.Save(result)
 
-	if prevResult != nil && httpResp.StatusCode != 200 && prevResult.StatusCode == 200 {
-		genAlertID := u.idGenerator.Generate()
-		newAlert := domain.NewAlert(
-			genAlertID,
-			targetID,
-			status,
-			fmt.Sprintf("Target %s is %s", target.URL, status),
-		)
+	// Planned downtime neither changes the target's state nor alerts.
+	if result.InMaintenance {
+		return nil
+	}
+
+	if next := nextState(target, status, run, policy, slow != ""); next != "" {
+		reason := status
+		if next == domain.StateDegraded {
+			reason = domain.AlertTypeSlowResponse
+		}
+
+		change, err := target.Transition(next, reason, u.now())
+		if err != nil {
+			return err
+		}
+
+		if change != nil && u.recordStateChange(change) {
+			u.onStateChange(target, change, result, run, slow)
+		}
+	}
+
+	if resp.TLS != nil {
+		u.checkCertificate(target, result, resp.TLS)
+	}
+
+	if u.reminder > 0 {
+		u.remind(target, result)
+	}
+
+	return nil
+}
 
-		u.alertRepo.Save(newAlert)
+// inMaintenance reports whether a maintenance window covering the target is
+// active. When the windows can't be loaded, alerts aren't held back.
+func (u *MonitorUseCase) inMaintenance(target *domain.Target) bool {
+	if u.maintenanceRepo == nil {
+		return false
 	}
 
-	if httpResp.StatusCode == 200 {
-		unresolvedAlerts, _ := u.alertRepo.GetUnresolvedByTargetID(targetID)
+	windows, err := u.maintenanceRepo.GetAll()
+	if err != nil {
+		return false
+	}
 
+	return domain.InMaintenance(windows, target, u.now())
+}
+
+// check probes the target and retries a failing check as the policy says,
+// returning the last attempt. It only fails when ctx was cancelled before a
+// check completed.
+func (u *MonitorUseCase) check(ctx context.Context, checker domain.Checker, target *domain.Target, policy domain.CheckPolicy) (*domain.CheckResponse, string, error) {
+	for attempt := 0; ; attempt++ {
+		start := u.now()
+		resp, err := checker.Check(ctx, target)
+
+		// A check cancelled by the caller says nothing about the target.
+		if err != nil && ctx.Err() != nil && errors.Is(err, context.Canceled) {
+			return nil, "", err
+		}
+
+		// Transport errors are recorded like any other failed check.
+		if err != nil {
+			resp = &domain.CheckResponse{ResponseTime: u.now().Sub(start), Error: err}
+		}
+
+		status := checkStatus(target, resp)
+		if status == domain.StatusOK || attempt >= policy.Retries {
+			return resp, status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return resp, status, nil
+		case <-u.after(policy.Backoff(attempt)):
+		}
+	}
+}
+
+// previousRun counts how many of the target's latest results, up to limit,
+// share the outcome up.
+func (u *MonitorUseCase) previousRun(targetID string, up bool, limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+
+	page, err := u.resultRepo.QueryByTargetID(targetID, domain.ResultQuery{Limit: limit})
+	if err != nil {
+		return 0
+	}
+
+	run := 0
+	for _, result := range page.Results {
+		if (result.Status == domain.StatusOK) != up {
+			break
+		}
+		run++
+	}
+
+	return run
+}
+
+// nextState is the state the check moves the target to, or "" when it
+// doesn't decide one: a paused target stays paused and a failure or recovery
+// has to be confirmed by the policy's number of checks first.
+func nextState(target *domain.Target, status string, run int, policy domain.CheckPolicy, slow bool) string {
+	switch {
+	case target.State == domain.StatePaused:
+		return ""
+	case status != domain.StatusOK && run >= policy.FailuresToAlert():
+		return domain.StateDown
+	case status == domain.StatusOK && run >= policy.SuccessesToRecover() && slow:
+		return domain.StateDegraded
+	case status == domain.StatusOK && run >= policy.SuccessesToRecover():
+		return domain.StateUp
+	default:
+		return ""
+	}
+}
+
+// recentResponseTimes returns responseTime followed by those of the target's
+// latest results, window in total.
+func (u *MonitorUseCase) recentResponseTimes(targetID string, responseTime time.Duration, window int) []time.Duration {
+	recent := []time.Duration{responseTime}
+	if window <= 1 {
+		return recent
+	}
+
+	page, err := u.resultRepo.QueryByTargetID(targetID, domain.ResultQuery{Limit: window - 1})
+	if err != nil {
+		return recent
+	}
+
+	for _, result := range page.Results {
+		recent = append(recent, result.ResponseTime)
+	}
+
+	return recent
+}
+
+// recordStateChange saves change and reports whether it did. The change is
+// dropped when the target left change.From while it was being checked, for
+// example because it was paused.
+func (u *MonitorUseCase) recordStateChange(change *domain.StateChange) bool {
+	changed, err := u.targetRepo.ChangeState(change)
+	if err != nil || !changed {
+		return false
+	}
+
+	change.ID = u.idGenerator.Generate()
+	if u.stateRepo != nil {
+		u.stateRepo.Save(change)
+	}
+
+	return true
+}
+
+// onStateChange opens a downtime alert when the target goes down and a slow
+// response alert when it is degraded, and resolves them once it is back up.
+// The slow response alert stays open while the target is down.
+func (u *MonitorUseCase) onStateChange(target *domain.Target, change *domain.StateChange, result *domain.Result, run int, slow string) {
+	unresolvedAlerts, _ := u.alertRepo.GetUnresolvedByTargetID(target.ID)
+
+	switch change.To {
+	case domain.StateDown:
+		if hasDowntimeAlert(unresolvedAlerts) {
+			return
+		}
+
+		message := fmt.Sprintf("Target %s is %s", target.URL, result.Status)
+		switch result.Status {
+		case domain.StatusAssertionFailed:
+			message += ": " + result.Error.Error()
+		case domain.StatusError:
+			message += fmt.Sprintf(" (%s): %v", result.ErrorKind, result.Error)
+		}
+		if run > 1 {
+			message += fmt.Sprintf(" (%d consecutive failures)", run)
+		}
+
+		u.openAlert(target, result, result.Status, message)
+
+	case domain.StateDegraded:
+		hasSlowAlert := false
 		for _, alert := range unresolvedAlerts {
-			alert.Resolve()
-			u.alertRepo.Update(alert)
+			switch {
+			case alert.IsDowntime():
+				u.resolveAlert(target, result, alert)
+			case alert.Type == domain.AlertTypeSlowResponse:
+				hasSlowAlert = true
+			}
+		}
+
+		if !hasSlowAlert {
+			message := fmt.Sprintf("Target %s is slow: %s", target.URL, slow)
+			u.openAlert(target, result, domain.AlertTypeSlowResponse, message)
+		}
+
+	case domain.StateUp:
+		for _, alert := range unresolvedAlerts {
+			if alert.Type == domain.AlertTypeCertificateExpiry {
+				continue
+			}
+
+			u.resolveAlert(target, result, alert)
 		}
 	}
+}
 
-	return nil
+func (u *MonitorUseCase) openAlert(target *domain.Target, result *domain.Result, alertType, message string) {
+	alert := domain.NewAlert(u.idGenerator.Generate(), target.ID, alertType, message)
+	alert.NotifiedAt = u.now()
+	n := newNotification(domain.NotificationOpened, alert, target, result)
+
+	if u.outbox != nil {
+		u.outbox.SaveAlert(alert, u.outboxMessages(n))
+	} else {
+		u.alertRepo.Save(alert)
+	}
+}
+
+// resolveAlert resolves the alert and queues notifications about it, unless
+// it was resolved meanwhile.
+func (u *MonitorUseCase) resolveAlert(target *domain.Target, result *domain.Result, alert *domain.Alert) {
+	now := u.now()
+	resolved := *alert
+	resolved.ResolvedAt = &now
+	resolved.IsResolved = true
+	n := newNotification(domain.NotificationResolved, &resolved, target, result)
+
+	var changed bool
+	if u.outbox != nil {
+		changed, _ = u.outbox.ResolveAlert(alert.ID, now, u.outboxMessages(n))
+	} else {
+		changed, _ = u.alertRepo.Resolve(alert.ID, now)
+	}
+	if changed {
+		*alert = resolved
+	}
+}
+
+// remind queues notifications again about the target's open alerts that went without a
+// notification for the reminder interval. Acknowledged and snoozed alerts
+// are left alone, also when they were acknowledged or snoozed since they
+// were read.
+func (u *MonitorUseCase) remind(target *domain.Target, result *domain.Result) {
+	unresolvedAlerts, _ := u.alertRepo.GetUnresolvedByTargetID(target.ID)
+
+	now := u.now()
+	for _, alert := range unresolvedAlerts {
+		if alert.IsSilenced(now) || now.Sub(alert.NotifiedAt) < u.reminder {
+			continue
+		}
+
+		reminded := *alert
+		reminded.NotifiedAt = now
+		n := newNotification(domain.NotificationRepeated, &reminded, target, result)
+
+		var changed bool
+		if u.outbox != nil {
+			changed, _ = u.outbox.RemindAlert(alert.ID, now, u.outboxMessages(n))
+		} else {
+			changed, _ = u.alertRepo.Remind(alert.ID, now)
+		}
+		if changed {
+			alert.NotifiedAt = now
+		}
+	}
+}
+
+func newNotification(event string, alert *domain.Alert, target *domain.Target, result *domain.Result) domain.Notification {
+	return domain.Notification{Event: event, Alert: *alert, Target: *target, ResponseTime: result.ResponseTime}
+}
+
+func (u *MonitorUseCase) outboxMessages(n domain.Notification) []*domain.OutboxMessage {
+	messages := make([]*domain.OutboxMessage, 0, len(u.channels))
+	for _, channel := range u.channels {
+		messages = append(messages, domain.NewOutboxMessage(u.idGenerator.Generate(), channel, n, u.now()))
+	}
+
+	return messages
+}
+
+func hasDowntimeAlert(alerts []*domain.Alert) bool {
+	for _, alert := range alerts {
+		if alert.IsDowntime() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkCertificate keeps one certificate expiry alert open while the chain
+// expires within the warning window and resolves it once it was renewed.
+func (u *MonitorUseCase) checkCertificate(target *domain.Target, result *domain.Result, info *domain.TLSInfo) {
+	expiresAt := info.ExpiresAt()
+	if expiresAt.IsZero() {
+		return
+	}
+
+	unresolvedAlerts, _ := u.alertRepo.GetUnresolvedByTargetID(target.ID)
+
+	var open []*domain.Alert
+	for _, alert := range unresolvedAlerts {
+		if alert.Type == domain.AlertTypeCertificateExpiry {
+			open = append(open, alert)
+		}
+	}
+
+	remaining := expiresAt.Sub(u.now())
+
+	if remaining > u.certExpiryWarning {
+		for _, alert := range open {
+			u.resolveAlert(target, result, alert)
+		}
+		return
+	}
+
+	if len(open) > 0 {
+		return
+	}
+
+	message := fmt.Sprintf("TLS certificate of %s expires in %d days (%s)", target.URL, int(remaining.Hours()/24), expiresAt.UTC().Format(time.RFC3339))
+	if remaining <= 0 {
+		message = fmt.Sprintf("TLS certificate of %s expired on %s", target.URL, expiresAt.UTC().Format(time.RFC3339))
+	}
+
+	u.openAlert(target, result, domain.AlertTypeCertificateExpiry, message)
+}
+
+func (u *MonitorUseCase) after(d time.Duration) <-chan time.Time {
+	if u.clock != nil {
+		return u.clock.After(d)
+	}
+
+	return time.After(d)
+}
+
+func (u *MonitorUseCase) now() time.Time {
+	if u.clock != nil {
+		return u.clock.Now()
+	}
+
+	return time.Now()
+}
+
+// checkStatus judges HTTP responses by the target's accepted status codes and
+// body assertions; other protocols are OK unless the checker reported an
+// error.
+func checkStatus(target *domain.Target, resp *domain.CheckResponse) string {
+	switch {
+	case resp.Error != nil:
+		return domain.StatusError
+	case target.Type() != domain.TargetTypeHTTP:
+		return domain.StatusOK
+	case target.EffectiveHTTPCheck().Accepts(resp.StatusCode) && resp.FailedAssertion != nil:
+		return domain.StatusAssertionFailed
+	case target.EffectiveHTTPCheck().Accepts(resp.StatusCode):
+		return domain.StatusOK
+	case resp.StatusCode >= 500:
+		return domain.StatusServerError
+	default:
+		return domain.StatusClientError
+	}
 }
@@ -2,7 +2,12 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
 	"testing"
 	"time"
 
@@ -14,7 +19,11 @@ import (
 // ========================[Target Repository]========================
 
 type MockTargetRepository struct {
-	FindByIDFunc func(id string) (*domain.Target, error)
+	FindByIDFunc   func(id string) (*domain.Target, error)
+	GetAllFunc     func() ([]*domain.Target, error)
+	UpdatedTargets []*domain.Target
+	ChangeStateFunc func(change *domain.StateChange) (bool, error)
+	ChangedStates   []*domain.StateChange
 }
 
 func (m *MockTargetRepository) FindByID(id string) (*domain.Target, error) {
@@ -26,10 +35,31 @@ func (m *MockTargetRepository) Delete(id string) error {
 }
 
 func (m *MockTargetRepository) Update(target *domain.Target) error {
+	m.UpdatedTargets = append(m.UpdatedTargets, target)
+	return nil
+}
+
+func (m *MockTargetRepository) ChangeState(change *domain.StateChange) (bool, error) {
+	if m.ChangeStateFunc != nil {
+		changed, err := m.ChangeStateFunc(change)
+		if !changed || err != nil {
+			return changed, err
+		}
+	}
+
+	m.ChangedStates = append(m.ChangedStates, change)
+	return true, nil
+}
+
+func (m *MockTargetRepository) SetEscalationPolicy(id, policyID string) error {
 	return nil
 }
 
 func (m *MockTargetRepository) GetAll() ([]*domain.Target, error) {
+	if m.GetAllFunc != nil {
+		return m.GetAllFunc()
+	}
+
 	return []*domain.Target{}, nil
 }
 
@@ -43,6 +73,9 @@ type MockResultRepository struct {
 	SaveFunc              func(result *domain.Result) error
 	SavedResults          []*domain.Result
 	GetLastByTargetIDFunc func(targetID string) (*domain.Result, error)
+	FindByTargetIDFunc    func(targetID string) ([]*domain.Result, error)
+	QueryByTargetIDFunc   func(targetID string, query domain.ResultQuery) (*domain.ResultPage, error)
+	DeleteBeforeFunc      func(targetID string, before time.Time) (int, error)
 }
 
 func (m *MockResultRepository) Save(result *domain.Result) error {
@@ -63,9 +96,29 @@ func (m *MockResultRepository) GetLastByTargetID(targetID string) (*domain.Resul
 }
 
 func (m *MockResultRepository) FindByTargetID(targetID string) ([]*domain.Result, error) {
+	if m.FindByTargetIDFunc != nil {
+		return m.FindByTargetIDFunc(targetID)
+	}
+
 	return []*domain.Result{}, nil
 }
 
+func (m *MockResultRepository) QueryByTargetID(targetID string, query domain.ResultQuery) (*domain.ResultPage, error) {
+	if m.QueryByTargetIDFunc != nil {
+		return m.QueryByTargetIDFunc(targetID, query)
+	}
+
+	return &domain.ResultPage{Results: []*domain.Result{}}, nil
+}
+
+func (m *MockResultRepository) DeleteBefore(targetID string, before time.Time) (int, error) {
+	if m.DeleteBeforeFunc != nil {
+		return m.DeleteBeforeFunc(targetID, before)
+	}
+
+	return 0, nil
+}
+
 // ========================[Alert Repository]========================
 
 type MockAlertRepository struct {
@@ -74,6 +127,10 @@ type MockAlertRepository struct {
 	GetUnresolvedByTargetIDFunc func(targetID string) ([]*domain.Alert, error)
 	UpdateFunc                  func(alert *domain.Alert) error
 	UpdatedAlerts               []*domain.Alert
+	FindByTargetIDFunc          func(targetID string) ([]*domain.Alert, error)
+
+	FindByIDFunc          func(id string) (*domain.Alert, error)
+	GetUnacknowledgedFunc func() ([]*domain.Alert, error)
 }
 
 func (m *MockAlertRepository) Save(alert *domain.Alert) error {
@@ -103,18 +160,361 @@ func (m *MockAlertRepository) Update(alert *domain.Alert) error {
 }
 
 func (m *MockAlertRepository) FindByTargetID(targetID string) ([]*domain.Alert, error) {
+	if m.FindByTargetIDFunc != nil {
+		return m.FindByTargetIDFunc(targetID)
+	}
+
+	return []*domain.Alert{}, nil
+}
+
+func (m *MockAlertRepository) FindByID(id string) (*domain.Alert, error) {
+	if m.FindByIDFunc != nil {
+		return m.FindByIDFunc(id)
+	}
+
+	return nil, domain.ErrNotFound
+}
+
+func (m *MockAlertRepository) GetUnacknowledged() ([]*domain.Alert, error) {
+	if m.GetUnacknowledgedFunc != nil {
+		return m.GetUnacknowledgedFunc()
+	}
+
 	return []*domain.Alert{}, nil
 }
 
-// ========================[HTTP Client]========================
+func (m *MockAlertRepository) Acknowledge(id, by string, at time.Time) error {
+	return nil
+}
+
+func (m *MockAlertRepository) Snooze(id string, until time.Time) error {
+	return nil
+}
+
+func (m *MockAlertRepository) Assign(id, assignee string) error {
+	return nil
+}
+
+// Remind and Resolve change the alert among SavedAlerts, or a stand-in with
+// only its ID when it is not there, and record it in UpdatedAlerts.
+func (m *MockAlertRepository) Remind(id string, at time.Time) (bool, error) {
+	return m.change(id, func(alert *domain.Alert) bool {
+		if alert.IsResolved || alert.IsSilenced(at) {
+			return false
+		}
+
+		alert.NotifiedAt = at
+		return true
+	})
+}
+
+func (m *MockAlertRepository) Resolve(id string, at time.Time) (bool, error) {
+	return m.change(id, func(alert *domain.Alert) bool {
+		if alert.IsResolved {
+			return false
+		}
+
+		alert.ResolvedAt = &at
+		alert.IsResolved = true
+		return true
+	})
+}
+
+func (m *MockAlertRepository) change(id string, fn func(alert *domain.Alert) bool) (bool, error) {
+	alert := &domain.Alert{ID: id}
+	for _, saved := range m.SavedAlerts {
+		if saved.ID == id {
+			alert = saved
+		}
+	}
+
+	if !fn(alert) {
+		return false, nil
+	}
+
+	m.UpdatedAlerts = append(m.UpdatedAlerts, alert)
+	return true, nil
+}
+
+// ========================[Rollup Repository]========================
+
+// MockRollupRepository serves FindByTargetID from the saved rollups unless
+// FindByTargetIDFunc is set, so compaction can read back what it wrote.
+type MockRollupRepository struct {
+	SaveFunc           func(rollup *domain.Rollup) error
+	SavedRollups       []*domain.Rollup
+	FindByTargetIDFunc func(targetID string, resolution domain.RollupResolution, from, to time.Time) ([]*domain.Rollup, error)
+	DeleteBeforeFunc   func(targetID string, resolution domain.RollupResolution, before time.Time) (int, error)
+	DeletedBefore      map[domain.RollupResolution]time.Time
+}
+
+func (m *MockRollupRepository) Save(rollup *domain.Rollup) error {
+	m.SavedRollups = append(m.SavedRollups, rollup)
+	if m.SaveFunc != nil {
+		return m.SaveFunc(rollup)
+	}
+
+	return nil
+}
+
+func (m *MockRollupRepository) FindByTargetID(targetID string, resolution domain.RollupResolution, from, to time.Time) ([]*domain.Rollup, error) {
+	if m.FindByTargetIDFunc != nil {
+		return m.FindByTargetIDFunc(targetID, resolution, from, to)
+	}
+
+	latest := make(map[time.Time]*domain.Rollup)
+	for _, rollup := range m.SavedRollups {
+		if rollup.TargetID == targetID && rollup.Resolution == resolution &&
+			!rollup.BucketStart.Before(from) && rollup.BucketStart.Before(to) {
+			latest[rollup.BucketStart] = rollup
+		}
+	}
+
+	found := make([]*domain.Rollup, 0, len(latest))
+	for _, rollup := range latest {
+		found = append(found, rollup)
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].BucketStart.Before(found[j].BucketStart) })
+
+	return found, nil
+}
+
+func (m *MockRollupRepository) DeleteBefore(targetID string, resolution domain.RollupResolution, before time.Time) (int, error) {
+	if m.DeletedBefore == nil {
+		m.DeletedBefore = make(map[domain.RollupResolution]time.Time)
+	}
+	m.DeletedBefore[resolution] = before
+
+	if m.DeleteBeforeFunc != nil {
+		return m.DeleteBeforeFunc(targetID, resolution, before)
+	}
+
+	return 0, nil
+}
+
+// ========================[State Change Repository]========================
+
+type MockStateChangeRepository struct {
+	SavedChanges []*domain.StateChange
+}
+
+func (m *MockStateChangeRepository) Save(change *domain.StateChange) error {
+	m.SavedChanges = append(m.SavedChanges, change)
+	return nil
+}
+
+func (m *MockStateChangeRepository) FindByTargetID(targetID string) ([]*domain.StateChange, error) {
+	return m.SavedChanges, nil
+}
+
+// ========================[Escalation Policy Repository]========================
+
+type MockEscalationPolicyRepository struct {
+	Policies map[string]*domain.EscalationPolicy
+}
+
+func (m *MockEscalationPolicyRepository) Save(policy *domain.EscalationPolicy) error {
+	m.Policies[policy.ID] = policy
+	return nil
+}
+
+func (m *MockEscalationPolicyRepository) FindByID(id string) (*domain.EscalationPolicy, error) {
+	if policy, exists := m.Policies[id]; exists {
+		return policy, nil
+	}
+
+	return nil, fmt.Errorf("escalation policy with id: %s %w", id, domain.ErrNotFound)
+}
+
+func (m *MockEscalationPolicyRepository) GetAll() ([]*domain.EscalationPolicy, error) {
+	policies := make([]*domain.EscalationPolicy, 0, len(m.Policies))
+	for _, policy := range m.Policies {
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+func (m *MockEscalationPolicyRepository) Delete(id string) error {
+	delete(m.Policies, id)
+	return nil
+}
+
+// ========================[Maintenance Window Repository]========================
+
+type MockMaintenanceWindowRepository struct {
+	Windows []*domain.MaintenanceWindow
+}
+
+func (m *MockMaintenanceWindowRepository) Save(window *domain.MaintenanceWindow) error {
+	m.Windows = append(m.Windows, window)
+	return nil
+}
+
+func (m *MockMaintenanceWindowRepository) FindByID(id string) (*domain.MaintenanceWindow, error) {
+	for _, window := range m.Windows {
+		if window.ID == id {
+			return window, nil
+		}
+	}
+
+	return nil, fmt.Errorf("maintenance window with id: %s %w", id, domain.ErrNotFound)
+}
+
+func (m *MockMaintenanceWindowRepository) GetAll() ([]*domain.MaintenanceWindow, error) {
+	return m.Windows, nil
+}
+
+func (m *MockMaintenanceWindowRepository) Delete(id string) error {
+	for i, window := range m.Windows {
+		if window.ID == id {
+			m.Windows = append(m.Windows[:i], m.Windows[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+// ========================[Outbox Repository]========================
+
+// MockOutboxRepository saves alerts to Alerts and keeps the queued messages in
+// memory.
+type MockOutboxRepository struct {
+	Alerts          *MockAlertRepository
+	Messages        []*domain.OutboxMessage
+	UpdatedMessages []*domain.OutboxMessage
+	DeletedIDs      []string
+}
+
+func (m *MockOutboxRepository) SaveAlert(alert *domain.Alert, messages []*domain.OutboxMessage) error {
+	m.Messages = append(m.Messages, messages...)
+	return m.Alerts.Save(alert)
+}
+
+func (m *MockOutboxRepository) RemindAlert(alertID string, at time.Time, messages []*domain.OutboxMessage) (bool, error) {
+	changed, _ := m.Alerts.Remind(alertID, at)
+	if changed {
+		m.Messages = append(m.Messages, messages...)
+	}
+
+	return changed, nil
+}
+
+func (m *MockOutboxRepository) ResolveAlert(alertID string, at time.Time, messages []*domain.OutboxMessage) (bool, error) {
+	changed, _ := m.Alerts.Resolve(alertID, at)
+	if changed {
+		m.Messages = append(m.Messages, messages...)
+	}
+
+	return changed, nil
+}
+
+// AdvanceEscalation changes the alert among Alerts.SavedAlerts and records it
+// in Alerts.UpdatedAlerts.
+func (m *MockOutboxRepository) AdvanceEscalation(alertID string, from, to int, messages []*domain.OutboxMessage) (bool, error) {
+	for _, alert := range m.Alerts.SavedAlerts {
+		if alert.ID != alertID || alert.IsResolved || alert.IsAcknowledged() || alert.EscalationStep != from {
+			continue
+		}
+
+		alert.EscalationStep = to
+		m.Alerts.UpdatedAlerts = append(m.Alerts.UpdatedAlerts, alert)
+		m.Messages = append(m.Messages, messages...)
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (m *MockOutboxRepository) FindByID(id string) (*domain.OutboxMessage, error) {
+	for _, message := range m.Messages {
+		if message.ID == id {
+			return message, nil
+		}
+	}
+
+	return nil, fmt.Errorf("outbox message with id: %s %w", id, domain.ErrNotFound)
+}
+
+func (m *MockOutboxRepository) Due(now time.Time, limit int) ([]*domain.OutboxMessage, error) {
+	var due []*domain.OutboxMessage
+	for _, message := range m.Messages {
+		if !message.IsDead() && !message.NextAttemptAt.After(now) && !m.waiting(message) && len(due) < limit {
+			due = append(due, message)
+		}
+	}
+
+	return due, nil
+}
+
+func (m *MockOutboxRepository) waiting(message *domain.OutboxMessage) bool {
+	for _, other := range m.Messages {
+		if !other.IsDead() && other.Precedes(message) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *MockOutboxRepository) DeadLetters() ([]*domain.OutboxMessage, error) {
+	var dead []*domain.OutboxMessage
+	for _, message := range m.Messages {
+		if message.IsDead() {
+			dead = append(dead, message)
+		}
+	}
+
+	return dead, nil
+}
+
+func (m *MockOutboxRepository) Update(message *domain.OutboxMessage) error {
+	m.UpdatedMessages = append(m.UpdatedMessages, message)
+	return nil
+}
+
+func (m *MockOutboxRepository) Delete(id string) error {
+	m.DeletedIDs = append(m.DeletedIDs, id)
+	for i, message := range m.Messages {
+		if message.ID == id {
+			m.Messages = append(m.Messages[:i], m.Messages[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("outbox message with id: %s %w", id, domain.ErrNotFound)
+}
+
+// ========================[Notifier]========================
+
+type MockNotifier struct {
+	mu            sync.Mutex
+	NotifyFunc    func(ctx context.Context, n domain.Notification) error
+	Notifications []domain.Notification
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, n domain.Notification) error {
+	m.mu.Lock()
+	m.Notifications = append(m.Notifications, n)
+	m.mu.Unlock()
+
+	if m.NotifyFunc != nil {
+		return m.NotifyFunc(ctx, n)
+	}
+
+	return nil
+}
+
+// ========================[Checker]========================
 
-type MockHTTPClient struct {
-	CheckFunc func(ctx context.Context, url string) (*domain.HTTPResponse, error)
+type MockChecker struct {
+	CheckFunc func(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error)
 }
 
-func (m *MockHTTPClient) Check(ctx context.Context, url string) (*domain.HTTPResponse, error) {
+func (m *MockChecker) Check(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
 	if m.CheckFunc != nil {
-		return m.CheckFunc(ctx, url)
+		return m.CheckFunc(ctx, target)
 	}
 
 	return nil, nil
@@ -187,10 +587,10 @@ func newMockIDGenerator() *MockIDGenerator {
 	}
 }
 
-func newMockHTTPClient() *MockHTTPClient {
-	return &MockHTTPClient{
-		CheckFunc: func(ctx context.Context, url string) (*domain.HTTPResponse, error) {
-			return &domain.HTTPResponse{
+func newMockChecker() *MockChecker {
+	return &MockChecker{
+		CheckFunc: func(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+			return &domain.CheckResponse{
 				StatusCode:   200,
 				ResponseTime: 100 * time.Millisecond,
 			}, nil
@@ -204,10 +604,10 @@ func TestCheckTarget_StatusOK(t *testing.T) {
 	mockTargetRepo := newMockTargetRepository()
 	mockResultRepo := newMockResultRepository()
 	mockAlertRepo := newMockAlertRepository()
-	mockHTTPClient := newMockHTTPClient()
+	mockChecker := newMockChecker()
 	mockIDGenerator := newMockIDGenerator()
 
-	usecase := NewMonitorUseCase(mockTargetRepo, mockResultRepo, mockAlertRepo, mockHTTPClient, mockIDGenerator)
+	usecase := NewMonitorUseCase(mockTargetRepo, mockResultRepo, mockAlertRepo, mockChecker, mockIDGenerator)
 
 	ctx := context.Background()
 	err := usecase.CheckTarget(ctx, "target-1")
@@ -237,17 +637,17 @@ func TestCheckTarget_StatusError(t *testing.T) {
 	mockTargetRepo := newMockTargetRepository()
 	mockResultRepo := newMockResultRepository()
 	mockAlertRepo := newMockAlertRepository()
-	mockHTTPClient := newMockHTTPClient()
+	mockChecker := newMockChecker()
 	mockIDGenerator := newMockIDGenerator()
 
-	mockHTTPClient.CheckFunc = func(ctx context.Context, url string) (*domain.HTTPResponse, error) {
-		return &domain.HTTPResponse{
+	mockChecker.CheckFunc = func(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+		return &domain.CheckResponse{
 			StatusCode:   500,
 			ResponseTime: 30 * time.Millisecond,
 		}, nil
 	}
 
-	usecase := NewMonitorUseCase(mockTargetRepo, mockResultRepo, mockAlertRepo, mockHTTPClient, mockIDGenerator)
+	usecase := NewMonitorUseCase(mockTargetRepo, mockResultRepo, mockAlertRepo, mockChecker, mockIDGenerator)
 
 	ctx := context.Background()
 	err := usecase.CheckTarget(ctx, "target-1")
@@ -269,21 +669,94 @@ func TestCheckTarget_StatusError(t *testing.T) {
 	}
 }
 
+func TestCheckTarget_AcceptedStatusFromSpec(t *testing.T) {
+	mockTargetRepo := newMockTargetRepository()
+	mockTargetRepo.FindByIDFunc = func(id string) (*domain.Target, error) {
+		target := domain.NewTarget(id, "https://example.com/login", "Login", time.Minute)
+		target.HTTP = &domain.HTTPCheck{
+			Method:         "POST",
+			AcceptedStatus: []domain.StatusRange{{Min: 401, Max: 401}},
+		}
+		return target, nil
+	}
+
+	statusCode := 401
+	mockChecker := newMockChecker()
+	mockChecker.CheckFunc = func(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+		return &domain.CheckResponse{StatusCode: statusCode, ResponseTime: time.Millisecond}, nil
+	}
+
+	mockResultRepo := newMockResultRepository()
+	usecase := NewMonitorUseCase(mockTargetRepo, mockResultRepo, newMockAlertRepository(), mockChecker, newMockIDGenerator())
+
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	statusCode = 200
+
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if got := mockResultRepo.SavedResults[0].Status; got != domain.StatusOK {
+		t.Errorf("expected an accepted 401 to be OK, got %s", got)
+	}
+
+	if got := mockResultRepo.SavedResults[1].Status; got != domain.StatusClientError {
+		t.Errorf("expected a 200 outside the accepted ranges to fail, got %s", got)
+	}
+}
+
+func TestCheckTarget_AssertionFailed(t *testing.T) {
+	assertion := domain.Assertion{Type: domain.AssertionContains, Value: "healthy"}
+
+	mockChecker := newMockChecker()
+	mockChecker.CheckFunc = func(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+		return &domain.CheckResponse{
+			StatusCode:      200,
+			ResponseTime:    time.Millisecond,
+			FailedAssertion: &domain.AssertionError{Assertion: assertion, Reason: "keyword not found"},
+		}, nil
+	}
+
+	mockResultRepo := newMockResultRepository()
+	mockAlertRepo := newMockAlertRepository()
+	usecase := NewMonitorUseCase(newMockTargetRepository(), mockResultRepo, mockAlertRepo, mockChecker, newMockIDGenerator())
+
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	result := mockResultRepo.SavedResults[0]
+	if result.Status != domain.StatusAssertionFailed || result.Error == nil {
+		t.Errorf("expected an ASSERTION_FAILED result with an error, got %s / %v", result.Status, result.Error)
+	}
+
+	if len(mockAlertRepo.SavedAlerts) != 1 {
+		t.Fatalf("expected 1 alert, got %d", len(mockAlertRepo.SavedAlerts))
+	}
+
+	if alert := mockAlertRepo.SavedAlerts[0]; alert.Type != domain.StatusAssertionFailed || !strings.Contains(alert.Message, `body contains "healthy"`) {
+		t.Errorf("expected the alert to name the assertion, got %s: %s", alert.Type, alert.Message)
+	}
+}
+
 func TestCheckTarget_AlertCreated(t *testing.T) {
 	mockTargetRepo := newMockTargetRepository()
 	mockResultRepo := newMockResultRepository()
 	mockAlertRepo := newMockAlertRepository()
-	mockHTTPClient := newMockHTTPClient()
+	mockChecker := newMockChecker()
 	mockIDGenerator := newMockIDGenerator()
 
-	mockHTTPClient.CheckFunc = func(ctx context.Context, url string) (*domain.HTTPResponse, error) {
-		return &domain.HTTPResponse{
+	mockChecker.CheckFunc = func(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+		return &domain.CheckResponse{
 			StatusCode:   500,
 			ResponseTime: 30 * time.Millisecond,
 		}, nil
 	}
 
-	usecase := NewMonitorUseCase(mockTargetRepo, mockResultRepo, mockAlertRepo, mockHTTPClient, mockIDGenerator)
+	usecase := NewMonitorUseCase(mockTargetRepo, mockResultRepo, mockAlertRepo, mockChecker, mockIDGenerator)
 
 	ctx := context.Background()
 	err := usecase.CheckTarget(ctx, "target-1")
@@ -313,7 +786,7 @@ func TestCheckTarget_AlertResolved(t *testing.T) {
 	mockTargetRepo := newMockTargetRepository()
 	mockResultRepo := newMockResultRepository()
 	mockAlertRepo := newMockAlertRepository()
-	mockHTTPClient := newMockHTTPClient()
+	mockChecker := newMockChecker()
 	mockIDGenerator := newMockIDGenerator()
 
 	mockAlertRepo.GetUnresolvedByTargetIDFunc = func(targetID string) ([]*domain.Alert, error) {
@@ -322,7 +795,7 @@ func TestCheckTarget_AlertResolved(t *testing.T) {
 		}, nil
 	}
 
-	usecase := NewMonitorUseCase(mockTargetRepo, mockResultRepo, mockAlertRepo, mockHTTPClient, mockIDGenerator)
+	usecase := NewMonitorUseCase(mockTargetRepo, mockResultRepo, mockAlertRepo, mockChecker, mockIDGenerator)
 
 	ctx := context.Background()
 	err := usecase.CheckTarget(ctx, "target-1")
@@ -342,4 +815,848 @@ func TestCheckTarget_AlertResolved(t *testing.T) {
 	if mockAlertRepo.UpdatedAlerts[0].ResolvedAt == nil {
 		t.Error("Resolved alerts should get time of resolve")
 	}
-}
\ No newline at end of file
+}
+func TestCheckTarget_DispatchesByTargetType(t *testing.T) {
+	mockTargetRepo := &MockTargetRepository{
+		FindByIDFunc: func(id string) (*domain.Target, error) {
+			return domain.NewTarget(id, "tcp://db.internal:5432", "db", time.Minute), nil
+		},
+	}
+	mockResultRepo := newMockResultRepository()
+	mockAlertRepo := newMockAlertRepository()
+
+	httpChecker := &MockChecker{
+		CheckFunc: func(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+			t.Error("expected the HTTP checker not to be used for a tcp target")
+			return nil, nil
+		},
+	}
+
+	var checked *domain.Target
+	tcpChecker := &MockChecker{
+		CheckFunc: func(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+			checked = target
+			return &domain.CheckResponse{ResponseTime: 5 * time.Millisecond}, nil
+		},
+	}
+
+	usecase := NewMonitorUseCase(mockTargetRepo, mockResultRepo, mockAlertRepo, httpChecker, newMockIDGenerator(),
+		WithChecker(domain.TargetTypeTCP, tcpChecker))
+
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if checked == nil || checked.URL != "tcp://db.internal:5432" {
+		t.Fatalf("expected the tcp checker to receive the target, got %+v", checked)
+	}
+
+	if result := mockResultRepo.SavedResults[0]; result.Status != domain.StatusOK || result.StatusCode != 0 {
+		t.Errorf("expected OK without status code, got %s/%d", result.Status, result.StatusCode)
+	}
+}
+
+func TestCheckTarget_NonHTTPFailureRaisesAlert(t *testing.T) {
+	mockTargetRepo := &MockTargetRepository{
+		FindByIDFunc: func(id string) (*domain.Target, error) {
+			return domain.NewTarget(id, "tcp://mail.internal:25?expect=220", "mail", time.Minute), nil
+		},
+	}
+	mockResultRepo := newMockResultRepository()
+	mockAlertRepo := newMockAlertRepository()
+
+	tcpChecker := &MockChecker{
+		CheckFunc: func(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+			return &domain.CheckResponse{ResponseTime: time.Millisecond, Error: fmt.Errorf("expected \"220\" in banner")}, nil
+		},
+	}
+
+	usecase := NewMonitorUseCase(mockTargetRepo, mockResultRepo, mockAlertRepo, newMockChecker(), newMockIDGenerator(),
+		WithChecker(domain.TargetTypeTCP, tcpChecker))
+
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if result := mockResultRepo.SavedResults[0]; result.Status != domain.StatusError || result.Error == nil {
+		t.Errorf("expected ERROR result carrying the checker error, got %s/%v", result.Status, result.Error)
+	}
+
+	if len(mockAlertRepo.SavedAlerts) != 1 || mockAlertRepo.SavedAlerts[0].Type != domain.StatusError {
+		t.Errorf("expected an ERROR alert, got %d alerts", len(mockAlertRepo.SavedAlerts))
+	}
+}
+
+func TestCheckTarget_UnsupportedTargetType(t *testing.T) {
+	mockTargetRepo := &MockTargetRepository{
+		FindByIDFunc: func(id string) (*domain.Target, error) {
+			return domain.NewTarget(id, "gopher://example.com", "old", time.Minute), nil
+		},
+	}
+	mockResultRepo := newMockResultRepository()
+
+	usecase := NewMonitorUseCase(mockTargetRepo, mockResultRepo, newMockAlertRepository(), newMockChecker(), newMockIDGenerator())
+
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err == nil {
+		t.Error("expected an error for a target type without checker")
+	}
+
+	if len(mockResultRepo.SavedResults) != 0 {
+		t.Errorf("expected no result, got %d", len(mockResultRepo.SavedResults))
+	}
+}
+
+func tlsChecker(expiresAt time.Time) *MockChecker {
+	return &MockChecker{
+		CheckFunc: func(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+			return &domain.CheckResponse{
+				StatusCode:   200,
+				ResponseTime: time.Millisecond,
+				TLS: &domain.TLSInfo{
+					Chain:           []domain.Certificate{{Subject: "CN=example.com", NotAfter: expiresAt}},
+					Verified:        true,
+					HostnameMatches: true,
+				},
+			}, nil
+		},
+	}
+}
+
+func TestCheckTarget_CertificateExpiringSoon(t *testing.T) {
+	clock := newMockClock()
+	mockResultRepo := newMockResultRepository()
+	mockAlertRepo := newMockAlertRepository()
+
+	certAlert := domain.NewAlert("cert-old", "target-1", domain.AlertTypeCertificateExpiry, "expires")
+	mockAlertRepo.GetUnresolvedByTargetIDFunc = func(targetID string) ([]*domain.Alert, error) {
+		return nil, nil
+	}
+
+	usecase := NewMonitorUseCase(newMockTargetRepository(), mockResultRepo, mockAlertRepo, tlsChecker(clock.Now().Add(5*24*time.Hour)), newMockIDGenerator(),
+		WithMonitorClock(clock), WithCertificateExpiryWarning(7))
+
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(mockAlertRepo.SavedAlerts) != 1 || mockAlertRepo.SavedAlerts[0].Type != domain.AlertTypeCertificateExpiry {
+		t.Fatalf("expected a certificate expiry alert, got %+v", mockAlertRepo.SavedAlerts)
+	}
+
+	// A healthy check must neither resolve nor duplicate the open alert.
+	mockAlertRepo.GetUnresolvedByTargetIDFunc = func(targetID string) ([]*domain.Alert, error) {
+		return []*domain.Alert{certAlert}, nil
+	}
+
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(mockAlertRepo.SavedAlerts) != 1 {
+		t.Errorf("expected no duplicate alert, got %d", len(mockAlertRepo.SavedAlerts))
+	}
+
+	if certAlert.IsResolved {
+		t.Error("expected the certificate alert to stay open while the certificate is still expiring")
+	}
+}
+
+func TestCheckTarget_CertificateRenewed(t *testing.T) {
+	clock := newMockClock()
+	mockAlertRepo := newMockAlertRepository()
+
+	certAlert := domain.NewAlert("cert-1", "target-1", domain.AlertTypeCertificateExpiry, "expires")
+	mockAlertRepo.GetUnresolvedByTargetIDFunc = func(targetID string) ([]*domain.Alert, error) {
+		return []*domain.Alert{certAlert}, nil
+	}
+
+	usecase := NewMonitorUseCase(newMockTargetRepository(), newMockResultRepository(), mockAlertRepo, tlsChecker(clock.Now().Add(90*24*time.Hour)), newMockIDGenerator(),
+		WithMonitorClock(clock))
+
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !certAlert.IsResolved {
+		t.Error("expected the certificate alert to be resolved after renewal")
+	}
+
+	if len(mockAlertRepo.SavedAlerts) != 0 {
+		t.Errorf("expected no new alert, got %d", len(mockAlertRepo.SavedAlerts))
+	}
+}
+
+func TestCheckTarget_CertificateExpired(t *testing.T) {
+	clock := newMockClock()
+	mockAlertRepo := newMockAlertRepository()
+	mockAlertRepo.GetUnresolvedByTargetIDFunc = func(targetID string) ([]*domain.Alert, error) {
+		return nil, nil
+	}
+
+	checker := &MockChecker{
+		CheckFunc: func(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+			return &domain.CheckResponse{
+				Error: fmt.Errorf("TLS certificate verification failed"),
+				TLS: &domain.TLSInfo{
+					Chain: []domain.Certificate{{NotAfter: clock.Now().Add(-time.Hour)}},
+				},
+			}, nil
+		},
+	}
+
+	usecase := NewMonitorUseCase(newMockTargetRepository(), newMockResultRepository(), mockAlertRepo, checker, newMockIDGenerator(),
+		WithMonitorClock(clock))
+
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var types []string
+	for _, alert := range mockAlertRepo.SavedAlerts {
+		types = append(types, alert.Type)
+	}
+
+	if len(types) != 2 || types[0] != domain.StatusError || types[1] != domain.AlertTypeCertificateExpiry {
+		t.Errorf("expected an ERROR and a certificate alert, got %v", types)
+	}
+}
+
+func targetWithPolicy(policy domain.CheckPolicy) *MockTargetRepository {
+	return &MockTargetRepository{
+		FindByIDFunc: func(id string) (*domain.Target, error) {
+			target := domain.NewTarget(id, "https://example.com", "", time.Minute)
+			target.Policy = &policy
+			return target, nil
+		},
+	}
+}
+
+func statusSequence(statusCodes ...int) *MockChecker {
+	calls := 0
+	return &MockChecker{
+		CheckFunc: func(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+			code := statusCodes[min(calls, len(statusCodes)-1)]
+			calls++
+			return &domain.CheckResponse{StatusCode: code, ResponseTime: time.Millisecond}, nil
+		},
+	}
+}
+
+func resultHistory(statuses ...string) *MockResultRepository {
+	var results []*domain.Result
+	for i, status := range statuses {
+		results = append(results, domain.NewResult(fmt.Sprint(i), "target-1", status, 0, 0))
+	}
+
+	return &MockResultRepository{
+		QueryByTargetIDFunc: func(targetID string, query domain.ResultQuery) (*domain.ResultPage, error) {
+			return &domain.ResultPage{Results: results[:min(query.Limit, len(results))]}, nil
+		},
+	}
+}
+
+func TestCheckTarget_RetriesBeforeRecordingFailure(t *testing.T) {
+	policy := domain.CheckPolicy{Retries: 2, RetryBackoff: time.Millisecond}
+	mockChecker := statusSequence(503, 503, 200)
+	mockResultRepo := newMockResultRepository()
+	mockAlertRepo := newMockAlertRepository()
+
+	usecase := NewMonitorUseCase(targetWithPolicy(policy), mockResultRepo, mockAlertRepo, mockChecker, newMockIDGenerator())
+
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(mockResultRepo.SavedResults) != 1 || mockResultRepo.SavedResults[0].Status != domain.StatusOK {
+		t.Errorf("expected only the successful retry to be recorded, got %+v", mockResultRepo.SavedResults)
+	}
+
+	if len(mockAlertRepo.SavedAlerts) != 0 {
+		t.Errorf("expected no alert, got %d", len(mockAlertRepo.SavedAlerts))
+	}
+}
+
+func TestCheckTarget_RetriesExhausted(t *testing.T) {
+	policy := domain.CheckPolicy{Retries: 2, RetryBackoff: time.Millisecond}
+
+	calls := 0
+	mockChecker := &MockChecker{
+		CheckFunc: func(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+			calls++
+			return &domain.CheckResponse{StatusCode: 503}, nil
+		},
+	}
+
+	mockResultRepo := newMockResultRepository()
+	usecase := NewMonitorUseCase(targetWithPolicy(policy), mockResultRepo, newMockAlertRepository(), mockChecker, newMockIDGenerator())
+
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if calls != 3 {
+		t.Errorf("expected 1 check and 2 retries, got %d calls", calls)
+	}
+
+	if len(mockResultRepo.SavedResults) != 1 || mockResultRepo.SavedResults[0].Status != domain.StatusServerError {
+		t.Errorf("expected one SERVER_ERROR result, got %+v", mockResultRepo.SavedResults)
+	}
+}
+
+func TestCheckTarget_RetryStopsWhenCancelled(t *testing.T) {
+	policy := domain.CheckPolicy{Retries: 5, RetryBackoff: time.Hour}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mockChecker := &MockChecker{
+		CheckFunc: func(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+			cancel()
+			return &domain.CheckResponse{StatusCode: 503}, nil
+		},
+	}
+
+	mockResultRepo := newMockResultRepository()
+	usecase := NewMonitorUseCase(targetWithPolicy(policy), mockResultRepo, newMockAlertRepository(), mockChecker, newMockIDGenerator(),
+		WithMonitorClock(newMockClock()))
+
+	if err := usecase.CheckTarget(ctx, "target-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(mockResultRepo.SavedResults) != 1 || mockResultRepo.SavedResults[0].Status != domain.StatusServerError {
+		t.Errorf("expected the last attempt to be recorded, got %+v", mockResultRepo.SavedResults)
+	}
+}
+
+func TestCheckTarget_FailureThreshold(t *testing.T) {
+	policy := domain.CheckPolicy{FailureThreshold: 3}
+
+	tests := []struct {
+		name      string
+		history   []string
+		wantAlert bool
+	}{
+		{"first failure", []string{domain.StatusOK, domain.StatusServerError}, false},
+		{"second failure", []string{domain.StatusServerError, domain.StatusOK}, false},
+		{"third failure", []string{domain.StatusServerError, domain.StatusError, domain.StatusOK}, true},
+	}
+
+	for _, tt := range tests {
+		mockAlertRepo := newMockAlertRepository()
+		usecase := NewMonitorUseCase(targetWithPolicy(policy), resultHistory(tt.history...), mockAlertRepo, statusSequence(500), newMockIDGenerator())
+
+		if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+			t.Fatalf("%s: expected no error, got %v", tt.name, err)
+		}
+
+		if got := len(mockAlertRepo.SavedAlerts) == 1; got != tt.wantAlert {
+			t.Errorf("%s: expected alert %t, got %d alerts", tt.name, tt.wantAlert, len(mockAlertRepo.SavedAlerts))
+		}
+
+		if tt.wantAlert && !strings.Contains(mockAlertRepo.SavedAlerts[0].Message, "3 consecutive failures") {
+			t.Errorf("%s: expected the message to count the failures, got %s", tt.name, mockAlertRepo.SavedAlerts[0].Message)
+		}
+	}
+}
+
+func TestCheckTarget_NoDuplicateDowntimeAlert(t *testing.T) {
+	mockAlertRepo := newMockAlertRepository()
+	mockAlertRepo.GetUnresolvedByTargetIDFunc = func(targetID string) ([]*domain.Alert, error) {
+		return []*domain.Alert{domain.NewAlert("a-1", targetID, domain.StatusServerError, "down")}, nil
+	}
+
+	usecase := NewMonitorUseCase(newMockTargetRepository(), newMockResultRepository(), mockAlertRepo, statusSequence(500), newMockIDGenerator())
+
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(mockAlertRepo.SavedAlerts) != 0 {
+		t.Errorf("expected the open alert to cover the failure, got %d new alerts", len(mockAlertRepo.SavedAlerts))
+	}
+}
+
+func TestCheckTarget_RecoveryThreshold(t *testing.T) {
+	policy := domain.CheckPolicy{RecoveryThreshold: 2}
+
+	for _, tt := range []struct {
+		history     []string
+		wantResolve bool
+	}{
+		{[]string{domain.StatusServerError}, false},
+		{[]string{domain.StatusOK, domain.StatusServerError}, true},
+	} {
+		alert := domain.NewAlert("a-1", "target-1", domain.StatusServerError, "down")
+		mockAlertRepo := newMockAlertRepository()
+		mockAlertRepo.GetUnresolvedByTargetIDFunc = func(targetID string) ([]*domain.Alert, error) {
+			return []*domain.Alert{alert}, nil
+		}
+
+		usecase := NewMonitorUseCase(targetWithPolicy(policy), resultHistory(tt.history...), mockAlertRepo, statusSequence(200), newMockIDGenerator())
+
+		if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if alert.IsResolved != tt.wantResolve {
+			t.Errorf("history %v: expected resolved %t, got %t", tt.history, tt.wantResolve, alert.IsResolved)
+		}
+	}
+}
+
+func TestCheckTarget_TransportErrorRecorded(t *testing.T) {
+	mockChecker := &MockChecker{
+		CheckFunc: func(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+			return nil, fmt.Errorf("dial tcp: %w", syscall.ECONNREFUSED)
+		},
+	}
+
+	mockResultRepo := newMockResultRepository()
+	mockAlertRepo := newMockAlertRepository()
+	usecase := NewMonitorUseCase(newMockTargetRepository(), mockResultRepo, mockAlertRepo, mockChecker, newMockIDGenerator())
+
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+		t.Fatalf("expected the failure to be recorded, got %v", err)
+	}
+
+	if len(mockResultRepo.SavedResults) != 1 {
+		t.Fatalf("expected 1 saved result, got %d", len(mockResultRepo.SavedResults))
+	}
+
+	result := mockResultRepo.SavedResults[0]
+	if result.Status != domain.StatusError || result.ErrorKind != domain.ErrorKindRefused || result.Error == nil {
+		t.Errorf("expected a refused ERROR result, got %s %q %v", result.Status, result.ErrorKind, result.Error)
+	}
+
+	if len(mockAlertRepo.SavedAlerts) != 1 || mockAlertRepo.SavedAlerts[0].Type != domain.StatusError {
+		t.Fatalf("expected an ERROR alert, got %+v", mockAlertRepo.SavedAlerts)
+	}
+
+	if !strings.Contains(mockAlertRepo.SavedAlerts[0].Message, "(refused)") {
+		t.Errorf("expected the alert to name the error kind, got %s", mockAlertRepo.SavedAlerts[0].Message)
+	}
+}
+
+func TestCheckTarget_TransportErrorRetried(t *testing.T) {
+	policy := domain.CheckPolicy{Retries: 1, RetryBackoff: time.Millisecond}
+
+	calls := 0
+	mockChecker := &MockChecker{
+		CheckFunc: func(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+			calls++
+			if calls == 1 {
+				return nil, context.DeadlineExceeded
+			}
+			return &domain.CheckResponse{StatusCode: 200}, nil
+		},
+	}
+
+	mockResultRepo := newMockResultRepository()
+	usecase := NewMonitorUseCase(targetWithPolicy(policy), mockResultRepo, newMockAlertRepository(), mockChecker, newMockIDGenerator())
+
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if calls != 2 || len(mockResultRepo.SavedResults) != 1 || mockResultRepo.SavedResults[0].Status != domain.StatusOK {
+		t.Errorf("expected the retry to succeed, got %d calls and %+v", calls, mockResultRepo.SavedResults)
+	}
+}
+
+func TestCheckTarget_CancelledCheckNotRecorded(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mockChecker := &MockChecker{
+		CheckFunc: func(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+			cancel()
+			return nil, ctx.Err()
+		},
+	}
+
+	mockResultRepo := newMockResultRepository()
+	usecase := NewMonitorUseCase(newMockTargetRepository(), mockResultRepo, newMockAlertRepository(), mockChecker, newMockIDGenerator())
+
+	if err := usecase.CheckTarget(ctx, "target-1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	if len(mockResultRepo.SavedResults) != 0 {
+		t.Errorf("expected nothing to be recorded, got %+v", mockResultRepo.SavedResults)
+	}
+}
+
+func TestCheckTarget_StateTransitions(t *testing.T) {
+	target := domain.NewTarget("target-1", "https://example.com", "", time.Minute)
+	mockTargetRepo := &MockTargetRepository{
+		FindByIDFunc: func(id string) (*domain.Target, error) { return target, nil },
+	}
+
+	mockAlertRepo := newMockAlertRepository()
+	mockAlertRepo.GetUnresolvedByTargetIDFunc = func(targetID string) ([]*domain.Alert, error) {
+		var open []*domain.Alert
+		for _, alert := range mockAlertRepo.SavedAlerts {
+			if !alert.IsResolved {
+				open = append(open, alert)
+			}
+		}
+		return open, nil
+	}
+
+	mockStateRepo := &MockStateChangeRepository{}
+	usecase := NewMonitorUseCase(mockTargetRepo, newMockResultRepository(), mockAlertRepo, statusSequence(500, 503, 503, 200), newMockIDGenerator(),
+		WithStateChangeRepository(mockStateRepo))
+
+	wantStates := []string{domain.StateDown, domain.StateDown, domain.StateDown, domain.StateUp}
+	for i, want := range wantStates {
+		if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+			t.Fatalf("check %d: expected no error, got %v", i+1, err)
+		}
+
+		if target.State != want {
+			t.Errorf("check %d: expected %s, got %s", i+1, want, target.State)
+		}
+	}
+
+	if len(mockAlertRepo.SavedAlerts) != 1 || mockAlertRepo.SavedAlerts[0].Type != domain.StatusServerError {
+		t.Fatalf("expected one alert for going down, got %+v", mockAlertRepo.SavedAlerts)
+	}
+
+	if !mockAlertRepo.SavedAlerts[0].IsResolved {
+		t.Error("expected the alert to be resolved when the target came back up")
+	}
+
+	if len(mockStateRepo.SavedChanges) != 2 || len(mockTargetRepo.ChangedStates) != 2 {
+		t.Fatalf("expected 2 persisted state changes, got %d changes and %d target updates", len(mockStateRepo.SavedChanges), len(mockTargetRepo.ChangedStates))
+	}
+
+	first, second := mockStateRepo.SavedChanges[0], mockStateRepo.SavedChanges[1]
+	if first.From != domain.StateUnknown || first.To != domain.StateDown || first.Reason != domain.StatusServerError {
+		t.Errorf("unexpected first change %+v", first)
+	}
+
+	if second.From != domain.StateDown || second.To != domain.StateUp || second.ID == "" {
+		t.Errorf("unexpected second change %+v", second)
+	}
+}
+
+func TestCheckTarget_TargetPausedDuringCheck(t *testing.T) {
+	mockTargetRepo := &MockTargetRepository{
+		FindByIDFunc: func(id string) (*domain.Target, error) {
+			return domain.NewTarget("target-1", "https://example.com", "", time.Minute), nil
+		},
+		ChangeStateFunc: func(change *domain.StateChange) (bool, error) { return false, nil },
+	}
+
+	mockAlertRepo := newMockAlertRepository()
+	mockStateRepo := &MockStateChangeRepository{}
+	usecase := NewMonitorUseCase(mockTargetRepo, newMockResultRepository(), mockAlertRepo, statusSequence(500), newMockIDGenerator(),
+		WithStateChangeRepository(mockStateRepo))
+
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(mockTargetRepo.ChangedStates) != 0 || len(mockStateRepo.SavedChanges) != 0 {
+		t.Errorf("expected the stale state change to be dropped, got %+v", mockStateRepo.SavedChanges)
+	}
+
+	if len(mockAlertRepo.SavedAlerts) != 0 {
+		t.Errorf("expected no alert for a dropped state change, got %+v", mockAlertRepo.SavedAlerts)
+	}
+}
+
+func TestCheckTarget_PausedTargetKeepsState(t *testing.T) {
+	target := domain.NewTarget("target-1", "https://example.com", "", time.Minute)
+	target.Pause(time.Now())
+
+	mockTargetRepo := &MockTargetRepository{
+		FindByIDFunc: func(id string) (*domain.Target, error) { return target, nil },
+	}
+
+	mockResultRepo := newMockResultRepository()
+	mockAlertRepo := newMockAlertRepository()
+	usecase := NewMonitorUseCase(mockTargetRepo, mockResultRepo, mockAlertRepo, statusSequence(500), newMockIDGenerator())
+
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(mockResultRepo.SavedResults) != 1 {
+		t.Errorf("expected the manual check to be recorded, got %d results", len(mockResultRepo.SavedResults))
+	}
+
+	if target.State != domain.StatePaused || len(mockAlertRepo.SavedAlerts) != 0 {
+		t.Errorf("expected the target to stay paused without alerts, got %s and %d alerts", target.State, len(mockAlertRepo.SavedAlerts))
+	}
+}
+
+func TestCheckTarget_SlowResponseDegrades(t *testing.T) {
+	target := domain.NewTarget("target-1", "https://example.com", "", time.Minute)
+	target.Latency = &domain.LatencyThreshold{Max: 100 * time.Millisecond}
+
+	mockTargetRepo := &MockTargetRepository{
+		FindByIDFunc: func(id string) (*domain.Target, error) { return target, nil },
+	}
+
+	mockAlertRepo := newMockAlertRepository()
+	mockAlertRepo.GetUnresolvedByTargetIDFunc = func(targetID string) ([]*domain.Alert, error) {
+		var open []*domain.Alert
+		for _, alert := range mockAlertRepo.SavedAlerts {
+			if !alert.IsResolved {
+				open = append(open, alert)
+			}
+		}
+		return open, nil
+	}
+
+	responseTimes := []time.Duration{50 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond, 50 * time.Millisecond}
+	calls := 0
+	mockChecker := &MockChecker{
+		CheckFunc: func(ctx context.Context, target *domain.Target) (*domain.CheckResponse, error) {
+			responseTime := responseTimes[calls]
+			calls++
+			return &domain.CheckResponse{StatusCode: 200, ResponseTime: responseTime}, nil
+		},
+	}
+
+	usecase := NewMonitorUseCase(mockTargetRepo, newMockResultRepository(), mockAlertRepo, mockChecker, newMockIDGenerator())
+
+	wantStates := []string{domain.StateUp, domain.StateDegraded, domain.StateDegraded, domain.StateUp}
+	for i, want := range wantStates {
+		if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+			t.Fatalf("check %d: expected no error, got %v", i+1, err)
+		}
+
+		if target.State != want {
+			t.Errorf("check %d: expected %s, got %s", i+1, want, target.State)
+		}
+	}
+
+	if len(mockAlertRepo.SavedAlerts) != 1 || mockAlertRepo.SavedAlerts[0].Type != domain.AlertTypeSlowResponse {
+		t.Fatalf("expected one slow response alert, got %+v", mockAlertRepo.SavedAlerts)
+	}
+
+	if !mockAlertRepo.SavedAlerts[0].IsResolved {
+		t.Error("expected the slow response alert to resolve once latency recovered")
+	}
+}
+
+func TestCheckTarget_P95Degrades(t *testing.T) {
+	target := domain.NewTarget("target-1", "https://example.com", "", time.Minute)
+	target.Latency = &domain.LatencyThreshold{P95: 200 * time.Millisecond, P95Window: 5}
+
+	mockResultRepo := newMockResultRepository()
+	mockResultRepo.QueryByTargetIDFunc = func(targetID string, query domain.ResultQuery) (*domain.ResultPage, error) {
+		var results []*domain.Result
+		for i := range query.Limit {
+			result := domain.NewResult(fmt.Sprint(i), targetID, domain.StatusOK, 200, 50*time.Millisecond)
+			if i == 1 {
+				result.ResponseTime = time.Second
+			}
+			results = append(results, result)
+		}
+		return &domain.ResultPage{Results: results}, nil
+	}
+
+	mockAlertRepo := newMockAlertRepository()
+	usecase := NewMonitorUseCase(&MockTargetRepository{
+		FindByIDFunc: func(id string) (*domain.Target, error) { return target, nil },
+	}, mockResultRepo, mockAlertRepo, statusSequence(200), newMockIDGenerator())
+
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if target.State != domain.StateDegraded {
+		t.Errorf("expected a slow p95 to degrade the target, got %s", target.State)
+	}
+
+	if len(mockAlertRepo.SavedAlerts) != 1 || !strings.Contains(mockAlertRepo.SavedAlerts[0].Message, "p95") {
+		t.Errorf("expected a p95 slow response alert, got %+v", mockAlertRepo.SavedAlerts)
+	}
+}
+
+func TestCheckTarget_NotifiesAlerts(t *testing.T) {
+	target := domain.NewTarget("target-1", "https://example.com", "", time.Minute)
+	mockAlertRepo := newMockAlertRepository()
+	mockAlertRepo.GetUnresolvedByTargetIDFunc = func(targetID string) ([]*domain.Alert, error) {
+		var open []*domain.Alert
+		for _, alert := range mockAlertRepo.SavedAlerts {
+			if !alert.IsResolved {
+				open = append(open, alert)
+			}
+		}
+		return open, nil
+	}
+
+	mockOutbox := &MockOutboxRepository{Alerts: mockAlertRepo}
+	usecase := NewMonitorUseCase(&MockTargetRepository{
+		FindByIDFunc: func(id string) (*domain.Target, error) { return target, nil },
+	}, newMockResultRepository(), mockAlertRepo, statusSequence(500, 200), newMockIDGenerator(), WithOutbox(mockOutbox, "webhook"))
+
+	for range 2 {
+		if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	if len(mockOutbox.Messages) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(mockOutbox.Messages))
+	}
+
+	opened, resolved := mockOutbox.Messages[0].Notification, mockOutbox.Messages[1].Notification
+	if opened.Event != domain.NotificationOpened || opened.Alert.Type != domain.StatusServerError || opened.Target.State != domain.StateDown {
+		t.Errorf("unexpected open notification %+v", opened)
+	}
+
+	if resolved.Event != domain.NotificationResolved || !resolved.Alert.IsResolved || resolved.Alert.ID != opened.Alert.ID {
+		t.Errorf("unexpected resolve notification %+v", resolved)
+	}
+}
+
+func TestCheckTarget_EnqueuesNotificationsWithAlerts(t *testing.T) {
+	target := domain.NewTarget("target-1", "https://example.com", "", time.Minute)
+	mockAlertRepo := newMockAlertRepository()
+	mockAlertRepo.GetUnresolvedByTargetIDFunc = func(targetID string) ([]*domain.Alert, error) {
+		var open []*domain.Alert
+		for _, alert := range mockAlertRepo.SavedAlerts {
+			if !alert.IsResolved {
+				open = append(open, alert)
+			}
+		}
+		return open, nil
+	}
+
+	ids := 0
+	mockOutbox := &MockOutboxRepository{Alerts: mockAlertRepo}
+	usecase := NewMonitorUseCase(&MockTargetRepository{
+		FindByIDFunc: func(id string) (*domain.Target, error) { return target, nil },
+	}, newMockResultRepository(), mockAlertRepo, statusSequence(500, 200), &MockIDGenerator{
+		GenerateFunc: func() string {
+			ids++
+			return fmt.Sprintf("id-%d", ids)
+		},
+	}, WithOutbox(mockOutbox, "webhook", "email"))
+
+	for range 2 {
+		if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	if len(mockAlertRepo.SavedAlerts) != 1 || len(mockAlertRepo.UpdatedAlerts) != 1 {
+		t.Fatalf("expected the alert to be saved and resolved through the outbox, got %d saved and %d updated", len(mockAlertRepo.SavedAlerts), len(mockAlertRepo.UpdatedAlerts))
+	}
+
+	if len(mockOutbox.Messages) != 4 {
+		t.Fatalf("expected 4 messages, got %d", len(mockOutbox.Messages))
+	}
+
+	for i, want := range []struct{ channel, event string }{
+		{"webhook", domain.NotificationOpened},
+		{"email", domain.NotificationOpened},
+		{"webhook", domain.NotificationResolved},
+		{"email", domain.NotificationResolved},
+	} {
+		message := mockOutbox.Messages[i]
+		if message.Channel != want.channel || message.Notification.Event != want.event || message.Notification.Alert.ID != mockAlertRepo.SavedAlerts[0].ID {
+			t.Errorf("expected a %s %s message at %d, got %+v", want.channel, want.event, i, message)
+		}
+	}
+}
+
+func TestCheckTarget_RemindsUntilAcknowledged(t *testing.T) {
+	clock := newMockClock()
+	target := domain.NewTarget("target-1", "https://example.com", "", time.Minute)
+	mockAlertRepo := newMockAlertRepository()
+	mockAlertRepo.GetUnresolvedByTargetIDFunc = func(targetID string) ([]*domain.Alert, error) {
+		return mockAlertRepo.SavedAlerts, nil
+	}
+
+	mockOutbox := &MockOutboxRepository{Alerts: mockAlertRepo}
+	usecase := NewMonitorUseCase(&MockTargetRepository{
+		FindByIDFunc: func(id string) (*domain.Target, error) { return target, nil },
+	}, newMockResultRepository(), mockAlertRepo, statusSequence(500), newMockIDGenerator(),
+		WithOutbox(mockOutbox, "webhook"), WithMonitorClock(clock), WithAlertReminder(15*time.Minute))
+
+	check := func(advance time.Duration, wantNotifications int) {
+		t.Helper()
+
+		clock.Advance(advance)
+		if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(mockOutbox.Messages) != wantNotifications {
+			t.Fatalf("expected %d notifications after %s, got %d", wantNotifications, clock.Now().Format(time.Kitchen), len(mockOutbox.Messages))
+		}
+	}
+
+	check(0, 1)
+	check(10*time.Minute, 1)
+	check(5*time.Minute, 2)
+
+	if reminder := mockOutbox.Messages[1].Notification; reminder.Event != domain.NotificationRepeated || reminder.Alert.ID != mockAlertRepo.SavedAlerts[0].ID {
+		t.Errorf("expected a reminder about the open alert, got %+v", reminder)
+	}
+
+	if len(mockAlertRepo.UpdatedAlerts) != 1 || !mockAlertRepo.UpdatedAlerts[0].NotifiedAt.Equal(clock.Now()) {
+		t.Errorf("expected the reminder to be recorded on the alert, got %v", mockAlertRepo.UpdatedAlerts)
+	}
+
+	alert := mockAlertRepo.SavedAlerts[0]
+	alert.Snooze(clock.Now().Add(time.Hour))
+	check(30*time.Minute, 2)
+	check(30*time.Minute, 3)
+
+	alert.Acknowledge("alice", clock.Now())
+	check(time.Hour, 3)
+}
+
+func TestCheckTarget_MaintenanceWindowHoldsAlertsBack(t *testing.T) {
+	clock := newMockClock()
+	target := domain.NewTarget("target-1", "https://example.com", "", time.Minute)
+	target.Tags = []string{"api"}
+
+	window := domain.NewMaintenanceWindow("window-1", "Deploy", clock.Now(), 30*time.Minute)
+	window.Tags = []string{"api"}
+
+	mockResultRepo := newMockResultRepository()
+	mockAlertRepo := newMockAlertRepository()
+	mockAlertRepo.GetUnresolvedByTargetIDFunc = func(targetID string) ([]*domain.Alert, error) {
+		return mockAlertRepo.SavedAlerts, nil
+	}
+
+	mockOutbox := &MockOutboxRepository{Alerts: mockAlertRepo}
+	usecase := NewMonitorUseCase(&MockTargetRepository{
+		FindByIDFunc: func(id string) (*domain.Target, error) { return target, nil },
+	}, mockResultRepo, mockAlertRepo, statusSequence(503), newMockIDGenerator(),
+		WithOutbox(mockOutbox, "webhook"), WithMonitorClock(clock),
+		WithMaintenanceWindows(&MockMaintenanceWindowRepository{Windows: []*domain.MaintenanceWindow{window}}))
+
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if result := mockResultRepo.SavedResults[0]; !result.InMaintenance || result.Status != domain.StatusServerError {
+		t.Errorf("expected a failed result in maintenance, got %+v", result)
+	}
+
+	if len(mockAlertRepo.SavedAlerts) != 0 || len(mockOutbox.Messages) != 0 || target.State != domain.StateUnknown {
+		t.Fatalf("expected no alert and no state change in maintenance, got %d alerts, %d notifications and state %s",
+			len(mockAlertRepo.SavedAlerts), len(mockOutbox.Messages), target.State)
+	}
+
+	clock.Advance(30 * time.Minute)
+	if err := usecase.CheckTarget(context.Background(), "target-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if mockResultRepo.SavedResults[1].InMaintenance {
+		t.Error("expected the result after the window not to be in maintenance")
+	}
+
+	if len(mockAlertRepo.SavedAlerts) != 1 || len(mockOutbox.Messages) != 1 || target.State != domain.StateDown {
+		t.Errorf("expected the alert to open once the window ended, got %d alerts, %d notifications and state %s",
+			len(mockAlertRepo.SavedAlerts), len(mockOutbox.Messages), target.State)
+	}
+}
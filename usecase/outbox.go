@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+const (
+	defaultOutboxInterval      = 5 * time.Second
+	defaultOutboxBatchSize     = 100
+	defaultDeliveryBackoff     = 10 * time.Second
+	defaultMaxDeliveryBackoff  = time.Hour
+	defaultMaxDeliveryAttempts = 10
+)
+
+type OutboxOption func(*OutboxUseCase)
+
+// WithOutboxInterval sets how often the outbox is polled for due messages.
+func WithOutboxInterval(d time.Duration) OutboxOption {
+	return func(u *OutboxUseCase) {
+		if d > 0 {
+			u.interval = d
+		}
+	}
+}
+
+// WithDeliveryBackoff sets the delay before the first retry of a failed
+// delivery. It doubles with every further attempt, up to max.
+func WithDeliveryBackoff(base, max time.Duration) OutboxOption {
+	return func(u *OutboxUseCase) {
+		if base > 0 && max >= base {
+			u.backoff = base
+			u.maxBackoff = max
+		}
+	}
+}
+
+// WithMaxDeliveryAttempts sets after how many failed attempts a message is
+// dead-lettered.
+func WithMaxDeliveryAttempts(n int) OutboxOption {
+	return func(u *OutboxUseCase) {
+		if n > 0 {
+			u.maxAttempts = n
+		}
+	}
+}
+
+// WithDeliveryErrorHandler is told about failed deliveries; message is nil
+// for errors reading or updating the outbox itself.
+func WithDeliveryErrorHandler(fn func(message *domain.OutboxMessage, err error)) OutboxOption {
+	return func(u *OutboxUseCase) {
+		u.onError = fn
+	}
+}
+
+// OutboxUseCase delivers the messages MonitorUseCase queues in the outbox to
+// the notifier of their channel. Failed deliveries are retried with
+// exponential backoff until they run out of attempts and are dead-lettered.
+type OutboxUseCase struct {
+	repo        domain.OutboxRepository
+	notifiers   map[string]domain.Notifier
+	clock       domain.Clock
+	interval    time.Duration
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	maxAttempts int
+	onError     func(message *domain.OutboxMessage, err error)
+}
+
+// NewOutboxUseCase delivers messages to the notifier registered under their
+// channel name.
+func NewOutboxUseCase(
+	repo domain.OutboxRepository,
+	notifiers map[string]domain.Notifier,
+	clock domain.Clock,
+	opts ...OutboxOption,
+) *OutboxUseCase {
+	u := &OutboxUseCase{
+		repo:        repo,
+		notifiers:   notifiers,
+		clock:       clock,
+		interval:    defaultOutboxInterval,
+		backoff:     defaultDeliveryBackoff,
+		maxBackoff:  defaultMaxDeliveryBackoff,
+		maxAttempts: defaultMaxDeliveryAttempts,
+	}
+
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	return u
+}
+
+// Run delivers due messages right away and then once per interval until ctx
+// is cancelled.
+func (u *OutboxUseCase) Run(ctx context.Context) error {
+	for {
+		if err := u.DeliverDue(ctx); err != nil {
+			u.reportError(nil, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-u.clock.After(u.interval):
+		}
+	}
+}
+
+// DeliverDue attempts every message that is due. Delivery failures are
+// recorded on the messages and reported to the error handler; only storage
+// errors are returned.
+func (u *OutboxUseCase) DeliverDue(ctx context.Context) error {
+	messages, err := u.repo.Due(u.clock.Now(), defaultOutboxBatchSize)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, message := range messages {
+		if ctx.Err() != nil {
+			break
+		}
+
+		if err := u.deliver(ctx, message); err != nil {
+			errs = append(errs, fmt.Errorf("outbox message %s: %w", message.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (u *OutboxUseCase) deliver(ctx context.Context, message *domain.OutboxMessage) error {
+	notifier, exists := u.notifiers[message.Channel]
+	if !exists {
+		err := fmt.Errorf("no notifier for channel %q", message.Channel)
+		u.reportError(message, err)
+
+		now := u.clock.Now()
+		message.Fail(err, now, time.Time{})
+		return u.repo.Update(message)
+	}
+
+	err := notifier.Notify(ctx, message.Notification)
+	if err == nil {
+		return u.repo.Delete(message.ID)
+	}
+
+	// Shutting down is not the receiver's fault; try again on the next run.
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	u.reportError(message, err)
+
+	now := u.clock.Now()
+	message.Fail(err, now, u.retryAt(message.Attempts+1, now))
+	return u.repo.Update(message)
+}
+
+// retryAt returns when to retry after the given number of failed attempts,
+// or the zero time once they are used up.
+func (u *OutboxUseCase) retryAt(attempts int, now time.Time) time.Time {
+	if attempts >= u.maxAttempts {
+		return time.Time{}
+	}
+
+	delay := u.maxBackoff
+	if shift := attempts - 1; shift < 32 && u.backoff<<shift < u.maxBackoff {
+		delay = u.backoff << shift
+	}
+
+	return now.Add(delay)
+}
+
+// DeadLetters returns the messages that could not be delivered, oldest first.
+func (u *OutboxUseCase) DeadLetters() ([]*domain.OutboxMessage, error) {
+	return u.repo.DeadLetters()
+}
+
+// Replay queues a dead letter for delivery again with a fresh set of
+// attempts. It returns domain.ErrNotFound unless id is a dead letter.
+func (u *OutboxUseCase) Replay(id string) (*domain.OutboxMessage, error) {
+	message, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !message.IsDead() {
+		return nil, fmt.Errorf("dead letter with id: %s %w", id, domain.ErrNotFound)
+	}
+
+	message.Replay(u.clock.Now())
+	if err := u.repo.Update(message); err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+func (u *OutboxUseCase) reportError(message *domain.OutboxMessage, err error) {
+	if u.onError != nil {
+		u.onError(message, err)
+	}
+}
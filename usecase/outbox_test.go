@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+// ----- > Helpers < -----
+
+func newOutboxMessage(id, channel string, now time.Time) *domain.OutboxMessage {
+	alert := domain.NewAlert("alert-1", "target-1", domain.StatusServerError, "down")
+	n := domain.Notification{Event: domain.NotificationOpened, Alert: *alert}
+	return domain.NewOutboxMessage(id, channel, n, now)
+}
+
+// ----- > Tests < -----
+
+func TestOutboxUseCase_DeliversDueMessages(t *testing.T) {
+	clock := newMockClock()
+	later := newOutboxMessage("message-2", "webhook", clock.Now().Add(time.Minute))
+	repo := &MockOutboxRepository{Messages: []*domain.OutboxMessage{
+		newOutboxMessage("message-1", "webhook", clock.Now()),
+		later,
+	}}
+	notifier := &MockNotifier{}
+
+	usecase := NewOutboxUseCase(repo, map[string]domain.Notifier{"webhook": notifier}, clock)
+
+	if err := usecase.DeliverDue(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(notifier.Notifications) != 1 || notifier.Notifications[0].Alert.ID != "alert-1" {
+		t.Fatalf("expected 1 notification, got %+v", notifier.Notifications)
+	}
+
+	if len(repo.DeletedIDs) != 1 || repo.DeletedIDs[0] != "message-1" {
+		t.Errorf("expected the delivered message to be deleted, got %v", repo.DeletedIDs)
+	}
+
+	if len(repo.Messages) != 1 || repo.Messages[0] != later {
+		t.Errorf("expected the message that is not due to stay queued, got %d messages", len(repo.Messages))
+	}
+}
+
+func TestOutboxUseCase_RetriesWithBackoff(t *testing.T) {
+	clock := newMockClock()
+	message := newOutboxMessage("message-1", "webhook", clock.Now())
+	repo := &MockOutboxRepository{Messages: []*domain.OutboxMessage{message}}
+	notifier := &MockNotifier{
+		NotifyFunc: func(ctx context.Context, n domain.Notification) error {
+			return errors.New("503 Service Unavailable")
+		},
+	}
+
+	var reported int
+	usecase := NewOutboxUseCase(
+		repo,
+		map[string]domain.Notifier{"webhook": notifier},
+		clock,
+		WithDeliveryBackoff(time.Second, 3*time.Second),
+		WithMaxDeliveryAttempts(4),
+		WithDeliveryErrorHandler(func(m *domain.OutboxMessage, err error) { reported++ }),
+	)
+
+	for i, delay := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second} {
+		usecase.DeliverDue(context.Background())
+
+		if message.Attempts != i+1 || message.IsDead() {
+			t.Fatalf("expected attempt %d to be retried, got %+v", i+1, message)
+		}
+
+		if want := clock.Now().Add(delay); !message.NextAttemptAt.Equal(want) {
+			t.Errorf("expected attempt %d to be retried at %v, got %v", i+1, want, message.NextAttemptAt)
+		}
+
+		// Not due again until the backoff has passed.
+		usecase.DeliverDue(context.Background())
+		if message.Attempts != i+1 {
+			t.Fatalf("expected no delivery before the backoff passed, got %d attempts", message.Attempts)
+		}
+
+		clock.Advance(delay)
+	}
+
+	usecase.DeliverDue(context.Background())
+
+	if !message.IsDead() || message.Attempts != 4 || message.LastError != "503 Service Unavailable" {
+		t.Errorf("expected the message to be dead-lettered after 4 attempts, got %+v", message)
+	}
+
+	if reported != 4 {
+		t.Errorf("expected 4 reported failures, got %d", reported)
+	}
+
+	if dead, _ := usecase.DeadLetters(); len(dead) != 1 {
+		t.Errorf("expected 1 dead letter, got %d", len(dead))
+	}
+}
+
+func TestOutboxUseCase_UnknownChannelDeadLetters(t *testing.T) {
+	clock := newMockClock()
+	message := newOutboxMessage("message-1", "pager", clock.Now())
+	repo := &MockOutboxRepository{Messages: []*domain.OutboxMessage{message}}
+
+	usecase := NewOutboxUseCase(repo, map[string]domain.Notifier{"webhook": &MockNotifier{}}, clock)
+	usecase.DeliverDue(context.Background())
+
+	if !message.IsDead() || message.Attempts != 1 {
+		t.Errorf("expected a message for an unknown channel to be dead-lettered, got %+v", message)
+	}
+}
+
+func TestOutboxUseCase_CancelledDeliveryNotCounted(t *testing.T) {
+	clock := newMockClock()
+	message := newOutboxMessage("message-1", "webhook", clock.Now())
+	repo := &MockOutboxRepository{Messages: []*domain.OutboxMessage{message}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	notifier := &MockNotifier{
+		NotifyFunc: func(ctx context.Context, n domain.Notification) error {
+			cancel()
+			return ctx.Err()
+		},
+	}
+
+	usecase := NewOutboxUseCase(repo, map[string]domain.Notifier{"webhook": notifier}, clock)
+	usecase.DeliverDue(ctx)
+
+	if message.Attempts != 0 || len(repo.UpdatedMessages) != 0 {
+		t.Errorf("expected a delivery cut short by shutdown not to count, got %+v", message)
+	}
+}
+
+func TestOutboxUseCase_Replay(t *testing.T) {
+	clock := newMockClock()
+	dead := newOutboxMessage("message-1", "webhook", clock.Now())
+	dead.Fail(errors.New("410 Gone"), clock.Now(), time.Time{})
+	live := newOutboxMessage("message-2", "webhook", clock.Now().Add(time.Hour))
+	repo := &MockOutboxRepository{Messages: []*domain.OutboxMessage{dead, live}}
+	notifier := &MockNotifier{}
+
+	usecase := NewOutboxUseCase(repo, map[string]domain.Notifier{"webhook": notifier}, clock)
+	clock.Advance(time.Minute)
+
+	replayed, err := usecase.Replay("message-1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if replayed.IsDead() || replayed.Attempts != 0 || !replayed.NextAttemptAt.Equal(clock.Now()) {
+		t.Errorf("expected the message to be due again with fresh attempts, got %+v", replayed)
+	}
+
+	if _, err := usecase.Replay("message-2"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected ErrNotFound replaying a live message, got %v", err)
+	}
+
+	if _, err := usecase.Replay("nonExistent"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	usecase.DeliverDue(context.Background())
+
+	if len(notifier.Notifications) != 1 {
+		t.Errorf("expected the replayed message to be delivered, got %d notifications", len(notifier.Notifications))
+	}
+}
@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+const (
+	defaultRawRetention       = 7 * 24 * time.Hour
+	defaultCompactionInterval = time.Hour
+)
+
+// epoch bounds rollup lookups that have no lower end.
+var epoch = time.Unix(0, 0)
+
+type RetentionOption func(*RetentionUseCase)
+
+// WithRawRetention sets how long raw results are kept before they are
+// compacted into rollups.
+func WithRawRetention(d time.Duration) RetentionOption {
+	return func(u *RetentionUseCase) {
+		if d > 0 {
+			u.rawRetention = d
+		}
+	}
+}
+
+// WithHourlyRetention sets how long hourly rollups are kept once their day
+// has been rolled up. Zero keeps them forever.
+func WithHourlyRetention(d time.Duration) RetentionOption {
+	return func(u *RetentionUseCase) {
+		if d >= 0 {
+			u.hourlyRetention = d
+		}
+	}
+}
+
+func WithCompactionInterval(d time.Duration) RetentionOption {
+	return func(u *RetentionUseCase) {
+		if d > 0 {
+			u.interval = d
+		}
+	}
+}
+
+func WithCompactionErrorHandler(fn func(err error)) RetentionOption {
+	return func(u *RetentionUseCase) {
+		u.onError = fn
+	}
+}
+
+// RetentionUseCase compacts raw results older than the raw retention into
+// hourly rollups, folds complete days of hourly rollups into daily ones and
+// then drops what was compacted.
+type RetentionUseCase struct {
+	targetRepo      domain.TargetRepository
+	resultRepo      domain.ResultRepository
+	rollupRepo      domain.RollupRepository
+	clock           domain.Clock
+	rawRetention    time.Duration
+	hourlyRetention time.Duration
+	interval        time.Duration
+	onError         func(err error)
+}
+
+func NewRetentionUseCase(
+	targetRepo domain.TargetRepository,
+	resultRepo domain.ResultRepository,
+	rollupRepo domain.RollupRepository,
+	clock domain.Clock,
+	opts ...RetentionOption,
+) *RetentionUseCase {
+	u := &RetentionUseCase{
+		targetRepo:   targetRepo,
+		resultRepo:   resultRepo,
+		rollupRepo:   rollupRepo,
+		clock:        clock,
+		rawRetention: defaultRawRetention,
+		interval:     defaultCompactionInterval,
+	}
+
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	return u
+}
+
+// Run compacts every target right away and then once per interval until ctx
+// is cancelled.
+func (u *RetentionUseCase) Run(ctx context.Context) error {
+	for {
+		if err := u.Compact(); err != nil {
+			u.reportError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-u.clock.After(u.interval):
+		}
+	}
+}
+
+// Compact compacts every target. A failing target doesn't stop the others;
+// their errors are joined.
+func (u *RetentionUseCase) Compact() error {
+	targets, err := u.targetRepo.GetAll()
+	if err != nil {
+		return err
+	}
+
+	now := u.clock.Now()
+
+	var errs []error
+	for _, target := range targets {
+		if err := u.CompactTarget(target.ID, now); err != nil {
+			errs = append(errs, fmt.Errorf("compacting target %s: %w", target.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// CompactTarget is idempotent: rollups replace earlier ones for the same
+// bucket, so a run interrupted between saving rollups and deleting the raw
+// results is simply redone.
+func (u *RetentionUseCase) CompactTarget(targetID string, now time.Time) error {
+	cutoff := domain.RollupHourly.Truncate(now.Add(-u.rawRetention))
+
+	days, err := u.compactResults(targetID, cutoff)
+	if err != nil {
+		return err
+	}
+
+	for day := range days {
+		if day.Add(domain.RollupDaily.Duration()).After(cutoff) {
+			continue
+		}
+
+		if err := u.rollUpDays(targetID, day, day.Add(domain.RollupDaily.Duration())); err != nil {
+			return err
+		}
+	}
+
+	if _, err := u.resultRepo.DeleteBefore(targetID, cutoff); err != nil {
+		return err
+	}
+
+	if u.hourlyRetention == 0 {
+		return nil
+	}
+
+	// Only drop hours whose whole day is older than the raw cutoff, and roll
+	// those days up first so that a day whose last hours were compacted in an
+	// earlier run still gets its daily rollup.
+	hourlyCutoff := domain.RollupDaily.Truncate(now.Add(-u.hourlyRetention))
+	if limit := domain.RollupDaily.Truncate(cutoff); hourlyCutoff.After(limit) {
+		hourlyCutoff = limit
+	}
+
+	if err := u.rollUpDays(targetID, epoch, hourlyCutoff); err != nil {
+		return err
+	}
+
+	_, err = u.rollupRepo.DeleteBefore(targetID, domain.RollupHourly, hourlyCutoff)
+	return err
+}
+
+// compactResults saves an hourly rollup for every hour before cutoff that
+// still has raw results and returns the days those hours belong to.
+func (u *RetentionUseCase) compactResults(targetID string, cutoff time.Time) (map[time.Time]bool, error) {
+	days := make(map[time.Time]bool)
+
+	var (
+		bucket time.Time
+		batch  []*domain.Result
+	)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := u.rollupRepo.Save(domain.NewRollup(targetID, domain.RollupHourly, bucket, batch)); err != nil {
+			return err
+		}
+
+		days[domain.RollupDaily.Truncate(bucket)] = true
+		batch = nil
+		return nil
+	}
+
+	query := domain.ResultQuery{To: cutoff, Limit: domain.MaxResultQueryLimit}
+
+	for {
+		page, err := u.resultRepo.QueryByTargetID(targetID, query)
+		if err != nil {
+			return nil, err
+		}
+
+		// Results come newest-first, so each hour is one contiguous run.
+		for _, result := range page.Results {
+			if start := domain.RollupHourly.Truncate(result.CheckedAt); !start.Equal(bucket) {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+				bucket = start
+			}
+
+			batch = append(batch, result)
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return days, nil
+}
+
+// rollUpDays merges the hourly rollups in [from, to) into one daily rollup
+// per day.
+func (u *RetentionUseCase) rollUpDays(targetID string, from, to time.Time) error {
+	hourly, err := u.rollupRepo.FindByTargetID(targetID, domain.RollupHourly, from, to)
+	if err != nil {
+		return err
+	}
+
+	var (
+		day   time.Time
+		hours []*domain.Rollup
+	)
+
+	flush := func() error {
+		if len(hours) == 0 {
+			return nil
+		}
+
+		err := u.rollupRepo.Save(domain.MergeRollups(targetID, domain.RollupDaily, day, hours))
+		hours = nil
+		return err
+	}
+
+	for _, rollup := range hourly {
+		if start := domain.RollupDaily.Truncate(rollup.BucketStart); !start.Equal(day) {
+			if err := flush(); err != nil {
+				return err
+			}
+			day = start
+		}
+
+		hours = append(hours, rollup)
+	}
+
+	return flush()
+}
+
+func (u *RetentionUseCase) reportError(err error) {
+	if u.onError != nil {
+		u.onError(err)
+	}
+}
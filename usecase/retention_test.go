@@ -0,0 +1,262 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+// ----- > Helpers < -----
+
+func resultCheckedAt(id, status string, checkedAt time.Time) *domain.Result {
+	result := domain.NewResult(id, "target-1", status, 200, 100*time.Millisecond)
+	result.CheckedAt = checkedAt
+	return result
+}
+
+func findRollup(rollups []*domain.Rollup, resolution domain.RollupResolution, bucket time.Time) *domain.Rollup {
+	var found *domain.Rollup
+	for _, rollup := range rollups {
+		if rollup.Resolution == resolution && rollup.BucketStart.Equal(bucket) {
+			found = rollup
+		}
+	}
+
+	return found
+}
+
+func expectCompaction(t *testing.T, runs <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("expected a compaction run, got none")
+	}
+}
+
+// ----- > Test cases < -----
+
+func TestCompactTarget_RollsUpResultsPastRetention(t *testing.T) {
+	day1 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	now := day2.Add(36*time.Hour + 30*time.Minute)
+
+	results := []*domain.Result{
+		resultCheckedAt("1", domain.StatusOK, day1.Add(10*time.Hour+5*time.Minute)),
+		resultCheckedAt("2", domain.StatusServerError, day1.Add(10*time.Hour+20*time.Minute)),
+		resultCheckedAt("3", domain.StatusOK, day1.Add(23*time.Hour+50*time.Minute)),
+		resultCheckedAt("4", domain.StatusOK, day2.Add(11*time.Hour+10*time.Minute)),
+		resultCheckedAt("5", domain.StatusOK, day2.Add(12*time.Hour+10*time.Minute)),
+	}
+
+	var deletedBefore time.Time
+	mockResultRepo := &MockResultRepository{
+		QueryByTargetIDFunc: queryResults(results),
+		DeleteBeforeFunc: func(targetID string, before time.Time) (int, error) {
+			deletedBefore = before
+			return 0, nil
+		},
+	}
+	mockRollupRepo := &MockRollupRepository{}
+
+	usecase := NewRetentionUseCase(newMockTargetRepository(), mockResultRepo, mockRollupRepo, newMockClock(), WithRawRetention(24*time.Hour))
+
+	if err := usecase.CompactTarget("target-1", now); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	cutoff := day2.Add(12 * time.Hour)
+	if !deletedBefore.Equal(cutoff) {
+		t.Errorf("expected raw results deleted before %s, got %s", cutoff, deletedBefore)
+	}
+
+	hour10 := findRollup(mockRollupRepo.SavedRollups, domain.RollupHourly, day1.Add(10*time.Hour))
+	if hour10 == nil || hour10.TotalChecks != 2 || hour10.UptimePercent != 50 {
+		t.Fatalf("expected the 10:00 rollup to hold 2 checks at 50%% uptime, got %+v", hour10)
+	}
+
+	if findRollup(mockRollupRepo.SavedRollups, domain.RollupHourly, day1.Add(23*time.Hour)) == nil ||
+		findRollup(mockRollupRepo.SavedRollups, domain.RollupHourly, day2.Add(11*time.Hour)) == nil {
+		t.Error("expected rollups for 23:00 on day 1 and 11:00 on day 2")
+	}
+
+	if findRollup(mockRollupRepo.SavedRollups, domain.RollupHourly, day2.Add(12*time.Hour)) != nil {
+		t.Error("expected the hour at the cutoff to stay raw")
+	}
+
+	daily := findRollup(mockRollupRepo.SavedRollups, domain.RollupDaily, day1)
+	if daily == nil || daily.TotalChecks != 3 {
+		t.Fatalf("expected a daily rollup of 3 checks for day 1, got %+v", daily)
+	}
+
+	if findRollup(mockRollupRepo.SavedRollups, domain.RollupDaily, day2) != nil {
+		t.Error("expected no daily rollup for the incomplete day 2")
+	}
+
+	if _, deleted := mockRollupRepo.DeletedBefore[domain.RollupHourly]; deleted {
+		t.Error("expected hourly rollups to be kept without an hourly retention")
+	}
+}
+
+func TestCompactTarget_HourlyRetention(t *testing.T) {
+	day1 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := day1.Add(72*time.Hour + 6*time.Hour)
+
+	// Hours compacted by an earlier run whose day never got a daily rollup.
+	mockRollupRepo := &MockRollupRepository{SavedRollups: []*domain.Rollup{
+		domain.NewRollup("target-1", domain.RollupHourly, day1.Add(22*time.Hour), []*domain.Result{
+			resultCheckedAt("1", domain.StatusOK, day1.Add(22*time.Hour)),
+		}),
+	}}
+
+	usecase := NewRetentionUseCase(
+		newMockTargetRepository(),
+		&MockResultRepository{},
+		mockRollupRepo,
+		newMockClock(),
+		WithRawRetention(24*time.Hour),
+		WithHourlyRetention(48*time.Hour),
+	)
+
+	if err := usecase.CompactTarget("target-1", now); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if before := mockRollupRepo.DeletedBefore[domain.RollupHourly]; !before.Equal(day1.Add(24 * time.Hour)) {
+		t.Errorf("expected hourly rollups deleted before day 2, got %s", before)
+	}
+
+	if daily := findRollup(mockRollupRepo.SavedRollups, domain.RollupDaily, day1); daily == nil || daily.TotalChecks != 1 {
+		t.Errorf("expected day 1 rolled up before its hours are dropped, got %+v", daily)
+	}
+}
+
+func TestCompactTarget_HourlyRetentionNeverPassesRawCutoff(t *testing.T) {
+	now := time.Date(2025, 1, 10, 6, 0, 0, 0, time.UTC)
+	mockRollupRepo := &MockRollupRepository{}
+
+	usecase := NewRetentionUseCase(
+		newMockTargetRepository(),
+		&MockResultRepository{},
+		mockRollupRepo,
+		newMockClock(),
+		WithRawRetention(72*time.Hour),
+		WithHourlyRetention(time.Hour),
+	)
+
+	if err := usecase.CompactTarget("target-1", now); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC)
+	if before := mockRollupRepo.DeletedBefore[domain.RollupHourly]; !before.Equal(expected) {
+		t.Errorf("expected hourly rollups deleted before %s, got %s", expected, before)
+	}
+}
+
+func TestCompactTarget_SaveError(t *testing.T) {
+	results := []*domain.Result{
+		resultCheckedAt("1", domain.StatusOK, time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)),
+	}
+
+	deleted := false
+	mockResultRepo := &MockResultRepository{
+		QueryByTargetIDFunc: queryResults(results),
+		DeleteBeforeFunc: func(targetID string, before time.Time) (int, error) {
+			deleted = true
+			return 0, nil
+		},
+	}
+	mockRollupRepo := &MockRollupRepository{
+		SaveFunc: func(rollup *domain.Rollup) error {
+			return errors.New("disk full")
+		},
+	}
+
+	usecase := NewRetentionUseCase(newMockTargetRepository(), mockResultRepo, mockRollupRepo, newMockClock())
+
+	if err := usecase.CompactTarget("target-1", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Fatal("expected save error to be returned")
+	}
+
+	if deleted {
+		t.Error("expected raw results to be kept when their rollup could not be saved")
+	}
+}
+
+func TestCompact_ContinuesAfterFailingTarget(t *testing.T) {
+	mockTargetRepo := &MockTargetRepository{
+		GetAllFunc: func() ([]*domain.Target, error) {
+			return []*domain.Target{{ID: "broken"}, {ID: "target-1"}}, nil
+		},
+	}
+
+	var compacted []string
+	mockResultRepo := &MockResultRepository{
+		QueryByTargetIDFunc: func(targetID string, query domain.ResultQuery) (*domain.ResultPage, error) {
+			if targetID == "broken" {
+				return nil, errors.New("database is locked")
+			}
+			return &domain.ResultPage{}, nil
+		},
+		DeleteBeforeFunc: func(targetID string, before time.Time) (int, error) {
+			compacted = append(compacted, targetID)
+			return 0, nil
+		},
+	}
+
+	usecase := NewRetentionUseCase(mockTargetRepo, mockResultRepo, &MockRollupRepository{}, newMockClock())
+	err := usecase.Compact()
+
+	if err == nil {
+		t.Fatal("expected the failing target to be reported")
+	}
+
+	if len(compacted) != 1 || compacted[0] != "target-1" {
+		t.Errorf("expected target-1 to be compacted anyway, got %v", compacted)
+	}
+}
+
+func TestRetentionRun_CompactsEveryInterval(t *testing.T) {
+	clock := newMockClock()
+	runs := make(chan struct{}, 4)
+
+	mockTargetRepo := &MockTargetRepository{
+		GetAllFunc: func() ([]*domain.Target, error) {
+			runs <- struct{}{}
+			return nil, nil
+		},
+	}
+
+	usecase := NewRetentionUseCase(mockTargetRepo, &MockResultRepository{}, &MockRollupRepository{}, clock, WithCompactionInterval(time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		usecase.Run(ctx)
+		close(done)
+	}()
+
+	expectCompaction(t, runs)
+
+	// Let Run register its timer before moving the clock.
+	for {
+		clock.mu.Lock()
+		waiting := len(clock.waiters)
+		clock.mu.Unlock()
+		if waiting > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	clock.Advance(time.Hour)
+	expectCompaction(t, runs)
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,323 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+const (
+	defaultSchedulerWorkers      = 4
+	defaultSchedulerSyncInterval = 30 * time.Second
+)
+
+type TargetChecker interface {
+	CheckTarget(ctx context.Context, targetID string) error
+}
+
+type SchedulerOption func(*Scheduler)
+
+// WithWorkers bounds how many checks may run at the same time.
+func WithWorkers(n int) SchedulerOption {
+	return func(s *Scheduler) {
+		if n > 0 {
+			s.workers = n
+		}
+	}
+}
+
+// WithSyncInterval sets how often the target list is reloaded from the repository.
+func WithSyncInterval(d time.Duration) SchedulerOption {
+	return func(s *Scheduler) {
+		if d > 0 {
+			s.syncInterval = d
+		}
+	}
+}
+
+func WithErrorHandler(fn func(targetID string, err error)) SchedulerOption {
+	return func(s *Scheduler) {
+		s.onError = fn
+	}
+}
+
+type scheduleEntry struct {
+	interval time.Duration
+	nextRun  time.Time
+	running  bool
+
+	// removed marks the entry of a target that is gone but still being
+	// checked; it is dropped once the check finishes.
+	removed bool
+}
+
+type Scheduler struct {
+	targetRepo   domain.TargetRepository
+	checker      TargetChecker
+	clock        domain.Clock
+	workers      int
+	syncInterval time.Duration
+	onError      func(targetID string, err error)
+
+	mu       sync.Mutex
+	entries  map[string]*scheduleEntry
+	lastSync time.Time
+	wake     chan struct{}
+}
+
+func NewScheduler(
+	targetRepo domain.TargetRepository,
+	checker TargetChecker,
+	clock domain.Clock,
+	opts ...SchedulerOption,
+) *Scheduler {
+	s := &Scheduler{
+		targetRepo:   targetRepo,
+		checker:      checker,
+		clock:        clock,
+		workers:      defaultSchedulerWorkers,
+		syncInterval: defaultSchedulerSyncInterval,
+		entries:      make(map[string]*scheduleEntry),
+		wake:         make(chan struct{}, 1),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Reload re-reads the targets from the repository right away, so that targets
+// added, updated or deactivated at runtime don't have to wait for the next sync.
+func (s *Scheduler) Reload() error {
+	if err := s.sync(s.clock.Now()); err != nil {
+		return err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Run schedules checks until ctx is cancelled. It waits for in-flight checks
+// to finish before returning.
+func (s *Scheduler) Run(ctx context.Context) error {
+	jobs := make(chan string)
+	done := make(chan string, s.workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go s.worker(ctx, jobs, done, &wg)
+	}
+
+	defer func() {
+		close(jobs)
+		wg.Wait()
+	}()
+
+	var pending []string
+
+	for {
+		now := s.clock.Now()
+
+		if s.syncDue(now) {
+			if err := s.sync(now); err != nil {
+				s.reportError("", err)
+			}
+		}
+
+		pending = append(s.dropRemoved(pending), s.collectDue(now)...)
+
+		var send chan<- string
+		var next string
+		if len(pending) > 0 {
+			send = jobs
+			next = pending[0]
+		}
+
+		timer := s.clock.After(s.nextWake(now))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case send <- next:
+			pending = pending[1:]
+		case id := <-done:
+			s.finish(id)
+		case <-s.wake:
+		case <-timer:
+		}
+	}
+}
+
+func (s *Scheduler) worker(ctx context.Context, jobs <-chan string, done chan<- string, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for id := range jobs {
+		if err := s.checker.CheckTarget(ctx, id); err != nil && ctx.Err() == nil {
+			s.reportError(id, err)
+		}
+
+		// Run stops reading done once ctx is cancelled.
+		select {
+		case done <- id:
+		case <-ctx.Done():
+		}
+	}
+}
+
+func (s *Scheduler) sync(now time.Time) error {
+	targets, err := s.targetRepo.GetAll()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastSync = now
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(targets))
+
+	for _, target := range targets {
+		if !target.IsActive || !target.IsValid() {
+			continue
+		}
+
+		seen[target.ID] = true
+		entry, exists := s.entries[target.ID]
+
+		if !exists {
+			s.entries[target.ID] = &scheduleEntry{
+				interval: target.Interval,
+				nextRun:  now,
+			}
+			continue
+		}
+
+		// Added back while its last check is running: it is checked again
+		// once that check finishes.
+		if entry.removed {
+			entry.removed = false
+			entry.interval = target.Interval
+			entry.nextRun = now
+			continue
+		}
+
+		if entry.interval != target.Interval {
+			entry.interval = target.Interval
+			if limit := now.Add(target.Interval); entry.nextRun.After(limit) {
+				entry.nextRun = limit
+			}
+		}
+	}
+
+	for id, entry := range s.entries {
+		switch {
+		case seen[id]:
+		case entry.running:
+			entry.removed = true
+		default:
+			delete(s.entries, id)
+		}
+	}
+
+	return nil
+}
+
+func (s *Scheduler) syncDue(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastSync.IsZero() || !now.Before(s.lastSync.Add(s.syncInterval))
+}
+
+func (s *Scheduler) collectDue(now time.Time) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []string
+
+	for id, entry := range s.entries {
+		if entry.running || entry.nextRun.After(now) {
+			continue
+		}
+
+		entry.running = true
+		entry.nextRun = entry.nextRun.Add(entry.interval)
+		if !entry.nextRun.After(now) {
+			entry.nextRun = now.Add(entry.interval)
+		}
+
+		due = append(due, id)
+	}
+
+	return due
+}
+
+func (s *Scheduler) finish(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.entries[id]
+	switch {
+	case !exists:
+	case entry.removed:
+		delete(s.entries, id)
+	default:
+		entry.running = false
+	}
+}
+
+// dropRemoved drops the targets removed since they were queued from pending.
+func (s *Scheduler) dropRemoved(pending []string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := pending[:0]
+	for _, id := range pending {
+		entry, exists := s.entries[id]
+		if exists && !entry.removed {
+			kept = append(kept, id)
+			continue
+		}
+
+		if exists {
+			delete(s.entries, id)
+		}
+	}
+
+	return kept
+}
+
+func (s *Scheduler) nextWake(now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wakeAt := s.lastSync.Add(s.syncInterval)
+
+	for _, entry := range s.entries {
+		if !entry.running && entry.nextRun.Before(wakeAt) {
+			wakeAt = entry.nextRun
+		}
+	}
+
+	if d := wakeAt.Sub(now); d > 0 {
+		return d
+	}
+
+	return 0
+}
+
+func (s *Scheduler) reportError(targetID string, err error) {
+	if s.onError != nil {
+		s.onError(targetID, err)
+	}
+}
@@ -0,0 +1,426 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+// ----- > MOCK DEF < -----
+
+// ========================[Clock]========================
+
+type clockWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+type MockClock struct {
+	mu      sync.Mutex
+	set     *sync.Cond
+	now     time.Time
+	waiters []clockWaiter
+}
+
+func newMockClock() *MockClock {
+	c := &MockClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	c.set = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *MockClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *MockClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+
+	c.waiters = append(c.waiters, clockWaiter{deadline: c.now.Add(d), ch: ch})
+	c.set.Broadcast()
+	return ch
+}
+
+// BlockUntil waits until a timer is set to fire at deadline, so that an
+// Advance past it is seen by whoever set it.
+func (c *MockClock) BlockUntil(deadline time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for !c.hasWaiter(deadline) {
+		c.set.Wait()
+	}
+}
+
+func (c *MockClock) hasWaiter(deadline time.Time) bool {
+	for _, w := range c.waiters {
+		if w.deadline.Equal(deadline) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *MockClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	remaining := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.deadline.After(c.now) {
+			remaining = append(remaining, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = remaining
+}
+
+// ========================[Target Checker]========================
+
+type MockTargetChecker struct {
+	CheckTargetFunc func(ctx context.Context, targetID string) error
+	Checked         chan string
+}
+
+func newMockTargetChecker() *MockTargetChecker {
+	return &MockTargetChecker{Checked: make(chan string, 16)}
+}
+
+func (m *MockTargetChecker) CheckTarget(ctx context.Context, targetID string) error {
+	var err error
+	if m.CheckTargetFunc != nil {
+		err = m.CheckTargetFunc(ctx, targetID)
+	}
+
+	m.Checked <- targetID
+	return err
+}
+
+// ----- > Helpers < -----
+
+type mutableTargets struct {
+	mu      sync.Mutex
+	targets []*domain.Target
+}
+
+func (m *mutableTargets) set(targets ...*domain.Target) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.targets = targets
+}
+
+func (m *mutableTargets) getAll() ([]*domain.Target, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*domain.Target(nil), m.targets...), nil
+}
+
+func expectChecked(t *testing.T, checker *MockTargetChecker, want string) {
+	t.Helper()
+
+	if got := <-checker.Checked; got != want {
+		t.Fatalf("expected check of %s, got %s", want, got)
+	}
+}
+
+// expectNoCheck is called once the scheduler waits for its next run, as
+// BlockUntil tells.
+func expectNoCheck(t *testing.T, checker *MockTargetChecker) {
+	t.Helper()
+
+	select {
+	case got := <-checker.Checked:
+		t.Fatalf("expected no check, got %s", got)
+	default:
+	}
+}
+
+func startScheduler(t *testing.T, s *Scheduler) func() {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+
+	go func() {
+		stopped <- s.Run(ctx)
+	}()
+
+	return func() {
+		cancel()
+
+		if err := <-stopped; err != nil {
+			t.Errorf("expected nil from Run, got %v", err)
+		}
+	}
+}
+
+// ----- > Test cases < -----
+
+func TestScheduler_ChecksActiveTargetsOnInterval(t *testing.T) {
+	active := domain.NewTarget("target-1", "https://example.com", "active", 10*time.Second)
+	inactive := domain.NewTarget("target-2", "https://example.org", "inactive", 10*time.Second)
+	inactive.IsActive = false
+
+	targets := &mutableTargets{}
+	targets.set(active, inactive)
+
+	mockTargetRepo := &MockTargetRepository{GetAllFunc: targets.getAll}
+	checker := newMockTargetChecker()
+	clock := newMockClock()
+
+	scheduler := NewScheduler(mockTargetRepo, checker, clock, WithSyncInterval(time.Hour))
+	stop := startScheduler(t, scheduler)
+	defer stop()
+
+	start := clock.Now()
+
+	expectChecked(t, checker, "target-1")
+	clock.BlockUntil(start.Add(10 * time.Second))
+	expectNoCheck(t, checker)
+
+	clock.Advance(10 * time.Second)
+	expectChecked(t, checker, "target-1")
+	clock.BlockUntil(start.Add(20 * time.Second))
+
+	clock.Advance(10 * time.Second)
+	expectChecked(t, checker, "target-1")
+	clock.BlockUntil(start.Add(30 * time.Second))
+	expectNoCheck(t, checker)
+}
+
+func TestScheduler_ReloadPicksUpRuntimeChanges(t *testing.T) {
+	first := domain.NewTarget("target-1", "https://example.com", "first", 10*time.Second)
+
+	targets := &mutableTargets{}
+	targets.set(first)
+
+	mockTargetRepo := &MockTargetRepository{GetAllFunc: targets.getAll}
+	checker := newMockTargetChecker()
+	clock := newMockClock()
+
+	scheduler := NewScheduler(mockTargetRepo, checker, clock, WithSyncInterval(time.Hour))
+	stop := startScheduler(t, scheduler)
+	defer stop()
+
+	start := clock.Now()
+
+	expectChecked(t, checker, "target-1")
+	clock.BlockUntil(start.Add(10 * time.Second))
+
+	second := domain.NewTarget("target-2", "https://example.org", "second", 5*time.Second)
+	targets.set(second)
+
+	if err := scheduler.Reload(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expectChecked(t, checker, "target-2")
+	clock.BlockUntil(start.Add(5 * time.Second))
+
+	clock.Advance(10 * time.Second)
+	expectChecked(t, checker, "target-2")
+	clock.BlockUntil(start.Add(15 * time.Second))
+	expectNoCheck(t, checker)
+}
+
+func TestScheduler_IntervalUpdateReschedules(t *testing.T) {
+	target := domain.NewTarget("target-1", "https://example.com", "target", time.Hour)
+
+	targets := &mutableTargets{}
+	targets.set(target)
+
+	mockTargetRepo := &MockTargetRepository{GetAllFunc: targets.getAll}
+	checker := newMockTargetChecker()
+	clock := newMockClock()
+
+	scheduler := NewScheduler(mockTargetRepo, checker, clock, WithSyncInterval(24*time.Hour))
+	stop := startScheduler(t, scheduler)
+	defer stop()
+
+	start := clock.Now()
+
+	expectChecked(t, checker, "target-1")
+	clock.BlockUntil(start.Add(time.Hour))
+
+	updated := domain.NewTarget("target-1", "https://example.com", "target", 5*time.Second)
+	targets.set(updated)
+	scheduler.Reload()
+
+	clock.BlockUntil(start.Add(5 * time.Second))
+	clock.Advance(5 * time.Second)
+	expectChecked(t, checker, "target-1")
+}
+
+func TestScheduler_PeriodicSync(t *testing.T) {
+	targets := &mutableTargets{}
+	first := domain.NewTarget("target-1", "https://example.com", "first", time.Hour)
+	targets.set(first)
+
+	mockTargetRepo := &MockTargetRepository{GetAllFunc: targets.getAll}
+	checker := newMockTargetChecker()
+	clock := newMockClock()
+
+	scheduler := NewScheduler(mockTargetRepo, checker, clock, WithSyncInterval(time.Minute))
+	stop := startScheduler(t, scheduler)
+	defer stop()
+
+	start := clock.Now()
+
+	expectChecked(t, checker, "target-1")
+	clock.BlockUntil(start.Add(time.Minute))
+
+	targets.set(first, domain.NewTarget("target-2", "https://example.org", "late", 10*time.Second))
+	expectNoCheck(t, checker)
+
+	clock.Advance(time.Minute)
+	expectChecked(t, checker, "target-2")
+}
+
+func TestScheduler_BoundedWorkers(t *testing.T) {
+	targets := &mutableTargets{}
+	targets.set(
+		domain.NewTarget("target-1", "https://example.com/1", "one", 10*time.Second),
+		domain.NewTarget("target-2", "https://example.com/2", "two", 10*time.Second),
+		domain.NewTarget("target-3", "https://example.com/3", "three", 10*time.Second),
+	)
+
+	started := make(chan string, 3)
+	release := make(chan struct{})
+
+	mockTargetRepo := &MockTargetRepository{GetAllFunc: targets.getAll}
+	checker := newMockTargetChecker()
+	checker.CheckTargetFunc = func(ctx context.Context, targetID string) error {
+		started <- targetID
+		<-release
+		return nil
+	}
+
+	scheduler := NewScheduler(mockTargetRepo, checker, newMockClock(), WithWorkers(2))
+	stop := startScheduler(t, scheduler)
+	defer stop()
+
+	<-started
+	<-started
+
+	select {
+	case id := <-started:
+		t.Fatalf("expected at most 2 concurrent checks, %s started too", id)
+	default:
+	}
+
+	close(release)
+	<-started
+}
+
+func TestScheduler_StopsOnContextCancel(t *testing.T) {
+	mockTargetRepo := &MockTargetRepository{}
+	scheduler := NewScheduler(mockTargetRepo, newMockTargetChecker(), newMockClock())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+
+	go func() {
+		stopped <- scheduler.Run(ctx)
+	}()
+
+	cancel()
+
+	if err := <-stopped; err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+}
+
+func TestScheduler_WorkerStopsWhenDoneIsNotRead(t *testing.T) {
+	scheduler := NewScheduler(&MockTargetRepository{}, newMockTargetChecker(), newMockClock())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	jobs := make(chan string, 1)
+	jobs <- "target-1"
+	close(jobs)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go scheduler.worker(ctx, jobs, make(chan string), &wg)
+
+	// Blocks for good if the worker waits for done to be read.
+	wg.Wait()
+}
+
+func TestScheduler_TargetReAddedWhileRunning(t *testing.T) {
+	targets := &mutableTargets{}
+	target := domain.NewTarget("target-1", "https://example.com", "target", time.Minute)
+	targets.set(target)
+
+	clock := newMockClock()
+	scheduler := NewScheduler(&MockTargetRepository{GetAllFunc: targets.getAll}, newMockTargetChecker(), clock)
+	now := clock.Now()
+
+	scheduler.sync(now)
+	if due := scheduler.collectDue(now); len(due) != 1 {
+		t.Fatalf("expected target-1 to be due, got %v", due)
+	}
+
+	targets.set()
+	scheduler.sync(now)
+	targets.set(target)
+	scheduler.sync(now)
+
+	if due := scheduler.collectDue(now); len(due) != 0 {
+		t.Fatalf("expected no second check while the first runs, got %v", due)
+	}
+
+	scheduler.finish("target-1")
+
+	if due := scheduler.collectDue(now); len(due) != 1 {
+		t.Errorf("expected the re-added target to be checked once the check finished, got %v", due)
+	}
+}
+
+func TestScheduler_DropsRemovedTargetsFromPending(t *testing.T) {
+	one := domain.NewTarget("target-1", "https://example.com/1", "one", time.Minute)
+	two := domain.NewTarget("target-2", "https://example.com/2", "two", time.Minute)
+
+	targets := &mutableTargets{}
+	targets.set(one, two)
+
+	clock := newMockClock()
+	scheduler := NewScheduler(&MockTargetRepository{GetAllFunc: targets.getAll}, newMockTargetChecker(), clock)
+	now := clock.Now()
+
+	scheduler.sync(now)
+	pending := scheduler.collectDue(now)
+
+	targets.set(two)
+	scheduler.sync(now)
+
+	if kept := scheduler.dropRemoved(pending); len(kept) != 1 || kept[0] != "target-2" {
+		t.Fatalf("expected only target-2 to stay queued, got %v", kept)
+	}
+
+	targets.set(one, two)
+	scheduler.sync(now)
+
+	if due := scheduler.collectDue(now); len(due) != 1 || due[0] != "target-1" {
+		t.Errorf("expected target-1 to be due again once added back, got %v", due)
+	}
+}
@@ -0,0 +1,336 @@
+package usecase
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+type ResponseTimeStats struct {
+	Mean time.Duration
+	P50  time.Duration
+	P95  time.Duration
+	P99  time.Duration
+}
+
+type Stats struct {
+	TargetID      string
+	From          time.Time
+	To            time.Time
+	TotalChecks   int
+	StatusCounts  map[string]int
+	UptimePercent float64
+	ResponseTime  ResponseTimeStats
+	Incidents     int
+	Downtime      time.Duration
+	MTTR          time.Duration
+	MTBF          time.Duration
+
+	// Phases breaks the response time of HTTP checks down by
+	// domain.TimingPhases; empty when no check in the window had timings.
+	Phases map[string]ResponseTimeStats
+}
+
+type StatsOption func(*StatsUseCase)
+
+// WithRollupRepository lets stats fall back to hourly and daily rollups for
+// the part of the window whose raw results were already compacted.
+func WithRollupRepository(repo domain.RollupRepository) StatsOption {
+	return func(u *StatsUseCase) {
+		u.rollupRepo = repo
+	}
+}
+
+// WithMaintenanceWindowRepository leaves the time the target was in
+// maintenance out of its downtime.
+func WithMaintenanceWindowRepository(repo domain.MaintenanceWindowRepository) StatsOption {
+	return func(u *StatsUseCase) {
+		u.maintenanceRepo = repo
+	}
+}
+
+type StatsUseCase struct {
+	targetRepo      domain.TargetRepository
+	resultRepo      domain.ResultRepository
+	alertRepo       domain.AlertRepository
+	rollupRepo      domain.RollupRepository
+	maintenanceRepo domain.MaintenanceWindowRepository
+}
+
+func NewStatsUseCase(
+	targetRepo domain.TargetRepository,
+	resultRepo domain.ResultRepository,
+	alertRepo domain.AlertRepository,
+	opts ...StatsOption,
+) *StatsUseCase {
+	u := &StatsUseCase{
+		targetRepo: targetRepo,
+		resultRepo: resultRepo,
+		alertRepo:  alertRepo,
+	}
+
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	return u
+}
+
+// GetStats computes statistics for the results checked and the alerts open
+// within [from, to). Results checked and downtime during maintenance are
+// left out.
+func (u *StatsUseCase) GetStats(targetID string, from, to time.Time) (*Stats, error) {
+	target, err := u.targetRepo.FindByID(targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !from.Before(to) {
+		return nil, errors.New("stats window must end after it starts")
+	}
+
+	stats := &Stats{
+		TargetID:     targetID,
+		From:         from,
+		To:           to,
+		StatusCounts: make(map[string]int),
+	}
+
+	var (
+		responseTimes domain.ResponseTimeDistribution
+		phases        domain.PhaseDistributions
+	)
+
+	rawFrom, err := u.applyRollups(stats, &responseTimes, &phases)
+	if err != nil {
+		return nil, err
+	}
+
+	if rawFrom.Before(to) {
+		if err := u.applyResults(stats, &responseTimes, &phases, rawFrom); err != nil {
+			return nil, err
+		}
+	}
+
+	if stats.TotalChecks > 0 {
+		stats.UptimePercent = float64(stats.StatusCounts[domain.StatusOK]) / float64(stats.TotalChecks) * 100
+	}
+
+	stats.ResponseTime = newResponseTimeStats(&responseTimes)
+
+	stats.Phases = make(map[string]ResponseTimeStats, len(phases))
+	for phase, dist := range phases {
+		stats.Phases[phase] = newResponseTimeStats(dist)
+	}
+
+	alerts, err := u.alertRepo.FindByTargetID(targetID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	maintenance, err := u.maintenance(target, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	u.applyIncidents(stats, alerts, maintenance)
+
+	return stats, nil
+}
+
+// maintenance returns when maintenance windows covered target within
+// [from, to).
+func (u *StatsUseCase) maintenance(target *domain.Target, from, to time.Time) ([]interval, error) {
+	if u.maintenanceRepo == nil {
+		return nil, nil
+	}
+
+	windows, err := u.maintenanceRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var covered []interval
+	for _, window := range windows {
+		if !window.Covers(target) {
+			continue
+		}
+
+		for _, start := range window.Runs(from, to) {
+			covered = append(covered, interval{start: start, end: start.Add(window.Duration)})
+		}
+	}
+
+	return covered, nil
+}
+
+func newResponseTimeStats(dist *domain.ResponseTimeDistribution) ResponseTimeStats {
+	return ResponseTimeStats{
+		Mean: dist.Mean(),
+		P50:  dist.Percentile(50),
+		P95:  dist.Percentile(95),
+		P99:  dist.Percentile(99),
+	}
+}
+
+func (u *StatsUseCase) applyResults(stats *Stats, responseTimes *domain.ResponseTimeDistribution, phases *domain.PhaseDistributions, from time.Time) error {
+	query := domain.ResultQuery{From: from, To: stats.To, Limit: domain.MaxResultQueryLimit}
+
+	for {
+		page, err := u.resultRepo.QueryByTargetID(stats.TargetID, query)
+		if err != nil {
+			return err
+		}
+
+		for _, result := range page.Results {
+			if result.InMaintenance {
+				continue
+			}
+
+			stats.TotalChecks++
+			stats.StatusCounts[result.Status]++
+			responseTimes.Add(result.ResponseTime)
+			phases.Add(result.Timings)
+		}
+
+		if page.NextCursor == "" {
+			return nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+// applyRollups adds the rollups whose bucket starts in the window and returns
+// where raw results take over: the end of the newest rollup used. Hourly
+// rollups win over the daily rollup of the same day; a daily rollup is only
+// used once its hours have been dropped.
+func (u *StatsUseCase) applyRollups(stats *Stats, responseTimes *domain.ResponseTimeDistribution, phases *domain.PhaseDistributions) (time.Time, error) {
+	rawFrom := stats.From
+
+	if u.rollupRepo == nil {
+		return rawFrom, nil
+	}
+
+	hourly, err := u.rollupRepo.FindByTargetID(stats.TargetID, domain.RollupHourly, stats.From, stats.To)
+	if err != nil {
+		return rawFrom, err
+	}
+
+	daily, err := u.rollupRepo.FindByTargetID(stats.TargetID, domain.RollupDaily, stats.From, stats.To)
+	if err != nil {
+		return rawFrom, err
+	}
+
+	hourlyDays := make(map[time.Time]bool, len(hourly))
+	for _, rollup := range hourly {
+		hourlyDays[domain.RollupDaily.Truncate(rollup.BucketStart)] = true
+	}
+
+	for _, rollup := range append(daily, hourly...) {
+		if rollup.Resolution == domain.RollupDaily && hourlyDays[rollup.BucketStart.UTC()] {
+			continue
+		}
+
+		stats.TotalChecks += rollup.TotalChecks
+		for status, n := range rollup.StatusCounts {
+			stats.StatusCounts[status] += n
+		}
+		responseTimes.AddRollup(rollup)
+		phases.AddRollup(rollup)
+
+		if end := rollup.BucketEnd(); end.After(rawFrom) {
+			rawFrom = end
+		}
+	}
+
+	return rawFrom, nil
+}
+
+// applyIncidents derives MTTR and MTBF from the downtime alerts overlapping
+// the window. Downtime during maintenance doesn't count.
+// MTTR averages how long resolved alerts stayed open; MTBF is the time the
+// target was up in the window divided by the number of failures that began
+// in it.
+func (u *StatsUseCase) applyIncidents(stats *Stats, alerts []*domain.Alert, maintenance []interval) {
+	var (
+		repaired     int
+		totalRepairs time.Duration
+		down         []interval
+	)
+
+	for _, alert := range alerts {
+		if !alert.IsDowntime() {
+			continue
+		}
+
+		end := stats.To
+		if alert.ResolvedAt != nil && alert.ResolvedAt.Before(end) {
+			end = *alert.ResolvedAt
+		}
+
+		if !alert.CreatedAt.Before(stats.To) || !end.After(stats.From) {
+			continue
+		}
+
+		start := alert.CreatedAt
+		if start.Before(stats.From) {
+			start = stats.From
+		}
+
+		down = append(down, interval{start: start, end: end})
+
+		if !alert.CreatedAt.Before(stats.From) {
+			stats.Incidents++
+		}
+
+		if alert.IsResolved && alert.ResolvedAt != nil && alert.ResolvedAt.Before(stats.To) {
+			repaired++
+			totalRepairs += alert.ResolvedAt.Sub(alert.CreatedAt)
+		}
+	}
+
+	// The time down outside maintenance is the time down or in maintenance
+	// less the time in maintenance.
+	stats.Downtime = mergedDuration(append(down, maintenance...)) - mergedDuration(maintenance)
+
+	if repaired > 0 {
+		stats.MTTR = totalRepairs / time.Duration(repaired)
+	}
+
+	if stats.Incidents > 0 {
+		uptime := stats.To.Sub(stats.From) - stats.Downtime
+		stats.MTBF = uptime / time.Duration(stats.Incidents)
+	}
+}
+
+type interval struct {
+	start, end time.Time
+}
+
+// mergedDuration is the time covered by intervals, counting overlaps once.
+func mergedDuration(intervals []interval) time.Duration {
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].start.Before(intervals[j].start)
+	})
+
+	var (
+		total time.Duration
+		end   time.Time
+	)
+
+	for _, iv := range intervals {
+		start := iv.start
+		if start.Before(end) {
+			start = end
+		}
+
+		if iv.end.After(start) {
+			total += iv.end.Sub(start)
+			end = iv.end
+		}
+	}
+
+	return total
+}
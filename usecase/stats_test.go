@@ -0,0 +1,477 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/karoljaro/go-uptime-monitor/domain"
+)
+
+// ----- > Helpers < -----
+
+var statsBase = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func resultAt(id, status string, offset, responseTime time.Duration) *domain.Result {
+	result := domain.NewResult(id, "target-1", status, 200, responseTime)
+	result.CheckedAt = statsBase.Add(offset)
+	return result
+}
+
+func alertBetween(id string, opened, resolved time.Duration) *domain.Alert {
+	alert := domain.NewAlert(id, "target-1", domain.StatusServerError, "down")
+	alert.CreatedAt = statsBase.Add(opened)
+
+	if resolved > 0 {
+		resolvedAt := statsBase.Add(resolved)
+		alert.ResolvedAt = &resolvedAt
+		alert.IsResolved = true
+	}
+
+	return alert
+}
+
+// queryResults answers result queries from a fixed slice, newest-first and in
+// a single page.
+func queryResults(results []*domain.Result) func(string, domain.ResultQuery) (*domain.ResultPage, error) {
+	return func(targetID string, query domain.ResultQuery) (*domain.ResultPage, error) {
+		page := &domain.ResultPage{}
+		for _, result := range results {
+			if !query.From.IsZero() && result.CheckedAt.Before(query.From) {
+				continue
+			}
+			if !query.To.IsZero() && !result.CheckedAt.Before(query.To) {
+				continue
+			}
+			page.Results = append(page.Results, result)
+		}
+
+		sort.Slice(page.Results, func(i, j int) bool {
+			return page.Results[i].CheckedAt.After(page.Results[j].CheckedAt)
+		})
+
+		return page, nil
+	}
+}
+
+func newStatsUseCase(results []*domain.Result, alerts []*domain.Alert) *StatsUseCase {
+	mockResultRepo := &MockResultRepository{
+		QueryByTargetIDFunc: queryResults(results),
+	}
+
+	mockAlertRepo := &MockAlertRepository{
+		FindByTargetIDFunc: func(targetID string) ([]*domain.Alert, error) {
+			return alerts, nil
+		},
+	}
+
+	return NewStatsUseCase(newMockTargetRepository(), mockResultRepo, mockAlertRepo)
+}
+
+// ----- > Test cases < -----
+
+func TestGetStats_UptimeAndStatusCounts(t *testing.T) {
+	results := []*domain.Result{
+		resultAt("before", domain.StatusServerError, -time.Minute, time.Second),
+		resultAt("1", domain.StatusOK, 0, 100*time.Millisecond),
+		resultAt("2", domain.StatusOK, time.Minute, 200*time.Millisecond),
+		resultAt("3", domain.StatusServerError, 2*time.Minute, 300*time.Millisecond),
+		resultAt("4", domain.StatusOK, 3*time.Minute, 400*time.Millisecond),
+		resultAt("after", domain.StatusServerError, time.Hour, time.Second),
+	}
+
+	stats, err := newStatsUseCase(results, nil).GetStats("target-1", statsBase, statsBase.Add(time.Hour))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if stats.TotalChecks != 4 {
+		t.Errorf("expected 4 checks in window, got %d", stats.TotalChecks)
+	}
+
+	if stats.StatusCounts[domain.StatusOK] != 3 || stats.StatusCounts[domain.StatusServerError] != 1 {
+		t.Errorf("unexpected status counts %v", stats.StatusCounts)
+	}
+
+	if stats.UptimePercent != 75 {
+		t.Errorf("expected uptime 75%%, got %f", stats.UptimePercent)
+	}
+
+	if stats.ResponseTime.Mean != 250*time.Millisecond {
+		t.Errorf("expected mean 250ms, got %s", stats.ResponseTime.Mean)
+	}
+
+	if stats.ResponseTime.P50 != 200*time.Millisecond {
+		t.Errorf("expected p50 200ms, got %s", stats.ResponseTime.P50)
+	}
+
+	if stats.ResponseTime.P95 != 400*time.Millisecond || stats.ResponseTime.P99 != 400*time.Millisecond {
+		t.Errorf("expected p95/p99 400ms, got %s/%s", stats.ResponseTime.P95, stats.ResponseTime.P99)
+	}
+}
+
+func TestGetStats_LeavesOutMaintenance(t *testing.T) {
+	inMaintenance := resultAt("2", domain.StatusServerError, time.Minute, 5*time.Second)
+	inMaintenance.InMaintenance = true
+
+	results := []*domain.Result{
+		resultAt("1", domain.StatusOK, 0, 100*time.Millisecond),
+		inMaintenance,
+		resultAt("3", domain.StatusOK, 2*time.Minute, 300*time.Millisecond),
+	}
+
+	stats, err := newStatsUseCase(results, nil).GetStats("target-1", statsBase, statsBase.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if stats.TotalChecks != 2 || stats.UptimePercent != 100 || stats.StatusCounts[domain.StatusServerError] != 0 {
+		t.Errorf("expected only the 2 checks outside maintenance, got %d checks, %f%% and %v", stats.TotalChecks, stats.UptimePercent, stats.StatusCounts)
+	}
+
+	if stats.ResponseTime.P99 > 300*time.Millisecond {
+		t.Errorf("expected response times in maintenance to be left out, got p99 %s", stats.ResponseTime.P99)
+	}
+}
+
+func TestGetStats_Percentiles(t *testing.T) {
+	results := make([]*domain.Result, 0, 100)
+	for i := 1; i <= 100; i++ {
+		results = append(results, resultAt(fmt.Sprint(i), domain.StatusOK, time.Duration(i)*time.Second, time.Duration(i)*time.Millisecond))
+	}
+
+	stats, _ := newStatsUseCase(results, nil).GetStats("target-1", statsBase, statsBase.Add(time.Hour))
+
+	if stats.ResponseTime.P50 != 50*time.Millisecond {
+		t.Errorf("expected p50 50ms, got %s", stats.ResponseTime.P50)
+	}
+
+	if stats.ResponseTime.P95 != 95*time.Millisecond {
+		t.Errorf("expected p95 95ms, got %s", stats.ResponseTime.P95)
+	}
+
+	if stats.ResponseTime.P99 != 99*time.Millisecond {
+		t.Errorf("expected p99 99ms, got %s", stats.ResponseTime.P99)
+	}
+}
+
+func TestGetStats_MTTRAndMTBF(t *testing.T) {
+	alerts := []*domain.Alert{
+		alertBetween("a-1", 10*time.Minute, 20*time.Minute),
+		alertBetween("a-2", 30*time.Minute, 60*time.Minute),
+		alertBetween("a-3", 100*time.Minute, 110*time.Minute),
+	}
+
+	stats, err := newStatsUseCase(nil, alerts).GetStats("target-1", statsBase, statsBase.Add(100*time.Minute))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if stats.Incidents != 2 {
+		t.Errorf("expected 2 incidents, got %d", stats.Incidents)
+	}
+
+	if stats.Downtime != 40*time.Minute {
+		t.Errorf("expected 40m downtime, got %s", stats.Downtime)
+	}
+
+	if stats.MTTR != 20*time.Minute {
+		t.Errorf("expected MTTR 20m, got %s", stats.MTTR)
+	}
+
+	if stats.MTBF != 30*time.Minute {
+		t.Errorf("expected MTBF 30m, got %s", stats.MTBF)
+	}
+}
+
+func TestGetStats_IgnoresCertificateExpiryAlerts(t *testing.T) {
+	results := []*domain.Result{
+		resultAt("1", domain.StatusOK, 0, 100*time.Millisecond),
+		resultAt("2", domain.StatusOK, time.Hour, 100*time.Millisecond),
+	}
+
+	expiring := alertBetween("a-1", 0, 0)
+	expiring.Type = domain.AlertTypeCertificateExpiry
+
+	stats, err := newStatsUseCase(results, []*domain.Alert{expiring}).GetStats("target-1", statsBase, statsBase.Add(12*time.Hour))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if stats.UptimePercent != 100 || stats.Incidents != 0 || stats.Downtime != 0 || stats.MTBF != 0 {
+		t.Errorf("expected a certificate expiry alert not to count as downtime, got %d incidents, %s downtime and MTBF %s",
+			stats.Incidents, stats.Downtime, stats.MTBF)
+	}
+}
+
+func TestGetStats_CountsOverlappingDowntimeOnce(t *testing.T) {
+	slow := alertBetween("a-1", 0, 90*time.Minute)
+	slow.Type = domain.AlertTypeSlowResponse
+
+	failedAssertion := alertBetween("a-3", 30*time.Minute, 50*time.Minute)
+	failedAssertion.Type = domain.StatusAssertionFailed
+
+	alerts := []*domain.Alert{
+		slow,
+		alertBetween("a-2", 10*time.Minute, 40*time.Minute),
+		failedAssertion,
+	}
+
+	stats, err := newStatsUseCase(nil, alerts).GetStats("target-1", statsBase, statsBase.Add(100*time.Minute))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if stats.Downtime != 40*time.Minute {
+		t.Errorf("expected 40m downtime, got %s", stats.Downtime)
+	}
+
+	if stats.Incidents != 2 || stats.MTBF != 30*time.Minute {
+		t.Errorf("expected 2 incidents and MTBF 30m, got %d and %s", stats.Incidents, stats.MTBF)
+	}
+}
+
+func TestGetStats_LeavesOutDowntimeInMaintenance(t *testing.T) {
+	alerts := []*domain.Alert{alertBetween("a-1", 10*time.Minute, 40*time.Minute)}
+
+	// Nightly from 00:30 for 20 minutes; the one-off window is another
+	// target's.
+	nightly := domain.NewMaintenanceWindow("window-1", "Backup", statsBase.Add(-24*time.Hour), 20*time.Minute)
+	nightly.Schedule = "30 0 * * *"
+	nightly.TargetIDs = []string{"target-1"}
+
+	other := domain.NewMaintenanceWindow("window-2", "Deploy", statsBase, time.Hour)
+	other.TargetIDs = []string{"target-2"}
+
+	usecase := newStatsUseCase(nil, alerts)
+	WithMaintenanceWindowRepository(&MockMaintenanceWindowRepository{Windows: []*domain.MaintenanceWindow{nightly, other}})(usecase)
+
+	stats, err := usecase.GetStats("target-1", statsBase, statsBase.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if stats.Downtime != 20*time.Minute {
+		t.Errorf("expected 20m downtime outside maintenance, got %s", stats.Downtime)
+	}
+}
+
+func TestGetStats_OpenAlertCountsUntilWindowEnd(t *testing.T) {
+	alerts := []*domain.Alert{
+		alertBetween("earlier", -30*time.Minute, 15*time.Minute),
+		alertBetween("open", 45*time.Minute, 0),
+	}
+
+	stats, _ := newStatsUseCase(nil, alerts).GetStats("target-1", statsBase, statsBase.Add(time.Hour))
+
+	if stats.Downtime != 30*time.Minute {
+		t.Errorf("expected 30m downtime, got %s", stats.Downtime)
+	}
+
+	if stats.Incidents != 1 {
+		t.Errorf("expected only the alert opened in the window to count, got %d", stats.Incidents)
+	}
+
+	if stats.MTTR != 45*time.Minute {
+		t.Errorf("expected MTTR 45m from the resolved alert, got %s", stats.MTTR)
+	}
+}
+
+func TestGetStats_FollowsPages(t *testing.T) {
+	var queries []domain.ResultQuery
+
+	mockResultRepo := &MockResultRepository{
+		QueryByTargetIDFunc: func(targetID string, query domain.ResultQuery) (*domain.ResultPage, error) {
+			queries = append(queries, query)
+
+			if query.Cursor == "" {
+				return &domain.ResultPage{
+					Results:    []*domain.Result{resultAt("2", domain.StatusOK, 2*time.Minute, time.Millisecond)},
+					NextCursor: "page-2",
+				}, nil
+			}
+
+			return &domain.ResultPage{
+				Results: []*domain.Result{resultAt("1", domain.StatusServerError, time.Minute, time.Millisecond)},
+			}, nil
+		},
+	}
+
+	usecase := NewStatsUseCase(newMockTargetRepository(), mockResultRepo, &MockAlertRepository{})
+	stats, err := usecase.GetStats("target-1", statsBase, statsBase.Add(time.Hour))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(queries) != 2 || queries[1].Cursor != "page-2" {
+		t.Fatalf("expected the second page to be requested with its cursor, got %+v", queries)
+	}
+
+	if !queries[0].From.Equal(statsBase) || !queries[0].To.Equal(statsBase.Add(time.Hour)) {
+		t.Errorf("expected query bounded by the stats window, got %+v", queries[0])
+	}
+
+	if stats.TotalChecks != 2 || stats.UptimePercent != 50 {
+		t.Errorf("expected 2 checks at 50%% uptime, got %d at %f", stats.TotalChecks, stats.UptimePercent)
+	}
+}
+
+func TestGetStats_QueryError(t *testing.T) {
+	mockResultRepo := &MockResultRepository{
+		QueryByTargetIDFunc: func(targetID string, query domain.ResultQuery) (*domain.ResultPage, error) {
+			return nil, errors.New("database is locked")
+		},
+	}
+
+	usecase := NewStatsUseCase(newMockTargetRepository(), mockResultRepo, &MockAlertRepository{})
+
+	if _, err := usecase.GetStats("target-1", statsBase, statsBase.Add(time.Hour)); err == nil {
+		t.Error("expected query error to be returned")
+	}
+}
+
+func TestGetStats_Empty(t *testing.T) {
+	usecase := NewStatsUseCase(newMockTargetRepository(), &MockResultRepository{}, &MockAlertRepository{})
+	stats, err := usecase.GetStats("target-1", statsBase, statsBase.Add(time.Hour))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if stats.TotalChecks != 0 || stats.UptimePercent != 0 || stats.MTBF != 0 {
+		t.Errorf("expected zero stats, got %+v", stats)
+	}
+}
+
+func TestGetStats_UnknownTarget(t *testing.T) {
+	mockTargetRepo := &MockTargetRepository{
+		FindByIDFunc: func(id string) (*domain.Target, error) {
+			return nil, fmt.Errorf("target with id: %s %w", id, domain.ErrNotFound)
+		},
+	}
+
+	usecase := NewStatsUseCase(mockTargetRepo, &MockResultRepository{}, &MockAlertRepository{})
+	_, err := usecase.GetStats("nonExistent", statsBase, statsBase.Add(time.Hour))
+
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestGetStats_InvalidWindow(t *testing.T) {
+	usecase := newStatsUseCase(nil, nil)
+
+	if _, err := usecase.GetStats("target-1", statsBase, statsBase); err == nil {
+		t.Error("expected error for empty window, got nil")
+	}
+}
+
+func TestGetStats_ReadsRollupsPastRawRetention(t *testing.T) {
+	hourly := domain.NewRollup("target-1", domain.RollupHourly, statsBase, []*domain.Result{
+		resultAt("h-1", domain.StatusOK, 0, 100*time.Millisecond),
+		resultAt("h-2", domain.StatusServerError, time.Minute, 300*time.Millisecond),
+	})
+
+	// A raw result left over from an interrupted compaction must not be
+	// counted twice.
+	results := []*domain.Result{
+		resultAt("stale", domain.StatusOK, 30*time.Minute, time.Second),
+		resultAt("1", domain.StatusOK, 90*time.Minute, 200*time.Millisecond),
+	}
+
+	mockRollupRepo := &MockRollupRepository{SavedRollups: []*domain.Rollup{hourly}}
+	mockResultRepo := &MockResultRepository{QueryByTargetIDFunc: queryResults(results)}
+
+	usecase := NewStatsUseCase(newMockTargetRepository(), mockResultRepo, &MockAlertRepository{}, WithRollupRepository(mockRollupRepo))
+	stats, err := usecase.GetStats("target-1", statsBase, statsBase.Add(2*time.Hour))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if stats.TotalChecks != 3 {
+		t.Fatalf("expected 2 rolled up checks and 1 raw check, got %d", stats.TotalChecks)
+	}
+
+	if stats.StatusCounts[domain.StatusOK] != 2 || stats.StatusCounts[domain.StatusServerError] != 1 {
+		t.Errorf("unexpected status counts %v", stats.StatusCounts)
+	}
+
+	if stats.ResponseTime.Mean != 200*time.Millisecond {
+		t.Errorf("expected mean 200ms, got %s", stats.ResponseTime.Mean)
+	}
+
+	if stats.ResponseTime.P99 != 300*time.Millisecond {
+		t.Errorf("expected p99 300ms, got %s", stats.ResponseTime.P99)
+	}
+}
+
+func TestGetStats_PhaseBreakdown(t *testing.T) {
+	timed := func(id string, offset, tls, server time.Duration) *domain.Result {
+		result := resultAt(id, domain.StatusOK, offset, tls+server)
+		result.Timings = &domain.Timings{TLS: tls, Server: server}
+		return result
+	}
+
+	hourly := domain.NewRollup("target-1", domain.RollupHourly, statsBase, []*domain.Result{
+		timed("h-1", 0, 10*time.Millisecond, 100*time.Millisecond),
+		timed("h-2", time.Minute, 30*time.Millisecond, 100*time.Millisecond),
+	})
+
+	results := []*domain.Result{
+		timed("1", 90*time.Minute, 80*time.Millisecond, 400*time.Millisecond),
+		resultAt("tcp", domain.StatusOK, 100*time.Minute, time.Millisecond),
+	}
+
+	mockRollupRepo := &MockRollupRepository{SavedRollups: []*domain.Rollup{hourly}}
+	mockResultRepo := &MockResultRepository{QueryByTargetIDFunc: queryResults(results)}
+
+	usecase := NewStatsUseCase(newMockTargetRepository(), mockResultRepo, &MockAlertRepository{}, WithRollupRepository(mockRollupRepo))
+	stats, err := usecase.GetStats("target-1", statsBase, statsBase.Add(2*time.Hour))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tls := stats.Phases[domain.PhaseTLS]
+	if tls.Mean != 40*time.Millisecond || tls.P95 != 80*time.Millisecond {
+		t.Errorf("expected TLS mean 40ms and p95 80ms, got %+v", tls)
+	}
+
+	server := stats.Phases[domain.PhaseServer]
+	if server.Mean != 200*time.Millisecond || server.P50 != 100*time.Millisecond {
+		t.Errorf("expected server mean 200ms and p50 100ms, got %+v", server)
+	}
+}
+
+func TestGetStats_PrefersHourlyOverDailyRollups(t *testing.T) {
+	day := domain.NewRollup("target-1", domain.RollupHourly, statsBase, []*domain.Result{
+		resultAt("1", domain.StatusOK, 0, time.Millisecond),
+	})
+	nextDay := statsBase.Add(24 * time.Hour)
+
+	mockRollupRepo := &MockRollupRepository{SavedRollups: []*domain.Rollup{
+		day,
+		domain.MergeRollups("target-1", domain.RollupDaily, statsBase, []*domain.Rollup{day}),
+		domain.NewRollup("target-1", domain.RollupDaily, nextDay, []*domain.Result{
+			resultAt("2", domain.StatusServerError, 24*time.Hour, time.Millisecond),
+			resultAt("3", domain.StatusServerError, 25*time.Hour, time.Millisecond),
+		}),
+	}}
+
+	usecase := NewStatsUseCase(newMockTargetRepository(), &MockResultRepository{}, &MockAlertRepository{}, WithRollupRepository(mockRollupRepo))
+	stats, err := usecase.GetStats("target-1", statsBase, statsBase.Add(72*time.Hour))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if stats.TotalChecks != 3 {
+		t.Errorf("expected the first day counted once, got %d checks", stats.TotalChecks)
+	}
+}